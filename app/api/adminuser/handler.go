@@ -1,11 +1,14 @@
 package adminuserapi
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"student-services-platform-backend/app/contextkeys"
 	adminusersvc "student-services-platform-backend/app/services/adminuser"
 	"student-services-platform-backend/internal/openapi"
+	"student-services-platform-backend/internal/pagination"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,6 +21,21 @@ func New(s *adminusersvc.Service) *Handler {
 	return &Handler{svc: s}
 }
 
+// currentUID returns the authenticated user's ID, writing an error response when it is missing
+func (h *Handler) currentUID(c *gin.Context) (uint, bool) {
+	val, exists := c.Get(string(contextkeys.UserIDKey))
+	if !exists {
+		c.JSON(http.StatusUnauthorized, openapi.Error{Code: "unauthorized", Message: "Unauthorized"})
+		return 0, false
+	}
+	uid, ok := val.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, openapi.Error{Code: "internal_error", Message: "Invalid user ID in context"})
+		return 0, false
+	}
+	return uid, true
+}
+
 // ListUsers handles GET /users - List users with pagination and role filtering
 func (h *Handler) ListUsers(c *gin.Context) {
 	// Parse query parameters
@@ -54,8 +72,18 @@ func (h *Handler) ListUsers(c *gin.Context) {
 		role = &r
 	}
 
+	// cursor 参数存在时使用游标分页（空值表示第一页）
+	cursor, useCursor := c.GetQuery("cursor")
+
 	// Get users
-	result, err := h.svc.ListUsers(page, pageSize, role)
+	result, err := h.svc.ListUsers(pagination.Params{Page: page, PageSize: pageSize, Cursor: cursor, UseCursor: useCursor}, role)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, openapi.Error{
+			Code:    "bad_request",
+			Message: "Invalid cursor parameter",
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, openapi.Error{
 			Code:    "internal_error",
@@ -93,6 +121,11 @@ func (h *Handler) GetUser(c *gin.Context) {
 
 // CreateUser handles POST /users - Create a new user
 func (h *Handler) CreateUser(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+
 	var req openapi.UserCreate
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, openapi.Error{
@@ -121,7 +154,7 @@ func (h *Handler) CreateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.svc.CreateUser(req)
+	user, err := h.svc.CreateUser(c.Request.Context(), uid, req)
 	if err != nil {
 		switch e := err.(type) {
 		case *adminusersvc.ErrEmailTaken:
@@ -145,6 +178,11 @@ func (h *Handler) CreateUser(c *gin.Context) {
 
 // UpdateUser handles PUT /users/{id} - Update a user
 func (h *Handler) UpdateUser(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+
 	id := c.Param("id")
 	if id == "" {
 		c.JSON(http.StatusBadRequest, openapi.Error{
@@ -182,7 +220,7 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.svc.UpdateUser(id, req)
+	user, err := h.svc.UpdateUser(c.Request.Context(), uid, id, req)
 	if err != nil {
 		switch e := err.(type) {
 		case *adminusersvc.ErrEmailTaken:
@@ -206,6 +244,11 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 
 // DeleteUser handles DELETE /users/{id} - Delete a user
 func (h *Handler) DeleteUser(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+
 	id := c.Param("id")
 	if id == "" {
 		c.JSON(http.StatusBadRequest, openapi.Error{
@@ -215,7 +258,7 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	if err := h.svc.DeleteUser(id); err != nil {
+	if err := h.svc.DeleteUser(c.Request.Context(), uid, id); err != nil {
 		c.JSON(http.StatusInternalServerError, openapi.Error{
 			Code:    "internal_error",
 			Message: "Failed to delete user",
@@ -0,0 +1,151 @@
+package auditlogapi
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"student-services-platform-backend/app/contextkeys"
+	auditlogsvc "student-services-platform-backend/app/services/auditlog"
+	"student-services-platform-backend/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /admin/audit-logs?actor_id=&action=&entity=&entity_id=&request_id=&from=&to=
+func (h *Handler) List(c *gin.Context) {
+	f, ok := h.parseFilters(c)
+	if !ok {
+		return
+	}
+	out, err := h.svc.List(f, pagination.FromQuery(c.Request.URL.Query()))
+	if err != nil {
+		h.handleSvcErr(c, err, "查询审计日志失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// GET /admin/audit-logs/export?format=csv|jsonl（筛选参数同列表）
+func (h *Handler) Export(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	f, ok := h.parseFilters(c)
+	if !ok {
+		return
+	}
+	exp, err := h.svc.NewExport(f, c.Query("format"))
+	if err != nil {
+		h.handleSvcErr(c, err, "导出审计日志失败")
+		return
+	}
+
+	c.Header("Content-Type", exp.ContentType())
+	c.Header("Content-Disposition", `attachment; filename="`+exp.FileName(time.Now())+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	// 已开始写出响应体，之后的错误只能记录日志
+	if n, err := exp.Stream(c.Request.Context(), uid, c.Writer); err != nil {
+		log.Printf("auditlog: 导出中断（已写出 %d 条）: %v", n, err)
+	}
+}
+
+// GET /admin/audit-logs/verify 校验审计日志哈希链与锚点
+func (h *Handler) Verify(c *gin.Context) {
+	out, err := h.svc.Verify(c.Request.Context())
+	if err != nil {
+		h.handleSvcErr(c, err, "校验审计日志失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// parseFilters 解析筛选参数；action 支持逗号分隔的多个值，以 ".*" 结尾的按前缀匹配
+func (h *Handler) parseFilters(c *gin.Context) (auditlogsvc.Filters, bool) {
+	var f auditlogsvc.Filters
+	var ok bool
+	if raw := strings.TrimSpace(c.Query("action")); raw != "" {
+		f.Actions = strings.Split(raw, ",")
+	}
+	f.Entity = c.Query("entity")
+	f.RequestID = c.Query("request_id")
+	if f.ActorID, ok = h.parseUintQuery(c, "actor_id"); !ok {
+		return f, false
+	}
+	if f.EntityID, ok = h.parseUintQuery(c, "entity_id"); !ok {
+		return f, false
+	}
+	if f.From, ok = h.parseTimeQuery(c, "from", false); !ok {
+		return f, false
+	}
+	if f.To, ok = h.parseTimeQuery(c, "to", true); !ok {
+		return f, false
+	}
+	return f, true
+}
+
+// 解析正整数 ID 查询参数；错误时直接返回 400
+func (h *Handler) parseUintQuery(c *gin.Context, key string) (*uint, bool) {
+	raw := strings.TrimSpace(c.Query(key))
+	if raw == "" {
+		return nil, true
+	}
+	n, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || n == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": key + " 参数无效"})
+		return nil, false
+	}
+	v := uint(n)
+	return &v, true
+}
+
+// 解析时间查询参数（RFC3339，或 YYYY-MM-DD 按 UTC 零点）；endOfDay 为 true 时
+// 日期形式取次日零点，便于作为开区间上界包含当天。错误时直接返回 400
+func (h *Handler) parseTimeQuery(c *gin.Context, key string, endOfDay bool) (*time.Time, bool) {
+	raw := strings.TrimSpace(c.Query(key))
+	if raw == "" {
+		return nil, true
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, true
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": key + " 参数无效，应为 RFC3339 或 YYYY-MM-DD"})
+		return nil, false
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, true
+}
+
+// currentUID 从 context 安全地获取用户 ID
+func (h *Handler) currentUID(c *gin.Context) (uint, bool) {
+	val, exists := c.Get(string(contextkeys.UserIDKey))
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return 0, false
+	}
+	uid, ok := val.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "上下文用户ID类型错误"})
+		return 0, false
+	}
+	return uid, true
+}
+
+// 将 service 错误统一映射为 HTTP
+func (h *Handler) handleSvcErr(c *gin.Context, err error, fallback string) {
+	switch e := err.(type) {
+	case *auditlogsvc.ErrValidation:
+		c.JSON(http.StatusBadRequest, gin.H{"error": e.Message, "details": e.Details})
+	default:
+		log.Printf("Internal server error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,11 @@
+package auditlogapi
+
+import auditlogsvc "student-services-platform-backend/app/services/auditlog"
+
+type Handler struct {
+	svc *auditlogsvc.Service
+}
+
+func New(s *auditlogsvc.Service) *Handler {
+	return &Handler{svc: s}
+}
@@ -17,7 +17,7 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	jwtResponse, err := h.svc.Login(postRequest.Email, postRequest.Password)
+	jwtResponse, err := h.svc.Login(c.Request.Context(), postRequest.Email, postRequest.Password)
 	if err != nil {
 		switch e := err.(type) {
 		case *auth.ErrUserNotFound:
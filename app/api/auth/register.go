@@ -13,7 +13,7 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
-	u, err := h.svc.Register(req)
+	u, err := h.svc.Register(c.Request.Context(), req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,140 @@
+package availabilityapi
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"student-services-platform-backend/app/contextkeys"
+	availabilitysvc "student-services-platform-backend/app/services/availability"
+	"student-services-platform-backend/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /availability
+func (h *Handler) List(c *gin.Context) {
+	out, err := h.svc.List()
+	if err != nil {
+		h.handleSvcErr(c, err, "查询失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// GET /users/me/availability
+func (h *Handler) GetMe(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	out, err := h.svc.Get(uid)
+	if err != nil {
+		h.handleSvcErr(c, err, "查询失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// PUT /users/me/availability
+func (h *Handler) UpdateMe(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	var req openapi.UsersMeAvailabilityPutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+	out, err := h.svc.UpdateStatus(c.Request.Context(), uid, req)
+	if err != nil {
+		h.handleSvcErr(c, err, "更新失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// PUT /users/me/shifts
+func (h *Handler) ReplaceShifts(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	var req openapi.UsersMeShiftsPutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+	out, err := h.svc.ReplaceShifts(c.Request.Context(), uid, req.Shifts)
+	if err != nil {
+		h.handleSvcErr(c, err, "更新班次失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// POST /users/me/out-of-office
+func (h *Handler) CreateOutOfOffice(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	var req openapi.AdminOutOfOfficeCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+	out, err := h.svc.CreateOutOfOffice(c.Request.Context(), uid, req)
+	if err != nil {
+		h.handleSvcErr(c, err, "登记外出失败")
+		return
+	}
+	c.JSON(http.StatusCreated, out)
+}
+
+// DELETE /users/me/out-of-office/:id
+func (h *Handler) DeleteOutOfOffice(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return
+	}
+	if err := h.svc.DeleteOutOfOffice(c.Request.Context(), uid, uint(id64)); err != nil {
+		h.handleSvcErr(c, err, "取消外出失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// currentUID 从 context 安全地获取用户 ID
+func (h *Handler) currentUID(c *gin.Context) (uint, bool) {
+	val, exists := c.Get(string(contextkeys.UserIDKey))
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return 0, false
+	}
+	uid, ok := val.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "上下文用户ID类型错误"})
+		return 0, false
+	}
+	return uid, true
+}
+
+// 将 service 错误统一映射为 HTTP
+func (h *Handler) handleSvcErr(c *gin.Context, err error, fallback string) {
+	switch e := err.(type) {
+	case *availabilitysvc.ErrValidation:
+		c.JSON(http.StatusBadRequest, gin.H{"error": e.Message, "details": e.Details})
+	case *availabilitysvc.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "资源不存在"})
+	default:
+		log.Printf("Internal server error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
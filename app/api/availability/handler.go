@@ -0,0 +1,11 @@
+package availabilityapi
+
+import availabilitysvc "student-services-platform-backend/app/services/availability"
+
+type Handler struct {
+	svc *availabilitysvc.Service
+}
+
+func New(s *availabilitysvc.Service) *Handler {
+	return &Handler{svc: s}
+}
@@ -3,36 +3,14 @@ package cannedapi
 import (
 	"net/http"
 	"strconv"
-	"strings"
 
 	"student-services-platform-backend/app/contextkeys"
 	"student-services-platform-backend/internal/openapi"
+	"student-services-platform-backend/internal/pagination"
 
 	"github.com/gin-gonic/gin"
 )
 
-func parsePage(c *gin.Context) (int, int) {
-	page := 1
-	pageSize := 20
-	if v := strings.TrimSpace(c.Query("page")); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n >= 1 {
-			page = n
-		}
-	}
-	if v := strings.TrimSpace(c.Query("page_size")); v != "" {
-		if n, err := strconv.Atoi(v); err == nil {
-			if n < 1 {
-				n = 1
-			}
-			if n > 100 {
-				n = 100
-			}
-			pageSize = n
-		}
-	}
-	return page, pageSize
-}
-
 func (h *Handler) List(c *gin.Context) {
 	val, ok := c.Get(string(contextkeys.UserIDKey))
 	if !ok {
@@ -41,8 +19,8 @@ func (h *Handler) List(c *gin.Context) {
 	}
 	uid := val.(uint)
 
-	page, pageSize := parsePage(c)
-	out, err := h.svc.List(uid, page, pageSize)
+	pg := pagination.FromQuery(c.Request.URL.Query())
+	out, err := h.svc.List(uid, pg.Page, pg.PageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败", "details": err.Error()})
 		return
@@ -63,7 +41,7 @@ func (h *Handler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
 		return
 	}
-	cr, err := h.svc.Create(uid, req)
+	cr, err := h.svc.Create(c.Request.Context(), uid, req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -92,7 +70,7 @@ func (h *Handler) Update(c *gin.Context) {
 		return
 	}
 
-	cr, svcErr := h.svc.Update(uid, uint(id64), req)
+	cr, svcErr := h.svc.Update(c.Request.Context(), uid, uint(id64), req)
 	if svcErr != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": svcErr.Error()})
 		return
@@ -115,7 +93,7 @@ func (h *Handler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.svc.Delete(uid, uint(id64)); err != nil {
+	if err := h.svc.Delete(c.Request.Context(), uid, uint(id64)); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
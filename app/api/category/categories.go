@@ -0,0 +1,177 @@
+package categoryapi
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"student-services-platform-backend/app/contextkeys"
+	categorysvc "student-services-platform-backend/app/services/category"
+	"student-services-platform-backend/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /categories 提单表单使用的启用分类（无需登录）
+func (h *Handler) ListActive(c *gin.Context) {
+	out, err := h.svc.ListActive()
+	if err != nil {
+		h.handleSvcErr(c, err, "查询失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// GET /admin/categories
+func (h *Handler) ListAll(c *gin.Context) {
+	out, err := h.svc.ListAll()
+	if err != nil {
+		h.handleSvcErr(c, err, "查询失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// POST /admin/categories
+func (h *Handler) Create(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	var req openapi.CategoryInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+	out, err := h.svc.Create(c.Request.Context(), uid, req)
+	if err != nil {
+		h.handleSvcErr(c, err, "创建失败")
+		return
+	}
+	c.JSON(http.StatusCreated, out)
+}
+
+// PUT /admin/categories/:id
+func (h *Handler) Update(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req openapi.CategoryInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+	out, err := h.svc.Update(c.Request.Context(), uid, id, req)
+	if err != nil {
+		h.handleSvcErr(c, err, "更新失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// DELETE /admin/categories/:id
+func (h *Handler) Delete(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.paramID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(c.Request.Context(), uid, id); err != nil {
+		h.handleSvcErr(c, err, "删除失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /admin/categories/:id/merge-into/:targetId
+func (h *Handler) MergeInto(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.paramID(c, "id")
+	if !ok {
+		return
+	}
+	targetID, ok := h.paramID(c, "targetId")
+	if !ok {
+		return
+	}
+	out, err := h.svc.Merge(c.Request.Context(), uid, id, targetID)
+	if err != nil {
+		h.handleSvcErr(c, err, "合并失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// PUT /admin/categories/:id/fields
+func (h *Handler) ReplaceFields(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req openapi.AdminCategoriesIdFieldsPutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+	out, err := h.svc.ReplaceFields(c.Request.Context(), uid, id, req.Fields)
+	if err != nil {
+		h.handleSvcErr(c, err, "保存字段失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// currentUID 从 context 安全地获取用户 ID
+func (h *Handler) currentUID(c *gin.Context) (uint, bool) {
+	val, exists := c.Get(string(contextkeys.UserIDKey))
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return 0, false
+	}
+	uid, ok := val.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "上下文用户ID类型错误"})
+		return 0, false
+	}
+	return uid, true
+}
+
+// 解析路径参数中的 ID
+func (h *Handler) paramID(c *gin.Context, key string) (uint, bool) {
+	id64, err := strconv.ParseUint(c.Param(key), 10, 64)
+	if err != nil || id64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return 0, false
+	}
+	return uint(id64), true
+}
+
+// 将 service 错误统一映射为 HTTP
+func (h *Handler) handleSvcErr(c *gin.Context, err error, fallback string) {
+	switch e := err.(type) {
+	case *categorysvc.ErrValidation:
+		c.JSON(http.StatusBadRequest, gin.H{"error": e.Message, "details": e.Details})
+	case *categorysvc.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "资源不存在"})
+	case *categorysvc.ErrConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": e.Message})
+	default:
+		log.Printf("Internal server error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
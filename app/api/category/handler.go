@@ -0,0 +1,11 @@
+package categoryapi
+
+import categorysvc "student-services-platform-backend/app/services/category"
+
+type Handler struct {
+	svc *categorysvc.Service
+}
+
+func New(s *categorysvc.Service) *Handler {
+	return &Handler{svc: s}
+}
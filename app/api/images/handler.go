@@ -26,7 +26,7 @@ func (h *Handler) Upload(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
 		return
 	}
-	_ = val.(uint) // 当前未使用；如果需要，可用于增加所有权等
+	uid := val.(uint)
 
 	fileHeader, err := c.FormFile("file")
 	if err != nil {
@@ -40,7 +40,7 @@ func (h *Handler) Upload(c *gin.Context) {
 	}
 	defer f.Close()
 
-	resp, err := h.svc.Upload(f)
+	resp, err := h.svc.Upload(c.Request.Context(), uid, f)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,15 @@
+package savedviewapi
+
+import (
+	savedviewsvc "student-services-platform-backend/app/services/savedview"
+	ticketsvc "student-services-platform-backend/app/services/ticket"
+)
+
+type Handler struct {
+	svc     *savedviewsvc.Service
+	tickets *ticketsvc.Service
+}
+
+func New(s *savedviewsvc.Service, tickets *ticketsvc.Service) *Handler {
+	return &Handler{svc: s, tickets: tickets}
+}
@@ -0,0 +1,157 @@
+package savedviewapi
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"student-services-platform-backend/app/contextkeys"
+	savedviewsvc "student-services-platform-backend/app/services/savedview"
+	ticketsvc "student-services-platform-backend/app/services/ticket"
+	"student-services-platform-backend/internal/openapi"
+	"student-services-platform-backend/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /views
+func (h *Handler) List(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	out, err := h.svc.List(uid)
+	if err != nil {
+		h.handleSvcErr(c, err, "查询失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// POST /views
+func (h *Handler) Create(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	var req openapi.SavedViewCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+	out, err := h.svc.Create(c.Request.Context(), uid, req)
+	if err != nil {
+		h.handleSvcErr(c, err, "创建失败")
+		return
+	}
+	c.JSON(http.StatusCreated, out)
+}
+
+// PUT /views/:id
+func (h *Handler) Update(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.paramID(c)
+	if !ok {
+		return
+	}
+	var req openapi.SavedViewCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+	out, err := h.svc.Update(c.Request.Context(), uid, id, req)
+	if err != nil {
+		h.handleSvcErr(c, err, "更新失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// DELETE /views/:id
+func (h *Handler) Delete(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.paramID(c)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(c.Request.Context(), uid, id); err != nil {
+		h.handleSvcErr(c, err, "删除失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /views/:id/tickets 按视图的筛选条件列出工单（分页参数同 GET /tickets）
+func (h *Handler) Tickets(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.paramID(c)
+	if !ok {
+		return
+	}
+	f, err := h.svc.Filters(uid, id)
+	if err != nil {
+		h.handleSvcErr(c, err, "查询失败")
+		return
+	}
+	out, err := h.tickets.ListTickets(uid, f, pagination.FromQuery(c.Request.URL.Query()))
+	if err != nil {
+		h.handleSvcErr(c, err, "查询失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// currentUID 从 context 安全地获取用户 ID
+func (h *Handler) currentUID(c *gin.Context) (uint, bool) {
+	val, exists := c.Get(string(contextkeys.UserIDKey))
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return 0, false
+	}
+	uid, ok := val.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "上下文用户ID类型错误"})
+		return 0, false
+	}
+	return uid, true
+}
+
+// 解析路径参数 :id
+func (h *Handler) paramID(c *gin.Context) (uint, bool) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return 0, false
+	}
+	return uint(id64), true
+}
+
+// 将 service 错误统一映射为 HTTP
+func (h *Handler) handleSvcErr(c *gin.Context, err error, fallback string) {
+	switch e := err.(type) {
+	case *savedviewsvc.ErrValidation:
+		c.JSON(http.StatusBadRequest, gin.H{"error": e.Message, "details": e.Details})
+	case *savedviewsvc.ErrForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": "无权限", "details": e.Reason})
+	case *savedviewsvc.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "资源不存在"})
+	case *savedviewsvc.ErrConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": e.Message})
+	case *ticketsvc.ErrValidation:
+		c.JSON(http.StatusBadRequest, gin.H{"error": e.Message, "details": e.Details})
+	case *ticketsvc.ErrForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": "无权限", "details": e.Reason})
+	default:
+		log.Printf("Internal server error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,11 @@
+package tagapi
+
+import tagsvc "student-services-platform-backend/app/services/tag"
+
+type Handler struct {
+	svc *tagsvc.Service
+}
+
+func New(s *tagsvc.Service) *Handler {
+	return &Handler{svc: s}
+}
@@ -0,0 +1,125 @@
+package tagapi
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"student-services-platform-backend/app/contextkeys"
+	tagsvc "student-services-platform-backend/app/services/tag"
+	"student-services-platform-backend/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /tags?q=前缀
+func (h *Handler) List(c *gin.Context) {
+	out, err := h.svc.List(c.Query("q"))
+	if err != nil {
+		h.handleSvcErr(c, err, "查询失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// PUT /tags/:id 重命名
+func (h *Handler) Rename(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.paramID(c, "id")
+	if !ok {
+		return
+	}
+	var req openapi.TagsIdPutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请求参数错误", "details": err.Error()})
+		return
+	}
+	out, err := h.svc.Rename(c.Request.Context(), uid, id, req.Name)
+	if err != nil {
+		h.handleSvcErr(c, err, "重命名失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// POST /tags/:id/merge-into/:targetId
+func (h *Handler) MergeInto(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.paramID(c, "id")
+	if !ok {
+		return
+	}
+	targetID, ok := h.paramID(c, "targetId")
+	if !ok {
+		return
+	}
+	out, err := h.svc.Merge(c.Request.Context(), uid, id, targetID)
+	if err != nil {
+		h.handleSvcErr(c, err, "合并失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// DELETE /tags/:id
+func (h *Handler) Delete(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.paramID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(c.Request.Context(), uid, id); err != nil {
+		h.handleSvcErr(c, err, "删除失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// currentUID 从 context 安全地获取用户 ID
+func (h *Handler) currentUID(c *gin.Context) (uint, bool) {
+	val, exists := c.Get(string(contextkeys.UserIDKey))
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未认证"})
+		return 0, false
+	}
+	uid, ok := val.(uint)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "上下文用户ID类型错误"})
+		return 0, false
+	}
+	return uid, true
+}
+
+// 解析路径参数中的 ID
+func (h *Handler) paramID(c *gin.Context, key string) (uint, bool) {
+	id64, err := strconv.ParseUint(c.Param(key), 10, 64)
+	if err != nil || id64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的ID"})
+		return 0, false
+	}
+	return uint(id64), true
+}
+
+// 将 service 错误统一映射为 HTTP
+func (h *Handler) handleSvcErr(c *gin.Context, err error, fallback string) {
+	switch e := err.(type) {
+	case *tagsvc.ErrValidation:
+		c.JSON(http.StatusBadRequest, gin.H{"error": e.Message, "details": e.Details})
+	case *tagsvc.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "资源不存在"})
+	case *tagsvc.ErrConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": e.Message})
+	default:
+		log.Printf("Internal server error: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -111,4 +111,71 @@ func (h *Handler) SpamReview(c *gin.Context) {
 		return
 	}
 	c.Status(http.StatusNoContent)
-}
\ No newline at end of file
+}
+// POST /tickets/:id/reveal-identity
+func (h *Handler) RevealIdentity(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+
+	var req openapi.TicketsIdRevealIdentityPostRequest
+	if !h.mustBindJSON(c, &req) {
+		return
+	}
+
+	out, err := h.svc.RevealIdentity(c.Request.Context(), uid, tid, req.Reason)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "查看提交人身份失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// PUT /tickets/:id/priority
+func (h *Handler) SetPriority(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+
+	var req openapi.TicketsIdPriorityPutRequest
+	if !h.mustBindJSON(c, &req) {
+		return
+	}
+
+	out, err := h.svc.SetPriority(c.Request.Context(), uid, tid, req)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "调整优先级失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// POST /tickets/bulk
+func (h *Handler) Bulk(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+
+	var req openapi.TicketsBulkPostRequest
+	if !h.mustBindJSON(c, &req) {
+		return
+	}
+
+	out, err := h.svc.BulkAction(c.Request.Context(), uid, req)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "批量操作失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"student-services-platform-backend/app/contextkeys"
 	ticketsvc "student-services-platform-backend/app/services/ticket"
@@ -39,27 +40,49 @@ func (h *Handler) paramTicketID(c *gin.Context) (uint, bool) {
 	return uint(tid64), true
 }
 
-// 分页
-func (h *Handler) parsePaging(c *gin.Context) (int, int) {
-	page := 1
-	pageSize := 20
-	if v := c.Query("page"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n >= 1 {
-			page = n
-		}
+func (h *Handler) paramMessageID(c *gin.Context) (uint, bool) {
+	mid64, err := strconv.ParseUint(c.Param("messageId"), 10, 64)
+	if err != nil || mid64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的消息 ID"})
+		return 0, false
 	}
-	if v := c.Query("page_size"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil {
-			if n < 1 {
-				n = 1
-			}
-			if n > 100 {
-				n = 100
-			}
-			pageSize = n
-		}
+	return uint(mid64), true
+}
+
+// 解析正整数 ID 查询参数；错误时直接返回 400
+func (h *Handler) parseUintQuery(c *gin.Context, key string) (*uint, bool) {
+	raw := strings.TrimSpace(c.Query(key))
+	if raw == "" {
+		return nil, true
+	}
+	n, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || n == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": key + " 参数无效"})
+		return nil, false
 	}
-	return page, pageSize
+	v := uint(n)
+	return &v, true
+}
+
+// 解析时间查询参数（RFC3339，或 YYYY-MM-DD 按 UTC 零点）；endOfDay 为 true 时
+// 日期形式取次日零点，便于作为开区间上界包含当天。错误时直接返回 400
+func (h *Handler) parseTimeQuery(c *gin.Context, key string, endOfDay bool) (*time.Time, bool) {
+	raw := strings.TrimSpace(c.Query(key))
+	if raw == "" {
+		return nil, true
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, true
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": key + " 参数无效，应为 RFC3339 或 YYYY-MM-DD"})
+		return nil, false
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, true
 }
 
 // 解析布尔查询参数；错误时直接返回 400
@@ -93,6 +116,14 @@ func (h *Handler) handleTicketSvcErr(c *gin.Context, err error, fallback string)
 		c.JSON(http.StatusConflict, gin.H{"error": e.Message})
 	case *ticketsvc.ErrInvalidState:
 		c.JSON(http.StatusBadRequest, gin.H{"error": e.Message}) // 状态机错误通常是客户端请求时机不对
+	case *ticketsvc.ErrMerged:
+		// 已合并的工单：308 转向目标工单的同一路径（保留方法与请求体）
+		loc := strings.Replace(c.Request.URL.Path, "/tickets/"+c.Param("id"), "/tickets/"+strconv.FormatUint(uint64(e.TargetID), 10), 1)
+		if c.Request.URL.RawQuery != "" {
+			loc += "?" + c.Request.URL.RawQuery
+		}
+		c.Header("Location", loc)
+		c.JSON(http.StatusPermanentRedirect, gin.H{"error": "工单已合并", "details": gin.H{"merged_into_ticket_id": e.TargetID}})
 	default:
 		// 避免暴露过多内部错误细节
 		log.Printf("Internal server error: %v", err)
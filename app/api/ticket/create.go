@@ -17,7 +17,7 @@ func (h *Handler) Create(c *gin.Context) {
 		return
 	}
 
-	out, err := h.svc.CreateTicket(uid, req)
+	out, err := h.svc.CreateTicket(c.Request.Context(), uid, req)
 	if err != nil {
 		h.handleTicketSvcErr(c, err, "创建工单失败")
 		return
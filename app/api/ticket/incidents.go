@@ -0,0 +1,154 @@
+package ticketapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-services-platform-backend/internal/openapi"
+	"student-services-platform-backend/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+// POST /incidents
+func (h *Handler) CreateIncident(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	var req openapi.IncidentCreate
+	if !h.mustBindJSON(c, &req) {
+		return
+	}
+	out, err := h.svc.CreateIncident(c.Request.Context(), uid, req)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "创建事件失败")
+		return
+	}
+	c.JSON(http.StatusCreated, out)
+}
+
+// GET /incidents?status=OPEN|RESOLVED
+func (h *Handler) ListIncidents(c *gin.Context) {
+	pg := pagination.FromQuery(c.Request.URL.Query())
+	out, err := h.svc.ListIncidents(c.Request.Context(), c.Query("status"), pg.Page, pg.PageSize)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "查询失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// GET /incidents/:id
+func (h *Handler) GetIncident(c *gin.Context) {
+	id, ok := h.paramIncidentID(c)
+	if !ok {
+		return
+	}
+	out, err := h.svc.GetIncident(c.Request.Context(), id)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "查询失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// POST /incidents/:id/tickets
+func (h *Handler) AttachIncidentTickets(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.paramIncidentID(c)
+	if !ok {
+		return
+	}
+	var req openapi.IncidentsIdTicketsPostRequest
+	if !h.mustBindJSON(c, &req) {
+		return
+	}
+	out, err := h.svc.AttachIncidentTickets(c.Request.Context(), uid, id, req.TicketIds)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "关联工单失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// DELETE /incidents/:id/tickets/:ticketId
+func (h *Handler) DetachIncidentTicket(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.paramIncidentID(c)
+	if !ok {
+		return
+	}
+	tid, err := strconv.ParseUint(c.Param("ticketId"), 10, 64)
+	if err != nil || tid == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的工单ID"})
+		return
+	}
+	if err := h.svc.DetachIncidentTicket(c.Request.Context(), uid, id, uint(tid)); err != nil {
+		h.handleTicketSvcErr(c, err, "移除工单失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /incidents/:id/updates 发布进展并同步到所有处理中的关联工单
+func (h *Handler) PostIncidentUpdate(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.paramIncidentID(c)
+	if !ok {
+		return
+	}
+	var req openapi.IncidentsIdUpdatesPostRequest
+	if !h.mustBindJSON(c, &req) {
+		return
+	}
+	out, err := h.svc.PostIncidentUpdate(c.Request.Context(), uid, id, req.Body)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "发布进展失败")
+		return
+	}
+	c.JSON(http.StatusCreated, out)
+}
+
+// POST /incidents/:id/resolve（请求体可省略）
+func (h *Handler) ResolveIncident(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.paramIncidentID(c)
+	if !ok {
+		return
+	}
+	var req openapi.IncidentsIdResolvePostRequest
+	if c.Request.ContentLength != 0 {
+		if !h.mustBindJSON(c, &req) {
+			return
+		}
+	}
+	out, err := h.svc.ResolveIncident(c.Request.Context(), uid, id, req.Resolution)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "处理事件失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// 解析路径参数 :id（事件）
+func (h *Handler) paramIncidentID(c *gin.Context) (uint, bool) {
+	id64, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的事件 ID"})
+		return 0, false
+	}
+	return uint(id64), true
+}
@@ -0,0 +1,100 @@
+package ticketapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-services-platform-backend/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /tickets/:id/links
+func (h *Handler) ListLinks(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+
+	out, err := h.svc.ListLinks(uid, tid)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "查询失败")
+		return
+	}
+	c.JSON(http.StatusOK, openapi.TicketsIdLinksGet200Response{Items: out})
+}
+
+// POST /tickets/:id/links
+func (h *Handler) AddLink(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+
+	var req openapi.TicketLinkCreate
+	if !h.mustBindJSON(c, &req) {
+		return
+	}
+
+	out, err := h.svc.AddLink(c.Request.Context(), uid, tid, req)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "添加关联失败")
+		return
+	}
+	c.JSON(http.StatusCreated, out)
+}
+
+// DELETE /tickets/:id/links/:linkId
+func (h *Handler) RemoveLink(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+	linkID, err := strconv.ParseUint(c.Param("linkId"), 10, 64)
+	if err != nil || linkID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的关联ID"})
+		return
+	}
+
+	if err := h.svc.RemoveLink(c.Request.Context(), uid, tid, uint(linkID)); err != nil {
+		h.handleTicketSvcErr(c, err, "删除关联失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /tickets/:id/follow-up 基于已关闭的工单创建后续工单
+func (h *Handler) CreateFollowUp(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+
+	var req openapi.TicketCreate
+	if !h.mustBindJSON(c, &req) {
+		return
+	}
+
+	out, err := h.svc.CreateFollowUp(c.Request.Context(), uid, tid, req)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "创建后续工单失败")
+		return
+	}
+	c.JSON(http.StatusCreated, out)
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	ticketsvc "student-services-platform-backend/app/services/ticket"
+	"student-services-platform-backend/internal/pagination"
 	"github.com/gin-gonic/gin"
 )
 
@@ -14,33 +15,82 @@ func (h *Handler) List(c *gin.Context) {
 		return
 	}
 
-	page, pageSize := h.parsePaging(c)
+	f := ticketsvc.ListFilters{
+		Category: strings.TrimSpace(c.Query("category")),
+		Keyword:  strings.TrimSpace(c.Query("keyword")),
+		Sort:     strings.TrimSpace(c.Query("sort")),
+		Order:    strings.TrimSpace(c.Query("order")),
+	}
 
-	status := strings.TrimSpace(c.Query("status"))
-	category := strings.TrimSpace(c.Query("category"))
+	// status 支持逗号分隔的多个值，例如 status=NEW,CLAIMED
+	if raw := strings.TrimSpace(c.Query("status")); raw != "" {
+		for _, st := range strings.Split(raw, ",") {
+			if st = strings.TrimSpace(st); st != "" {
+				f.Statuses = append(f.Statuses, st)
+			}
+		}
+	}
 
-	isUrgent, ok := h.parseBoolQuery(c, "is_urgent")
-	if !ok {
+	// priority 支持逗号分隔的多个值，例如 priority=P1,P2
+	if raw := strings.TrimSpace(c.Query("priority")); raw != "" {
+		for _, p := range strings.Split(raw, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				f.Priorities = append(f.Priorities, p)
+			}
+		}
+	}
+
+	// tag 支持逗号分隔的多个值，需同时带有全部标签，例如 tag=refund,repeat-issue
+	if raw := strings.TrimSpace(c.Query("tag")); raw != "" {
+		for _, tg := range strings.Split(raw, ",") {
+			if tg = strings.TrimSpace(tg); tg != "" {
+				f.Tags = append(f.Tags, tg)
+			}
+		}
+	}
+
+	// field.<key>=<value> 按自定义字段精确匹配，可出现多个
+	for k, vs := range c.Request.URL.Query() {
+		if key, found := strings.CutPrefix(k, "field."); found && len(vs) > 0 {
+			if f.Fields == nil {
+				f.Fields = map[string]string{}
+			}
+			f.Fields[key] = vs[0]
+		}
+	}
+
+	if f.IsUrgent, ok = h.parseBoolQuery(c, "is_urgent"); !ok {
 		return
 	}
-	assignedToMe, ok := h.parseBoolQuery(c, "assigned_to_me")
-	if !ok {
+	if f.AssignedToMe, ok = h.parseBoolQuery(c, "assigned_to_me"); !ok {
+		return
+	}
+	if f.Unassigned, ok = h.parseBoolQuery(c, "unassigned"); !ok {
+		return
+	}
+	if f.AssigneeID, ok = h.parseUintQuery(c, "assignee_id"); !ok {
+		return
+	}
+	if f.CreatorID, ok = h.parseUintQuery(c, "creator_id"); !ok {
+		return
+	}
+	if f.CreatedFrom, ok = h.parseTimeQuery(c, "created_from", false); !ok {
+		return
+	}
+	if f.CreatedTo, ok = h.parseTimeQuery(c, "created_to", true); !ok {
+		return
+	}
+	if f.UpdatedFrom, ok = h.parseTimeQuery(c, "updated_from", false); !ok {
+		return
+	}
+	if f.UpdatedTo, ok = h.parseTimeQuery(c, "updated_to", true); !ok {
 		return
 	}
 
-	out, svcErr := h.svc.ListTickets(
-		uid,
-		ticketsvc.ListFilters{
-			Status:       status,
-			Category:     category,
-			IsUrgent:     isUrgent,
-			AssignedToMe: assignedToMe,
-		},
-		page, pageSize,
-	)
+	out, svcErr := h.svc.ListTickets(uid, f, pagination.FromQuery(c.Request.URL.Query()))
 	if svcErr != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败", "details": svcErr.Error()})
+		h.handleTicketSvcErr(c, svcErr, "查询失败")
 		return
 	}
 	c.JSON(http.StatusOK, out)
-}
\ No newline at end of file
+}
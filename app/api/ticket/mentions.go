@@ -0,0 +1,56 @@
+package ticketapi
+
+import (
+	"net/http"
+
+	"student-services-platform-backend/internal/openapi"
+	"student-services-platform-backend/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /users/me/mentions
+func (h *Handler) ListMyMentions(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	unread, ok := h.parseBoolQuery(c, "unread")
+	if !ok {
+		return
+	}
+
+	out, err := h.svc.ListMentions(uid, unread != nil && *unread, pagination.FromQuery(c.Request.URL.Query()))
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "查询失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// POST /users/me/mentions/read
+func (h *Handler) MarkMentionsRead(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+
+	var req openapi.UsersMeMentionsReadPostRequest
+	if c.Request.ContentLength != 0 && !h.mustBindJSON(c, &req) {
+		return
+	}
+	ids := make([]uint, 0, len(req.Ids))
+	for _, id := range req.Ids {
+		if id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的提及ID"})
+			return
+		}
+		ids = append(ids, uint(id))
+	}
+
+	if err := h.svc.MarkMentionsRead(c.Request.Context(), uid, ids); err != nil {
+		h.handleTicketSvcErr(c, err, "标记已读失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
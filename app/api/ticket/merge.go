@@ -0,0 +1,31 @@
+package ticketapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// POST /tickets/:id/merge-into/:targetId
+func (h *Handler) MergeInto(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+	target64, err := strconv.ParseUint(c.Param("targetId"), 10, 64)
+	if err != nil || target64 == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的目标工单ID"})
+		return
+	}
+
+	if err := h.svc.MergeTicket(c.Request.Context(), uid, tid, uint(target64)); err != nil {
+		h.handleTicketSvcErr(c, err, "合并工单失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -3,6 +3,7 @@ package ticketapi
 import (
 	"net/http"
 	"student-services-platform-backend/internal/openapi"
+	"student-services-platform-backend/internal/pagination"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,9 +17,7 @@ func (h *Handler) ListMessages(c *gin.Context) {
 	if !ok {
 		return
 	}
-	page, pageSize := h.parsePaging(c)
-
-	out, svcErr := h.svc.ListMessages(uid, tid, page, pageSize)
+	out, svcErr := h.svc.ListMessages(uid, tid, pagination.FromQuery(c.Request.URL.Query()))
 	if svcErr != nil {
 		h.handleTicketSvcErr(c, svcErr, "查询失败")
 		return
@@ -42,10 +41,109 @@ func (h *Handler) PostMessage(c *gin.Context) {
 		return
 	}
 
-	msg, svcErr := h.svc.PostMessage(uid, tid, req.Body, req.IsInternalNote)
+	msg, svcErr := h.svc.PostMessage(c.Request.Context(), uid, tid, req.Body, req.IsInternalNote, req.AttachmentIds)
 	if svcErr != nil {
 		h.handleTicketSvcErr(c, svcErr, "创建消息失败")
 		return
 	}
 	c.JSON(http.StatusCreated, msg)
-}
\ No newline at end of file
+}
+// PATCH /tickets/:id/messages/:messageId
+func (h *Handler) EditMessage(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+	mid, ok := h.paramMessageID(c)
+	if !ok {
+		return
+	}
+
+	var req openapi.TicketsIdMessagesMessageIdPatchRequest
+	if !h.mustBindJSON(c, &req) {
+		return
+	}
+
+	msg, err := h.svc.EditMessage(c.Request.Context(), uid, tid, mid, req)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "编辑消息失败")
+		return
+	}
+	c.JSON(http.StatusOK, msg)
+}
+
+// DELETE /tickets/:id/messages/:messageId
+func (h *Handler) DeleteMessage(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+	mid, ok := h.paramMessageID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeleteMessage(c.Request.Context(), uid, tid, mid); err != nil {
+		h.handleTicketSvcErr(c, err, "删除消息失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /tickets/:id/messages/:messageId/redact
+func (h *Handler) RedactMessage(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+	mid, ok := h.paramMessageID(c)
+	if !ok {
+		return
+	}
+
+	var req openapi.TicketsIdMessagesMessageIdRedactPostRequest
+	if !h.mustBindJSON(c, &req) {
+		return
+	}
+
+	if err := h.svc.RedactMessage(c.Request.Context(), uid, tid, mid, req.Reason); err != nil {
+		h.handleTicketSvcErr(c, err, "抹除消息失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /tickets/:id/messages/:messageId/revisions
+func (h *Handler) ListMessageRevisions(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+	mid, ok := h.paramMessageID(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.ListMessageRevisions(uid, tid, mid)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "获取消息编辑历史失败")
+		return
+	}
+	c.JSON(http.StatusOK, openapi.TicketsIdMessagesMessageIdRevisionsGet200Response{Items: items})
+}
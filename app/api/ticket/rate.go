@@ -23,7 +23,7 @@ func (h *Handler) Rate(c *gin.Context) {
 		return
 	}
 
-	r, svcErr := h.svc.RateTicket(uid, tid, int(req.Stars), req.Comment)
+	r, svcErr := h.svc.RateTicket(c.Request.Context(), uid, tid, int(req.Stars), req.Comment)
 	if svcErr != nil {
 		h.handleTicketSvcErr(c, svcErr, "评分失败")
 		return
@@ -0,0 +1,55 @@
+package ticketapi
+
+import (
+	"net/http"
+
+	"student-services-platform-backend/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// POST /tickets/:id/read
+func (h *Handler) MarkRead(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+
+	var req openapi.TicketsIdReadPostRequest
+	if c.Request.ContentLength != 0 && !h.mustBindJSON(c, &req) {
+		return
+	}
+	var mid uint
+	if req.MessageId != nil {
+		if *req.MessageId <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "无效的消息 ID"})
+			return
+		}
+		mid = uint(*req.MessageId)
+	}
+
+	if err := h.svc.MarkRead(c.Request.Context(), uid, tid, mid); err != nil {
+		h.handleTicketSvcErr(c, err, "标记已读失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /users/me/unread
+func (h *Handler) MyUnread(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+
+	out, err := h.svc.UnreadSummary(uid)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "查询失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
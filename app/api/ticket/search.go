@@ -0,0 +1,26 @@
+package ticketapi
+
+import (
+	"net/http"
+
+	"student-services-platform-backend/internal/pagination"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Search 全文检索工单（GET /tickets/search?q=）
+func (h *Handler) Search(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+
+	pg := pagination.FromQuery(c.Request.URL.Query())
+
+	out, err := h.svc.SearchTickets(uid, c.Query("q"), pg.Page, pg.PageSize)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "搜索失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
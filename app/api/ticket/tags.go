@@ -0,0 +1,51 @@
+package ticketapi
+
+import (
+	"net/http"
+
+	"student-services-platform-backend/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// POST /tickets/:id/tags
+func (h *Handler) AddTags(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+
+	var req openapi.TicketsIdTagsPostRequest
+	if !h.mustBindJSON(c, &req) {
+		return
+	}
+
+	tags, err := h.svc.AddTicketTags(c.Request.Context(), uid, tid, req.Tags)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "添加标签失败")
+		return
+	}
+	c.JSON(http.StatusOK, openapi.TicketsIdTagsPost200Response{Tags: tags})
+}
+
+// DELETE /tickets/:id/tags/:tag
+func (h *Handler) RemoveTag(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.RemoveTicketTag(c.Request.Context(), uid, tid, c.Param("tag")); err != nil {
+		h.handleTicketSvcErr(c, err, "移除标签失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
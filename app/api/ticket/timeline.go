@@ -0,0 +1,26 @@
+package ticketapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /tickets/:id/timeline
+func (h *Handler) Timeline(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+
+	out, err := h.svc.GetTimeline(uid, tid)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "获取工单时间线失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
@@ -0,0 +1,33 @@
+package ticketapi
+
+import (
+	"net/http"
+
+	"student-services-platform-backend/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Update 创建者在工单被受理前编辑工单（PUT /tickets/:id）
+func (h *Handler) Update(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+
+	var req openapi.TicketUpdate
+	if !h.mustBindJSON(c, &req) {
+		return
+	}
+
+	out, err := h.svc.UpdateTicket(c.Request.Context(), uid, tid, req)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "编辑工单失败")
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
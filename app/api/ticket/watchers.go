@@ -0,0 +1,98 @@
+package ticketapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"student-services-platform-backend/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// POST /tickets/:id/watch
+func (h *Handler) Watch(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.svc.WatchTicket(c.Request.Context(), uid, tid, uid)
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "关注失败")
+		return
+	}
+	c.JSON(http.StatusOK, openapi.TicketsIdWatchPost200Response{Items: items})
+}
+
+// DELETE /tickets/:id/watch
+func (h *Handler) Unwatch(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.UnwatchTicket(c.Request.Context(), uid, tid, uid); err != nil {
+		h.handleTicketSvcErr(c, err, "取消关注失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// POST /tickets/:id/watchers
+func (h *Handler) AddWatcher(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+
+	var req openapi.TicketsIdWatchersPostRequest
+	if !h.mustBindJSON(c, &req) {
+		return
+	}
+	if req.UserId <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	items, err := h.svc.WatchTicket(c.Request.Context(), uid, tid, uint(req.UserId))
+	if err != nil {
+		h.handleTicketSvcErr(c, err, "添加关注者失败")
+		return
+	}
+	c.JSON(http.StatusOK, openapi.TicketsIdWatchPost200Response{Items: items})
+}
+
+// DELETE /tickets/:id/watchers/:userId
+func (h *Handler) RemoveWatcher(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	if err != nil || userID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的用户ID"})
+		return
+	}
+
+	if err := h.svc.UnwatchTicket(c.Request.Context(), uid, tid, uint(userID)); err != nil {
+		h.handleTicketSvcErr(c, err, "移除关注者失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
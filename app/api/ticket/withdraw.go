@@ -0,0 +1,34 @@
+package ticketapi
+
+import (
+	"net/http"
+
+	"student-services-platform-backend/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// POST /tickets/:id/withdraw（请求体可省略）
+func (h *Handler) Withdraw(c *gin.Context) {
+	uid, ok := h.currentUID(c)
+	if !ok {
+		return
+	}
+	tid, ok := h.paramTicketID(c)
+	if !ok {
+		return
+	}
+
+	var req openapi.TicketsIdWithdrawPostRequest
+	if c.Request.ContentLength != 0 {
+		if !h.mustBindJSON(c, &req) {
+			return
+		}
+	}
+
+	if err := h.svc.WithdrawTicket(c.Request.Context(), uid, tid, req.Reason); err != nil {
+		h.handleTicketSvcErr(c, err, "撤回工单失败")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -40,7 +40,7 @@ func (h *Handler) UpdateMe(c *gin.Context) {
 		return
 	}
 
-	out, err := h.svc.UpdateByID(uid, usersvc.UpdateFields{
+	out, err := h.svc.UpdateByID(c.Request.Context(), uid, usersvc.UpdateFields{
 		Email:      req.Email,
 		Name:       req.Name,
 		Phone:      req.Phone,
@@ -0,0 +1,67 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	auditlogsvc "student-services-platform-backend/app/services/auditlog"
+	ticketsvc "student-services-platform-backend/app/services/ticket"
+	"student-services-platform-backend/internal/config"
+	"student-services-platform-backend/internal/scheduler"
+)
+
+// 任务名（同时是数据库锁名）
+const (
+	// JobAutoCloseResolved 自动关闭已处理工单
+	JobAutoCloseResolved = "ticket.auto_close_resolved"
+	// JobAnchorAuditChain 将审计日志链头追加到锚定文件
+	JobAnchorAuditChain = "audit.anchor_chain"
+)
+
+// Register 按配置注册所有定时任务
+func Register(s *scheduler.Scheduler, cfg config.SchedulerConfig, ticketSvc *ticketsvc.Service, auditLogSvc *auditlogsvc.Service) error {
+	if cfg.AutoCloseAfterDays > 0 {
+		interval, err := time.ParseDuration(cfg.AutoCloseInterval)
+		if err != nil || interval <= 0 {
+			interval = time.Hour
+		}
+		after := time.Duration(cfg.AutoCloseAfterDays) * 24 * time.Hour
+		err = s.Register(scheduler.Job{
+			Name:     JobAutoCloseResolved,
+			Interval: interval,
+			Run: func(ctx context.Context) error {
+				n, err := ticketSvc.AutoCloseResolved(ctx, after)
+				if n > 0 {
+					log.Printf("jobs: 自动关闭了 %d 个已处理工单", n)
+				}
+				return err
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	// 锚定文件只写在执行任务的实例本机上，多实例部署时应指向共享的只追加存储
+	if auditLogSvc != nil && auditLogSvc.AnchoringEnabled() {
+		interval, err := time.ParseDuration(cfg.AuditAnchorInterval)
+		if err != nil || interval <= 0 {
+			interval = time.Hour
+		}
+		err = s.Register(scheduler.Job{
+			Name:     JobAnchorAuditChain,
+			Interval: interval,
+			Run: func(ctx context.Context) error {
+				a, err := auditLogSvc.AnchorHead(ctx)
+				if a != nil {
+					log.Printf("jobs: 已锚定审计日志链头 id=%d hash=%s", a.LastID, a.Hash)
+				}
+				return err
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"student-services-platform-backend/internal/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader 请求 ID 的请求/响应头
+const RequestIDHeader = "X-Request-ID"
+
+// 客户端或网关传入的请求 ID 只接受这些字符，避免把任意内容写进日志
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestMeta 为每个请求确定请求 ID（沿用合法的 X-Request-ID，否则生成），写回响应头，
+// 并将客户端 IP 与请求 ID 放入请求 context，供审计日志记录
+func RequestMeta() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rid := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(rid) {
+			rid = newRequestID()
+		}
+		c.Header(RequestIDHeader, rid)
+		ctx := audit.WithMeta(c.Request.Context(), audit.Meta{IP: c.ClientIP(), RequestID: rid})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -1,9 +1,14 @@
 package router
 
 import (
+	auditlogapi "student-services-platform-backend/app/api/auditlog"
 	authapi "student-services-platform-backend/app/api/auth"
 	adminuserapi "student-services-platform-backend/app/api/adminuser"
+	availabilityapi "student-services-platform-backend/app/api/availability"
+	categoryapi "student-services-platform-backend/app/api/category"
 	imagesapi "student-services-platform-backend/app/api/images"
+	savedviewapi "student-services-platform-backend/app/api/savedview"
+	tagapi "student-services-platform-backend/app/api/tag"
 	ticketapi "student-services-platform-backend/app/api/ticket"
 	userapi "student-services-platform-backend/app/api/user"
 
@@ -29,6 +34,11 @@ func Init(
 	adminStatsH *adminstatsapi.Handler,
 	cannedH *cannedapi.Handler,
 	adminUserH *adminuserapi.Handler,
+	availabilityH *availabilityapi.Handler,
+	savedViewH *savedviewapi.Handler,
+	tagH *tagapi.Handler,
+	categoryH *categoryapi.Handler,
+	auditLogH *auditlogapi.Handler,
 ) {
 	authRG := api.Group("/auth")
 	{
@@ -40,6 +50,7 @@ func Init(
 	{
 		userRG.GET("/me", middleware.JWTAuth(cfg.JWT.SecretKey), userH.GetMe)
 		userRG.PUT("/me", middleware.JWTAuth(cfg.JWT.SecretKey), userH.UpdateMe)
+		userRG.GET("/me/unread", middleware.JWTAuth(cfg.JWT.SecretKey), ticketH.MyUnread)
 	}
 
 	// 管理员：用户管理（仅限超级管理员）
@@ -55,6 +66,60 @@ func Init(
 		adminUserRG.DELETE("/:id", adminUserH.DeleteUser)
 	}
 
+	// 管理员：本人在岗状态、班次、外出与提及收件箱（管理员 + 超级管理员）
+	meDutyRG := api.Group("/users/me",
+		middleware.JWTAuth(cfg.JWT.SecretKey),
+		middleware.RequireRole(database, dbpkg.RoleAdmin, dbpkg.RoleSuperAdmin),
+	)
+	{
+		meDutyRG.GET("/availability", availabilityH.GetMe)
+		meDutyRG.PUT("/availability", availabilityH.UpdateMe)
+		meDutyRG.PUT("/shifts", availabilityH.ReplaceShifts)
+		meDutyRG.POST("/out-of-office", availabilityH.CreateOutOfOffice)
+		meDutyRG.DELETE("/out-of-office/:id", availabilityH.DeleteOutOfOffice)
+		meDutyRG.GET("/mentions", ticketH.ListMyMentions)
+		meDutyRG.POST("/mentions/read", ticketH.MarkMentionsRead)
+	}
+
+	// 管理员：查看所有管理员在岗情况（管理员 + 超级管理员）
+	availabilityRG := api.Group("/availability",
+		middleware.JWTAuth(cfg.JWT.SecretKey),
+		middleware.RequireRole(database, dbpkg.RoleAdmin, dbpkg.RoleSuperAdmin),
+	)
+	{
+		availabilityRG.GET("", availabilityH.List)
+	}
+
+	// 管理员：保存的工单视图（管理员 + 超级管理员）
+	viewsRG := api.Group("/views",
+		middleware.JWTAuth(cfg.JWT.SecretKey),
+		middleware.RequireRole(database, dbpkg.RoleAdmin, dbpkg.RoleSuperAdmin),
+	)
+	{
+		viewsRG.GET("", savedViewH.List)
+		viewsRG.POST("", savedViewH.Create)
+		viewsRG.PUT("/:id", savedViewH.Update)
+		viewsRG.DELETE("/:id", savedViewH.Delete)
+		viewsRG.GET("/:id/tickets", savedViewH.Tickets)
+	}
+
+	// 工单分类目录（提单表单使用，无需登录）
+	api.GET("/categories", categoryH.ListActive)
+
+	// 管理员：工单标签（查看：管理员 + 超级管理员；重命名/合并/删除：仅限超级管理员）
+	tagsRG := api.Group("/tags",
+		middleware.JWTAuth(cfg.JWT.SecretKey),
+		middleware.RequireRole(database, dbpkg.RoleAdmin, dbpkg.RoleSuperAdmin),
+	)
+	{
+		superAdminOnly := middleware.RequireRole(database, dbpkg.RoleSuperAdmin)
+
+		tagsRG.GET("", tagH.List)
+		tagsRG.PUT("/:id", superAdminOnly, tagH.Rename)
+		tagsRG.POST("/:id/merge-into/:targetId", superAdminOnly, tagH.MergeInto)
+		tagsRG.DELETE("/:id", superAdminOnly, tagH.Delete)
+	}
+
 	// 图片端点（需要认证）
 	imagesRG := api.Group("/images", middleware.JWTAuth(cfg.JWT.SecretKey))
 	{
@@ -67,32 +132,81 @@ func Init(
 		// 学生/管理员共有
 		ticketsRG.POST("", ticketH.Create)
 		ticketsRG.GET("", ticketH.List)
+		ticketsRG.GET("/search", ticketH.Search)
 		ticketsRG.GET("/:id", ticketH.Detail)
+		ticketsRG.PUT("/:id", ticketH.Update)
+		ticketsRG.GET("/:id/timeline", ticketH.Timeline)
 		ticketsRG.GET("/:id/messages", ticketH.ListMessages)
 		ticketsRG.POST("/:id/messages", ticketH.PostMessage)
+		ticketsRG.PATCH("/:id/messages/:messageId", ticketH.EditMessage)
+		ticketsRG.GET("/:id/messages/:messageId/revisions", ticketH.ListMessageRevisions)
+		ticketsRG.POST("/:id/read", ticketH.MarkRead)
 		ticketsRG.POST("/:id/rate", ticketH.Rate)
+		ticketsRG.POST("/:id/withdraw", ticketH.Withdraw)
+		ticketsRG.POST("/:id/follow-up", ticketH.CreateFollowUp)
+		ticketsRG.GET("/:id/links", ticketH.ListLinks)
 
 		// 管理员工作流
 		adminOnly := middleware.RequireRole(database, dbpkg.RoleAdmin, dbpkg.RoleSuperAdmin)
 		superAdminOnly := middleware.RequireRole(database, dbpkg.RoleSuperAdmin)
 
+		ticketsRG.POST("/bulk", adminOnly, ticketH.Bulk)
 		ticketsRG.POST("/:id/claim", adminOnly, ticketH.Claim)
 		ticketsRG.POST("/:id/unclaim", adminOnly, ticketH.Unclaim)
 		ticketsRG.POST("/:id/resolve", adminOnly, ticketH.Resolve)
 		ticketsRG.POST("/:id/close", adminOnly, ticketH.Close)
+		ticketsRG.PUT("/:id/priority", adminOnly, ticketH.SetPriority)
+		ticketsRG.POST("/:id/merge-into/:targetId", adminOnly, ticketH.MergeInto)
+		ticketsRG.POST("/:id/links", adminOnly, ticketH.AddLink)
+		ticketsRG.DELETE("/:id/links/:linkId", adminOnly, ticketH.RemoveLink)
+		ticketsRG.POST("/:id/tags", adminOnly, ticketH.AddTags)
+		ticketsRG.DELETE("/:id/tags/:tag", adminOnly, ticketH.RemoveTag)
+		ticketsRG.POST("/:id/watch", adminOnly, ticketH.Watch)
+		ticketsRG.DELETE("/:id/watch", adminOnly, ticketH.Unwatch)
+		ticketsRG.POST("/:id/watchers", adminOnly, ticketH.AddWatcher)
+		ticketsRG.DELETE("/:id/watchers/:userId", adminOnly, ticketH.RemoveWatcher)
+		ticketsRG.DELETE("/:id/messages/:messageId", adminOnly, ticketH.DeleteMessage)
+		ticketsRG.POST("/:id/messages/:messageId/redact", superAdminOnly, ticketH.RedactMessage)
 
 		// 垃圾标记 & 审核
 		ticketsRG.POST("/:id/spam-flag", adminOnly, ticketH.SpamFlag)
 		ticketsRG.POST("/:id/spam-review", superAdminOnly, ticketH.SpamReview)
+
+		// 匿名工单：查看提交人身份（仅超级管理员，需填写原因并记审计）
+		ticketsRG.POST("/:id/reveal-identity", superAdminOnly, ticketH.RevealIdentity)
+	}
+
+	// 管理员：事件（批量处理同类工单，管理员 + 超级管理员）
+	incidentsRG := api.Group("/incidents",
+		middleware.JWTAuth(cfg.JWT.SecretKey),
+		middleware.RequireRole(database, dbpkg.RoleAdmin, dbpkg.RoleSuperAdmin),
+	)
+	{
+		incidentsRG.POST("", ticketH.CreateIncident)
+		incidentsRG.GET("", ticketH.ListIncidents)
+		incidentsRG.GET("/:id", ticketH.GetIncident)
+		incidentsRG.POST("/:id/tickets", ticketH.AttachIncidentTickets)
+		incidentsRG.DELETE("/:id/tickets/:ticketId", ticketH.DetachIncidentTicket)
+		incidentsRG.POST("/:id/updates", ticketH.PostIncidentUpdate)
+		incidentsRG.POST("/:id/resolve", ticketH.ResolveIncident)
 	}
 
-	// 管理员：统计（仅限超级管理员）
+	// 管理员：统计、分类目录与审计日志（仅限超级管理员）
 	adminRG := api.Group("/admin",
 		middleware.JWTAuth(cfg.JWT.SecretKey),
 		middleware.RequireRole(database, dbpkg.RoleSuperAdmin),
 	)
 	{
 		adminRG.GET("/stats", adminStatsH.Get)
+		adminRG.GET("/categories", categoryH.ListAll)
+		adminRG.POST("/categories", categoryH.Create)
+		adminRG.PUT("/categories/:id", categoryH.Update)
+		adminRG.DELETE("/categories/:id", categoryH.Delete)
+		adminRG.POST("/categories/:id/merge-into/:targetId", categoryH.MergeInto)
+		adminRG.PUT("/categories/:id/fields", categoryH.ReplaceFields)
+		adminRG.GET("/audit-logs", auditLogH.List)
+		adminRG.GET("/audit-logs/export", auditLogH.Export)
+		adminRG.GET("/audit-logs/verify", auditLogH.Verify)
 	}
 
 	// 管理员：常用回复（管理员 + 超级管理员）
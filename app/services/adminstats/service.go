@@ -54,22 +54,31 @@ func (s *Service) Stats(from, to time.Time) (*openapi.AdminStatsGet200Response,
 	var resp openapi.AdminStatsGet200Response
 
 	// ---- 总计 ----
+	// 已撤回（CANCELLED）与已合并（MERGED）的工单不计入工单总数，单独统计；
+	// 分类、标签与每日趋势同样排除这两类，保证各项分布与工单总数一致
+	excluded := []dbpkg.TicketStatus{dbpkg.TicketStatusCancelled, dbpkg.TicketStatusMerged}
 	type totalsRow struct {
 		Tickets       int64
 		Resolved      int64
 		Closed        int64
 		SpamConfirmed int64
+		Cancelled     int64
+		Merged        int64
 	}
 	var tr totalsRow
 	if err := s.db.Raw(
 		`SELECT
-   COUNT(*) AS tickets,
+   SUM(CASE WHEN status NOT IN (?, ?) THEN 1 ELSE 0 END) AS tickets,
    SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS resolved,
    SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS closed,
-   SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS spam_confirmed
+   SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS spam_confirmed,
+   SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS cancelled,
+   SUM(CASE WHEN status = ? THEN 1 ELSE 0 END) AS merged
   FROM tickets
   WHERE created_at >= ? AND created_at < ?`,
-		dbpkg.TicketStatusResolved, dbpkg.TicketStatusClosed, dbpkg.TicketStatusSpamConfirmed, from, to,
+		dbpkg.TicketStatusCancelled, dbpkg.TicketStatusMerged,
+		dbpkg.TicketStatusResolved, dbpkg.TicketStatusClosed, dbpkg.TicketStatusSpamConfirmed,
+		dbpkg.TicketStatusCancelled, dbpkg.TicketStatusMerged, from, to,
 	).Scan(&tr).Error; err != nil {
 		return nil, err
 	}
@@ -78,6 +87,8 @@ func (s *Service) Stats(from, to time.Time) (*openapi.AdminStatsGet200Response,
 		Resolved:      int32(tr.Resolved),
 		Closed:        int32(tr.Closed),
 		SpamConfirmed: int32(tr.SpamConfirmed),
+		Cancelled:     int32(tr.Cancelled),
+		Merged:        int32(tr.Merged),
 	}
 
 	// ---- 按分类统计 ----
@@ -89,10 +100,10 @@ func (s *Service) Stats(from, to time.Time) (*openapi.AdminStatsGet200Response,
 	if err := s.db.Raw(
 		`SELECT category, COUNT(*) AS count
    FROM tickets
-  WHERE created_at >= ? AND created_at < ?
+  WHERE created_at >= ? AND created_at < ? AND status NOT IN ?
   GROUP BY category
   ORDER BY count DESC`,
-		from, to,
+		from, to, excluded,
 	).Scan(&cats).Error; err != nil {
 		return nil, err
 	}
@@ -104,6 +115,33 @@ func (s *Service) Stats(from, to time.Time) (*openapi.AdminStatsGet200Response,
 		})
 	}
 
+	// ---- 按标签统计 ----
+	// 一个工单可带多个标签，各标签计数之和可能大于工单总数
+	type tagRow struct {
+		Tag   string
+		Count int64
+	}
+	var tags []tagRow
+	if err := s.db.Raw(
+		`SELECT tg.name AS tag, COUNT(*) AS count
+   FROM ticket_tags tt
+   JOIN tags tg ON tg.id = tt.tag_id
+   JOIN tickets t ON t.id = tt.ticket_id
+  WHERE t.created_at >= ? AND t.created_at < ? AND t.status NOT IN ?
+  GROUP BY tg.name
+  ORDER BY count DESC, tg.name ASC`,
+		from, to, excluded,
+	).Scan(&tags).Error; err != nil {
+		return nil, err
+	}
+	resp.ByTag = make([]openapi.AdminStatsGet200ResponseByTagInner, 0, len(tags))
+	for _, r := range tags {
+		resp.ByTag = append(resp.ByTag, openapi.AdminStatsGet200ResponseByTagInner{
+			Tag:   r.Tag,
+			Count: int32(r.Count),
+		})
+	}
+
 	// ---- 每日趋势 ----
 	var trendSQL string
 	switch s.db.Dialector.Name() {
@@ -113,7 +151,7 @@ func (s *Service) Stats(from, to time.Time) (*openapi.AdminStatsGet200Response,
 		trendSQL = `
 SELECT to_char((created_at AT TIME ZONE 'UTC')::date, 'YYYY-MM-DD') AS date, COUNT(*) AS count
    FROM tickets
-  WHERE created_at >= ? AND created_at < ?
+  WHERE created_at >= ? AND created_at < ? AND status NOT IN ?
   GROUP BY 1
   ORDER BY 1`
 	case "sqlite":
@@ -121,7 +159,7 @@ SELECT to_char((created_at AT TIME ZONE 'UTC')::date, 'YYYY-MM-DD') AS date, COU
 		trendSQL = `
 SELECT strftime('%Y-%m-%d', created_at) AS date, COUNT(*) AS count
    FROM tickets
-  WHERE created_at >= ? AND created_at < ?
+  WHERE created_at >= ? AND created_at < ? AND status NOT IN ?
   GROUP BY 1
   ORDER BY 1`
 	default:
@@ -129,7 +167,7 @@ SELECT strftime('%Y-%m-%d', created_at) AS date, COUNT(*) AS count
 		trendSQL = `
 SELECT DATE(created_at) AS date, COUNT(*) AS count
    FROM tickets
-  WHERE created_at >= ? AND created_at < ?
+  WHERE created_at >= ? AND created_at < ? AND status NOT IN ?
   GROUP BY 1
   ORDER BY 1`
 	}
@@ -138,7 +176,7 @@ SELECT DATE(created_at) AS date, COUNT(*) AS count
 		Count int64
 	}
 	var trs []trendRow
-	if err := s.db.Raw(trendSQL, from, to).Scan(&trs).Error; err != nil {
+	if err := s.db.Raw(trendSQL, from, to, excluded).Scan(&trs).Error; err != nil {
 		return nil, err
 	}
 	resp.DailyTrend = make([]openapi.AdminStatsGet200ResponseDailyTrendInner, 0, len(trs))
@@ -150,6 +188,7 @@ SELECT DATE(created_at) AS date, COUNT(*) AS count
 	}
 
 	// ---- 管理员工作量 ----
+	// 之后被撤回的工单不计入处理量
 	type wlRow struct {
 		AdminID        int32
 		Name           string
@@ -160,11 +199,13 @@ SELECT DATE(created_at) AS date, COUNT(*) AS count
   SELECT u.id AS admin_id, COALESCE(u.name, '') AS name, COUNT(*) AS tickets_handled
     FROM audit_logs al
     JOIN users u ON u.id = al.actor_user_id
+    JOIN tickets t ON t.id = al.entity_id AND al.entity = 'TICKET'
    WHERE al.action IN ('ticket.resolve','ticket.close')
+     AND t.status <> ?
      AND al.created_at >= ? AND al.created_at < ?
    GROUP BY u.id, u.name
    ORDER BY tickets_handled DESC`
-	if err := s.db.Raw(wlSQL, from, to).Scan(&wls).Error; err != nil {
+	if err := s.db.Raw(wlSQL, dbpkg.TicketStatusCancelled, from, to).Scan(&wls).Error; err != nil {
 		return nil, err
 	}
 	resp.AdminWorkload = make([]openapi.AdminStatsGet200ResponseAdminWorkloadInner, 0, len(wls))
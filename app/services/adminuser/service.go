@@ -1,14 +1,19 @@
 package adminuser
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
+	"student-services-platform-backend/internal/audit"
 	dbpkg "student-services-platform-backend/internal/db"
 	"student-services-platform-backend/internal/openapi"
+	"student-services-platform-backend/internal/pagination"
 )
 
 type Service struct {
@@ -19,22 +24,61 @@ func NewService(db *gorm.DB) *Service {
 	return &Service{db: db}
 }
 
-// ListUsers retrieves users with pagination and optional role filtering
-func (s *Service) ListUsers(page, pageSize int, role *openapi.Role) (*openapi.PagedUsers, error) {
+// ListUsers retrieves users with pagination (page/page_size or cursor) and optional role filtering
+func (s *Service) ListUsers(pg pagination.Params, role *openapi.Role) (*openapi.PagedUsers, error) {
 	var dbRole *dbpkg.Role
 	if role != nil {
 		r := dbpkg.Role(*role)
 		dbRole = &r
 	}
 
-	users, total, err := dbpkg.ListUsers(s.db, page, pageSize, dbRole)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list users: %w", err)
+	pg = pg.Normalize()
+	out := &openapi.PagedUsers{PageSize: int32(pg.PageSize)}
+	var users []dbpkg.User
+
+	if pg.UseCursor {
+		var afterCreatedAt *time.Time
+		var afterID uint
+		if pg.Cursor != "" {
+			c, err := pagination.Decode(pg.Cursor, "created_at:desc", 2)
+			if err != nil {
+				return nil, err
+			}
+			ts, err := pagination.ParseTime(c.Values[0])
+			if err != nil {
+				return nil, err
+			}
+			if afterID, err = pagination.ParseUint(c.Values[1]); err != nil {
+				return nil, err
+			}
+			afterCreatedAt = &ts
+		}
+		rows, err := dbpkg.ListUsersKeyset(s.db, dbRole, afterCreatedAt, afterID, pg.PageSize+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
+		if len(rows) > pg.PageSize {
+			rows = rows[:pg.PageSize]
+			last := rows[len(rows)-1]
+			out.NextCursor = pagination.Encode(pagination.Cursor{
+				Sort:   "created_at:desc",
+				Values: []string{pagination.FormatTime(last.CreatedAt), pagination.FormatUint(last.ID)},
+			})
+		}
+		users = rows
+	} else {
+		rows, total, err := dbpkg.ListUsers(s.db, pg.Page, pg.PageSize, dbRole)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
+		users = rows
+		out.Page = int32(pg.Page)
+		out.Total = int32(total)
 	}
 
-	items := make([]openapi.User, len(users))
+	out.Items = make([]openapi.User, len(users))
 	for i, user := range users {
-		items[i] = openapi.User{
+		out.Items[i] = openapi.User{
 			Id:         int32(user.ID),
 			Email:      user.Email,
 			Name:       user.Name,
@@ -48,12 +92,7 @@ func (s *Service) ListUsers(page, pageSize int, role *openapi.Role) (*openapi.Pa
 		}
 	}
 
-	return &openapi.PagedUsers{
-		Items:    items,
-		Page:     int32(page),
-		PageSize: int32(pageSize),
-		Total:    int32(total),
-	}, nil
+	return out, nil
 }
 
 // GetUserByID retrieves a user by ID
@@ -82,8 +121,11 @@ func (s *Service) GetUserByID(id string) (*openapi.User, error) {
 	}, nil
 }
 
-// CreateUser creates a new user with password hashing
-func (s *Service) CreateUser(req openapi.UserCreate) (*openapi.User, error) {
+// auditEntity is the audit log entity for user accounts
+const auditEntity = "USER"
+
+// CreateUser creates a new user with password hashing; actorUID is the admin performing the action
+func (s *Service) CreateUser(ctx context.Context, actorUID uint, req openapi.UserCreate) (*openapi.User, error) {
 	// Check if email is already taken
 	if _, err := dbpkg.GetUserByEmail(s.db, req.Email); err == nil {
 		return nil, &ErrEmailTaken{Email: req.Email}
@@ -107,8 +149,14 @@ func (s *Service) CreateUser(req openapi.UserCreate) (*openapi.User, error) {
 		PasswordHash: string(hash),
 	}
 
-	if err := dbpkg.CreateUser(s.db, user); err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := dbpkg.CreateUser(tx, user); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		return audit.Record(ctx, tx, actorUID, "user.create", auditEntity, user.ID, userSnapshot(user))
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &openapi.User{
@@ -125,8 +173,8 @@ func (s *Service) CreateUser(req openapi.UserCreate) (*openapi.User, error) {
 	}, nil
 }
 
-// UpdateUser updates a user by ID
-func (s *Service) UpdateUser(id string, req openapi.UserAdminUpdate) (*openapi.User, error) {
+// UpdateUser updates a user by ID; the audit log records the changed fields
+func (s *Service) UpdateUser(ctx context.Context, actorUID uint, id string, req openapi.UserAdminUpdate) (*openapi.User, error) {
 	idUint, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
 		return nil, fmt.Errorf("invalid user ID: %w", err)
@@ -137,6 +185,7 @@ func (s *Service) UpdateUser(id string, req openapi.UserAdminUpdate) (*openapi.U
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
+	before := userSnapshot(user)
 
 	// Check email uniqueness if email is being changed
 	if req.Email != user.Email {
@@ -169,8 +218,18 @@ func (s *Service) UpdateUser(id string, req openapi.UserAdminUpdate) (*openapi.U
 	user.IsActive = req.IsActive
 	user.AllowEmail = req.AllowEmail
 
-	if err := dbpkg.UpdateUser(s.db, user); err != nil {
-		return nil, fmt.Errorf("failed to update user: %w", err)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := dbpkg.UpdateUser(tx, user); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		changes := audit.Changes(before, userSnapshot(user))
+		if len(changes) == 0 {
+			return nil
+		}
+		return audit.Record(ctx, tx, actorUID, "user.update", auditEntity, user.ID, changes)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &openapi.User{
@@ -187,18 +246,46 @@ func (s *Service) UpdateUser(id string, req openapi.UserAdminUpdate) (*openapi.U
 	}, nil
 }
 
-// DeleteUser deletes a user by ID
-func (s *Service) DeleteUser(id string) error {
+// DeleteUser deletes a user by ID; the audit log keeps a snapshot of the deleted account
+func (s *Service) DeleteUser(ctx context.Context, actorUID uint, id string) error {
 	idUint, err := strconv.ParseUint(id, 10, 32)
 	if err != nil {
 		return fmt.Errorf("invalid user ID: %w", err)
 	}
 
-	if err := dbpkg.DeleteUser(s.db, uint(idUint)); err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		user, err := dbpkg.GetUserByID(tx, uint(idUint))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // already gone: nothing to delete or audit
+		}
+		if err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		if err := dbpkg.DeleteUser(tx, user.ID); err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		return audit.Record(ctx, tx, actorUID, "user.delete", auditEntity, user.ID, userSnapshot(user))
+	})
+}
+
+// userSnapshot returns the audited fields of a user (never the password hash)
+func userSnapshot(u *dbpkg.User) map[string]interface{} {
+	return map[string]interface{}{
+		"email":       u.Email,
+		"name":        u.Name,
+		"role":        string(u.Role),
+		"phone":       derefString(u.Phone),
+		"dept":        derefString(u.Dept),
+		"is_active":   u.IsActive,
+		"allow_email": u.AllowEmail,
 	}
+}
 
-	return nil
+func derefString(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
 }
 
 // Helper function to return nil for empty strings
@@ -0,0 +1,181 @@
+package auditlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+)
+
+// verifyBatchSize 校验哈希链时每批读取的行数
+const verifyBatchSize = 1000
+
+// 锚点核对发现的问题类型
+const (
+	breakAnchorMissing  = "anchor_missing"  // 锚点记录的审计日志已不存在
+	breakAnchorMismatch = "anchor_mismatch" // 审计日志的哈希与锚定时不一致（整条链被重算）
+)
+
+// Anchor 锚定文件中的一行：某一时刻的链头
+type Anchor struct {
+	AnchoredAt time.Time `json:"anchored_at"`
+	LastID     uint      `json:"last_id"`
+	Hash       string    `json:"hash"`
+}
+
+// AnchoringEnabled 是否配置了锚定文件
+func (s *Service) AnchoringEnabled() bool { return s.anchorFile != "" }
+
+// AnchorHead 将当前链头追加到锚定文件；链为空或链头自上次锚定后未变化时不写入，返回 nil
+func (s *Service) AnchorHead(ctx context.Context) (*Anchor, error) {
+	if !s.AnchoringEnabled() {
+		return nil, nil
+	}
+	head, err := dbpkg.GetAuditChainHead(s.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if head.LastID == 0 {
+		return nil, nil
+	}
+	anchors, err := readAnchors(s.anchorFile)
+	if err != nil {
+		return nil, err
+	}
+	if n := len(anchors); n > 0 && anchors[n-1].LastID == head.LastID && anchors[n-1].Hash == head.Hash {
+		return nil, nil
+	}
+
+	a := &Anchor{AnchoredAt: time.Now().UTC(), LastID: head.LastID, Hash: head.Hash}
+	line, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.anchorFile), 0o750); err != nil {
+		return nil, fmt.Errorf("创建锚定目录失败: %w", err)
+	}
+	f, err := os.OpenFile(s.anchorFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("打开锚定文件失败: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("写入锚定文件失败: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return nil, fmt.Errorf("写入锚定文件失败: %w", err)
+	}
+	return a, nil
+}
+
+// Rekey 将哈希链从 oldKey 换算到当前配置的密钥，返回换算的记录数。
+// 原锚定文件中的链头按旧密钥计算、换算后无法再核对，因此改名归档并以新链头重新开始锚定
+func (s *Service) Rekey(ctx context.Context, oldKey string) (int64, error) {
+	n, err := dbpkg.RekeyAuditChain(s.db.WithContext(ctx), oldKey)
+	if err != nil || n == 0 || !s.AnchoringEnabled() {
+		return n, err
+	}
+	archived := s.anchorFile + ".pre-rekey-" + time.Now().UTC().Format("20060102T150405Z")
+	if err := os.Rename(s.anchorFile, archived); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return n, fmt.Errorf("归档锚定文件失败: %w", err)
+	}
+	if _, err := s.AnchorHead(ctx); err != nil {
+		return n, err
+	}
+	return n, nil
+}
+
+// readAnchors 读取锚定文件；文件不存在视为没有锚点，无法解析的行跳过
+func readAnchors(path string) ([]Anchor, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开锚定文件失败: %w", err)
+	}
+	defer f.Close()
+
+	var out []Anchor
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var a Anchor
+		if err := json.Unmarshal(sc.Bytes(), &a); err != nil || a.LastID == 0 {
+			continue
+		}
+		out = append(out, a)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("读取锚定文件失败: %w", err)
+	}
+	return out, nil
+}
+
+// Verify 遍历审计日志哈希链并核对锚定文件中的历史链头，报告所有断点
+func (s *Service) Verify(ctx context.Context) (*openapi.AuditChainVerification, error) {
+	d := s.db.WithContext(ctx)
+	rep, err := dbpkg.VerifyAuditChain(d, verifyBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	out := &openapi.AuditChainVerification{
+		Checked:    rep.Checked,
+		Unchained:  rep.Unchained,
+		LastId:     int32(rep.LastID),
+		LastHash:   rep.LastHash,
+		BreakCount: int32(rep.BreakCount),
+		Breaks:     make([]openapi.AuditChainBreak, 0, len(rep.Breaks)),
+		VerifiedAt: time.Now().UTC(),
+	}
+	for _, b := range rep.Breaks {
+		out.Breaks = append(out.Breaks, openapi.AuditChainBreak{Id: int32(b.ID), Kind: b.Kind, Expected: b.Expected, Actual: b.Actual})
+	}
+
+	if s.AnchoringEnabled() {
+		anchors, err := readAnchors(s.anchorFile)
+		if err != nil {
+			return nil, err
+		}
+		hashes := make(map[uint]string, len(anchors))
+		for i := 0; i < len(anchors); i += verifyBatchSize {
+			end := min(i+verifyBatchSize, len(anchors))
+			ids := make([]uint, 0, end-i)
+			for _, a := range anchors[i:end] {
+				ids = append(ids, a.LastID)
+			}
+			m, err := dbpkg.GetAuditLogHashes(d, ids)
+			if err != nil {
+				return nil, err
+			}
+			for id, h := range m {
+				hashes[id] = h
+			}
+		}
+		for _, a := range anchors {
+			b := openapi.AuditChainBreak{Id: int32(a.LastID), Expected: a.Hash}
+			h, ok := hashes[a.LastID]
+			switch {
+			case !ok:
+				b.Kind = breakAnchorMissing
+			case h != a.Hash:
+				b.Kind, b.Actual = breakAnchorMismatch, h
+			default:
+				continue
+			}
+			out.BreakCount++
+			if len(out.Breaks) < dbpkg.AuditBreakLimit {
+				out.Breaks = append(out.Breaks, b)
+			}
+		}
+		out.AnchorsChecked = int32(len(anchors))
+	}
+	out.Ok = out.BreakCount == 0
+	return out, nil
+}
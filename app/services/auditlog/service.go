@@ -0,0 +1,413 @@
+package auditlog
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+	"student-services-platform-backend/internal/pagination"
+
+	"gorm.io/gorm"
+)
+
+// exportBatchSize 导出时每批读取的行数
+const exportBatchSize = 500
+
+// 导出格式
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// Service 审计日志查询、导出与哈希链校验（仅超级管理员）
+type Service struct {
+	db *gorm.DB
+	// anchorFile 链头锚定文件路径；为空表示禁用锚定
+	anchorFile string
+}
+
+func NewService(db *gorm.DB, anchorFile string) *Service {
+	return &Service{db: db, anchorFile: anchorFile}
+}
+
+// ---- 错误类型 ----
+
+type ErrValidation struct {
+	Message string
+	Details map[string]interface{}
+}
+
+func (e *ErrValidation) Error() string { return e.Message }
+
+// Filters 审计日志筛选条件（所有字段可选）
+type Filters struct {
+	ActorID   *uint
+	Actions   []string // 精确匹配；以 ".*" 结尾的按前缀匹配，例如 ticket.*
+	Entity    string
+	EntityID  *uint
+	RequestID string
+	From      *time.Time
+	To        *time.Time
+}
+
+// toRepoFilter 校验并规范化筛选条件
+func (f Filters) toRepoFilter() (dbpkg.AuditLogFilter, error) {
+	details := map[string]interface{}{}
+	out := dbpkg.AuditLogFilter{
+		ActorID:   f.ActorID,
+		Entity:    strings.ToUpper(strings.TrimSpace(f.Entity)),
+		EntityID:  f.EntityID,
+		RequestID: strings.TrimSpace(f.RequestID),
+		From:      f.From,
+		To:        f.To,
+	}
+	if len(out.RequestID) > 64 {
+		details["request_id"] = "长度不能超过 64"
+	}
+	for _, a := range f.Actions {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == "" {
+			continue
+		}
+		if len(a) > 100 {
+			details["action"] = "长度不能超过 100"
+			break
+		}
+		out.Actions = append(out.Actions, a)
+	}
+	if f.From != nil && f.To != nil && f.To.Before(*f.From) {
+		details["to"] = "不能早于 from"
+	}
+	if len(details) > 0 {
+		return out, &ErrValidation{Message: "字段校验失败", Details: details}
+	}
+	return out, nil
+}
+
+// List 查询审计日志（最新的在前），支持 page/page_size 与游标分页
+func (s *Service) List(f Filters, pg pagination.Params) (*openapi.PagedAuditLogs, error) {
+	rf, err := f.toRepoFilter()
+	if err != nil {
+		return nil, err
+	}
+
+	pg = pg.Normalize()
+	if pg.PageSize > 100 {
+		pg.PageSize = 100
+	}
+	out := &openapi.PagedAuditLogs{PageSize: int32(pg.PageSize)}
+	var rows []dbpkg.AuditLog
+
+	if pg.UseCursor {
+		// 审计日志 ID 单调递增，直接以 ID 作为 keyset
+		var beforeID uint
+		if pg.Cursor != "" {
+			c, err := pagination.Decode(pg.Cursor, "id:desc", 1)
+			if err != nil {
+				return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"cursor": "无效的游标"}}
+			}
+			if beforeID, err = pagination.ParseUint(c.Values[0]); err != nil {
+				return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"cursor": "无效的游标"}}
+			}
+		}
+		if rows, err = dbpkg.ListAuditLogsBefore(s.db, rf, beforeID, pg.PageSize+1); err != nil {
+			return nil, err
+		}
+		if len(rows) > pg.PageSize {
+			rows = rows[:pg.PageSize]
+			last := rows[len(rows)-1].ID
+			out.NextCursor = pagination.Encode(pagination.Cursor{Sort: "id:desc", Values: []string{pagination.FormatUint(last)}})
+		}
+	} else {
+		var total int64
+		if rows, total, err = dbpkg.ListAuditLogs(s.db, rf, (pg.Page-1)*pg.PageSize, pg.PageSize); err != nil {
+			return nil, err
+		}
+		out.Page = int32(pg.Page)
+		out.Total = int32(total)
+	}
+
+	if out.Items, err = s.toAPIEntries(rows); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Export 审计日志导出；筛选条件与格式在开始写出前校验
+type Export struct {
+	s      *Service
+	filter dbpkg.AuditLogFilter
+	format string
+}
+
+// NewExport 校验导出参数；format 为 csv 或 jsonl
+func (s *Service) NewExport(f Filters, format string) (*Export, error) {
+	format = strings.ToLower(strings.TrimSpace(format))
+	if format == "" {
+		format = FormatCSV
+	}
+	if format != FormatCSV && format != FormatJSONL {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"format": "取值必须为 csv/jsonl"}}
+	}
+	rf, err := f.toRepoFilter()
+	if err != nil {
+		return nil, err
+	}
+	return &Export{s: s, filter: rf, format: format}, nil
+}
+
+// ContentType 响应的 Content-Type
+func (e *Export) ContentType() string {
+	if e.format == FormatJSONL {
+		return "application/x-ndjson; charset=utf-8"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// FileName 下载文件名
+func (e *Export) FileName(now time.Time) string {
+	return "audit-logs-" + now.UTC().Format("20060102T150405Z") + "." + e.format
+}
+
+// Stream 按 ID 升序分批写出全部匹配的审计日志，每批写完即刷新；
+// 导出动作本身也记入审计日志（actorID 为导出人）
+func (e *Export) Stream(ctx context.Context, actorID uint, w io.Writer) (int, error) {
+	if err := audit.Record(ctx, e.s.db, actorID, "audit_log.export", "AUDIT_LOG", 0, map[string]interface{}{
+		"format":  e.format,
+		"filters": filterDiff(e.filter),
+	}); err != nil {
+		return 0, err
+	}
+
+	bw := bufio.NewWriter(w)
+	var cw *csv.Writer
+	if e.format == FormatCSV {
+		// BOM 便于 Excel 正确识别 UTF-8 中文
+		if _, err := bw.WriteString("\ufeff"); err != nil {
+			return 0, err
+		}
+		cw = csv.NewWriter(bw)
+		if err := cw.Write([]string{"id", "created_at", "actor_user_id", "actor_name", "actor_handle", "action", "entity", "entity_id", "ip", "request_id", "summary", "diff"}); err != nil {
+			return 0, err
+		}
+	}
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+
+	n := 0
+	var afterID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+		rows, err := dbpkg.ListAuditLogsAfter(e.s.db.WithContext(ctx), e.filter, afterID, exportBatchSize)
+		if err != nil {
+			return n, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		entries, err := e.s.toAPIEntries(rows)
+		if err != nil {
+			return n, err
+		}
+		for i, it := range entries {
+			if cw != nil {
+				err = cw.Write([]string{
+					strconv.Itoa(int(it.Id)),
+					it.CreatedAt.UTC().Format(time.RFC3339Nano),
+					strconv.Itoa(int(it.ActorUserId)),
+					it.ActorName,
+					it.ActorHandle,
+					it.Action,
+					it.Entity,
+					strconv.Itoa(int(it.EntityId)),
+					it.Ip,
+					it.RequestId,
+					it.Summary,
+					string(rows[i].Diff),
+				})
+			} else {
+				err = enc.Encode(it)
+			}
+			if err != nil {
+				return n, err
+			}
+		}
+		n += len(rows)
+		afterID = rows[len(rows)-1].ID
+		if cw != nil {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return n, err
+			}
+		}
+		if err := bw.Flush(); err != nil {
+			return n, err
+		}
+		if f, ok := w.(interface{ Flush() }); ok {
+			f.Flush()
+		}
+	}
+	if cw != nil {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return n, err
+		}
+	}
+	return n, bw.Flush()
+}
+
+// filterDiff 审计记录中的导出筛选条件（只记录已设置的项）
+func filterDiff(f dbpkg.AuditLogFilter) map[string]interface{} {
+	out := map[string]interface{}{}
+	if f.ActorID != nil {
+		out["actor_id"] = *f.ActorID
+	}
+	if len(f.Actions) > 0 {
+		out["actions"] = f.Actions
+	}
+	if f.Entity != "" {
+		out["entity"] = f.Entity
+	}
+	if f.EntityID != nil {
+		out["entity_id"] = *f.EntityID
+	}
+	if f.RequestID != "" {
+		out["request_id"] = f.RequestID
+	}
+	if f.From != nil {
+		out["from"] = f.From.UTC()
+	}
+	if f.To != nil {
+		out["to"] = f.To.UTC()
+	}
+	return out
+}
+
+// toAPIEntries 组装输出：补充操作人姓名，并渲染可读的变更明细。
+// 创建者在其匿名工单上的操作隐藏操作人、IP 与请求 ID，以工单代号代替（与工单详情一致，超管需通过身份揭示查看）
+func (s *Service) toAPIEntries(rows []dbpkg.AuditLog) ([]openapi.AuditLogEntry, error) {
+	anonymous, err := dbpkg.AnonymousAuditRows(s.db, rows)
+	if err != nil {
+		return nil, err
+	}
+	actorIDs := make([]uint, 0, len(rows))
+	seen := map[uint]bool{}
+	for _, r := range rows {
+		if _, ok := anonymous[r.ID]; ok {
+			continue
+		}
+		if !seen[r.ActorUserID] {
+			seen[r.ActorUserID] = true
+			actorIDs = append(actorIDs, r.ActorUserID)
+		}
+	}
+	names := map[uint]string{}
+	if len(actorIDs) > 0 {
+		var users []dbpkg.User
+		if err := s.db.Select("id", "name").Where("id IN ?", actorIDs).Find(&users).Error; err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			names[u.ID] = u.Name
+		}
+	}
+
+	out := make([]openapi.AuditLogEntry, 0, len(rows))
+	for _, r := range rows {
+		item := openapi.AuditLogEntry{
+			Id:          int32(r.ID),
+			ActorUserId: int32(r.ActorUserID),
+			ActorName:   names[r.ActorUserID],
+			Action:      r.Action,
+			Entity:      r.Entity,
+			EntityId:    int32(r.EntityID),
+			Ip:          r.IP,
+			RequestId:   r.RequestID,
+			CreatedAt:   r.CreatedAt,
+		}
+		if ticketID, ok := anonymous[r.ID]; ok {
+			item.ActorUserId, item.ActorName = 0, ""
+			item.ActorHandle = dbpkg.AnonymousHandle(ticketID)
+			item.Ip, item.RequestId = "", ""
+		}
+		if len(r.Diff) > 0 {
+			var diff map[string]interface{}
+			if err := json.Unmarshal(r.Diff, &diff); err == nil {
+				item.Diff = diff
+				item.Changes = renderChanges(diff)
+				item.Summary = summarize(item.Changes)
+			}
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// renderChanges 将 diff 展开为按字段名排序的变更明细；形如 {"from": .., "to": ..} 的字段拆为前后值
+func renderChanges(diff map[string]interface{}) []openapi.AuditLogChange {
+	keys := make([]string, 0, len(diff))
+	for k := range diff {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]openapi.AuditLogChange, 0, len(keys))
+	for _, k := range keys {
+		if m, ok := diff[k].(map[string]interface{}); ok {
+			from, hasFrom := m["from"]
+			to, hasTo := m["to"]
+			if (hasFrom || hasTo) && len(m) <= 2 {
+				out = append(out, openapi.AuditLogChange{Field: k, From: from, To: to})
+				continue
+			}
+		}
+		out = append(out, openapi.AuditLogChange{Field: k, Value: diff[k]})
+	}
+	return out
+}
+
+// summarize 生成一行可读摘要，例如 "priority: P3 → P1；reason: 安全隐患"
+func summarize(changes []openapi.AuditLogChange) string {
+	parts := make([]string, 0, len(changes))
+	for _, c := range changes {
+		if c.Value != nil || (c.From == nil && c.To == nil) {
+			parts = append(parts, c.Field+": "+formatValue(c.Value))
+			continue
+		}
+		parts = append(parts, c.Field+": "+formatValue(c.From)+" → "+formatValue(c.To))
+	}
+	return strings.Join(parts, "；")
+}
+
+// formatValue 摘要中的取值：字符串原样输出，其余按 JSON 输出；过长的截断
+func formatValue(v interface{}) string {
+	var s string
+	switch x := v.(type) {
+	case nil:
+		s = "∅"
+	case string:
+		s = x
+	default:
+		b, err := json.Marshal(x)
+		if err != nil {
+			s = fmt.Sprint(x)
+		} else {
+			s = string(b)
+		}
+	}
+	if r := []rune(s); len(r) > 80 {
+		s = string(r[:80]) + "…"
+	}
+	return s
+}
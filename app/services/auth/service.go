@@ -1,11 +1,14 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
 	"strconv"
 	"time"
 
+	"student-services-platform-backend/internal/audit"
 	dbpkg "student-services-platform-backend/internal/db"
 	"student-services-platform-backend/internal/openapi"
 
@@ -44,7 +47,7 @@ type ErrEmailTaken struct{ Email string }
 func (e *ErrEmailTaken) Error() string { return fmt.Sprintf("邮箱已被占用: %s", e.Email) }
 
 // Register creates a user with a bcrypt hash.
-func (s *Service) Register(req openapi.UserCreate) (*openapi.User, error) {
+func (s *Service) Register(ctx context.Context, req openapi.UserCreate) (*openapi.User, error) {
 	// Uniqueness check (DB also enforces unique index)
 	if _, err := dbpkg.GetUserByEmail(s.db, req.Email); err == nil {
 		return nil, &ErrEmailTaken{Email: req.Email}
@@ -68,9 +71,19 @@ func (s *Service) Register(req openapi.UserCreate) (*openapi.User, error) {
 		PasswordHash: string(hash),
 	}
 
-	if err := dbpkg.CreateUser(s.db, u); err != nil {
-		// likely unique conflict race
-		return nil, fmt.Errorf("创建用户失败: %w", err)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := dbpkg.CreateUser(tx, u); err != nil {
+			// likely unique conflict race
+			return fmt.Errorf("创建用户失败: %w", err)
+		}
+		return audit.Record(ctx, tx, u.ID, "auth.register", "USER", u.ID, map[string]interface{}{
+			"email": u.Email,
+			"name":  u.Name,
+			"role":  string(u.Role),
+		})
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &openapi.User{
@@ -94,16 +107,19 @@ func nilIfEmpty(s string) *string {
 	return &s
 }
 
-func (s *Service) Login(email, password string) (*openapi.AuthLoginPost200Response, error) {
+// Login 校验邮箱密码并签发访问令牌；成功与失败的登录都会记录审计日志（写入失败只打日志，不影响登录结果）
+func (s *Service) Login(ctx context.Context, email, password string) (*openapi.AuthLoginPost200Response, error) {
 	u, err := dbpkg.GetUserByEmail(s.db, email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.auditLoginFailed(ctx, 0, email, "user_not_found")
 			return nil, &ErrUserNotFound{Email: email}
 		}
 		return nil, fmt.Errorf("查询用户失败: %w", err)
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
+		s.auditLoginFailed(ctx, u.ID, email, "invalid_password")
 		return nil, &ErrInvalidPassword{Email: email}
 	}
 
@@ -111,6 +127,9 @@ func (s *Service) Login(email, password string) (*openapi.AuthLoginPost200Respon
 	if err != nil {
 		return nil, &ErrGenerateToken{Message: err.Error()}
 	}
+	if err := audit.Record(ctx, s.db, u.ID, "auth.login", "USER", u.ID, map[string]interface{}{"email": u.Email}); err != nil {
+		log.Printf("auth: 记录登录审计日志失败: %v", err)
+	}
 
 	return &openapi.AuthLoginPost200Response{
 		AccessToken: tokenResp.AccessToken,
@@ -119,6 +138,18 @@ func (s *Service) Login(email, password string) (*openapi.AuthLoginPost200Respon
 	}, nil
 }
 
+// auditLoginFailed 记录失败的登录（操作人未知，记为 0）；此类记录不入哈希链、不争抢链头锁。
+// 写入失败只打日志，不影响返回给调用方的错误
+func (s *Service) auditLoginFailed(ctx context.Context, userID uint, email, reason string) {
+	err := audit.Record(ctx, s.db, 0, "auth.login_failed", "USER", userID, map[string]interface{}{
+		"email":  email,
+		"reason": reason,
+	})
+	if err != nil {
+		log.Printf("auth: 记录登录失败审计日志失败: %v", err)
+	}
+}
+
 func (s *Service) generateAccessToken(u *dbpkg.User) (*struct {
 	AccessToken string        `json:"access_token"`
 	ExpiresIn   time.Duration `json:"expires_in"`
@@ -0,0 +1,365 @@
+package availability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+
+	"gorm.io/gorm"
+)
+
+// Service 管理员在岗状态、班次与外出
+type Service struct {
+	db  *gorm.DB
+	loc *time.Location // 值班时区
+}
+
+func NewService(db *gorm.DB, loc *time.Location) *Service {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &Service{db: db, loc: loc}
+}
+
+// ---- 错误类型 ----
+
+type ErrValidation struct {
+	Message string
+	Details map[string]interface{}
+}
+
+func (e *ErrValidation) Error() string { return e.Message }
+
+type ErrNotFound struct{ Resource string }
+
+func (e *ErrNotFound) Error() string { return "not found: " + e.Resource }
+
+// maxShiftsPerAdmin 单个管理员最多可配置的班次数量
+const maxShiftsPerAdmin = 42
+
+// 审计日志的实体类型：在岗状态与班次以管理员用户 ID 为实体 ID，外出登记以其自身 ID 为实体 ID
+const (
+	auditEntityAvailability = "ADMIN_AVAILABILITY"
+	auditEntityOutOfOffice  = "ADMIN_OUT_OF_OFFICE"
+)
+
+// Get 返回管理员当前状态、班次以及当前/未来的外出安排
+func (s *Service) Get(uid uint) (*openapi.AdminAvailability, error) {
+	u, err := dbpkg.GetUserByID(s.db, uid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &ErrNotFound{Resource: "user"}
+		}
+		return nil, err
+	}
+	return s.build(u, time.Now().In(s.loc))
+}
+
+// List 列出所有在职管理员的在岗情况（供管理员查看谁在值班）
+func (s *Service) List() (*openapi.AvailabilityGet200Response, error) {
+	var admins []dbpkg.User
+	if err := s.db.
+		Where("role IN ? AND is_active = ?", []dbpkg.Role{dbpkg.RoleAdmin, dbpkg.RoleSuperAdmin}, true).
+		Order("id ASC").
+		Find(&admins).Error; err != nil {
+		return nil, err
+	}
+	now := time.Now().In(s.loc)
+	items := make([]openapi.AdminAvailability, 0, len(admins))
+	for i := range admins {
+		av, err := s.build(&admins[i], now)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *av)
+	}
+	return &openapi.AvailabilityGet200Response{Items: items}, nil
+}
+
+// UpdateStatus 设置手动在岗状态
+func (s *Service) UpdateStatus(ctx context.Context, uid uint, in openapi.UsersMeAvailabilityPutRequest) (*openapi.AdminAvailability, error) {
+	status := dbpkg.AvailabilityStatus(strings.ToUpper(strings.TrimSpace(string(in.Status))))
+	switch status {
+	case dbpkg.AvailabilityOnline, dbpkg.AvailabilityAway, dbpkg.AvailabilityOffDuty:
+	default:
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"status": "必须为 ONLINE、AWAY 或 OFF_DUTY"}}
+	}
+	note := strings.TrimSpace(in.Note)
+	if len([]rune(note)) > 255 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"note": "长度不能超过 255"}}
+	}
+
+	av := &dbpkg.AdminAvailability{
+		UserID:    uid,
+		Status:    status,
+		Note:      note,
+		UpdatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		old, err := dbpkg.GetAdminAvailability(tx, uid)
+		if err != nil {
+			return err
+		}
+		if err := tx.Save(av).Error; err != nil {
+			return err
+		}
+		changes := audit.Changes(
+			map[string]interface{}{"status": string(old.Status), "note": old.Note},
+			map[string]interface{}{"status": string(av.Status), "note": av.Note},
+		)
+		if len(changes) == 0 {
+			return nil
+		}
+		return audit.Record(ctx, tx, uid, "availability.status", auditEntityAvailability, uid, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(uid)
+}
+
+// ReplaceShifts 用新的班次集合整体替换管理员的班次
+func (s *Service) ReplaceShifts(ctx context.Context, uid uint, shifts []openapi.AdminShift) (*openapi.AdminAvailability, error) {
+	if len(shifts) > maxShiftsPerAdmin {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"shifts": fmt.Sprintf("最多 %d 个班次", maxShiftsPerAdmin)}}
+	}
+
+	rows := make([]dbpkg.AdminShift, 0, len(shifts))
+	details := map[string]interface{}{}
+	for i, in := range shifts {
+		key := fmt.Sprintf("shifts[%d]", i)
+		if in.Weekday < 0 || in.Weekday > 6 {
+			details[key] = "weekday 须在 0-6 之间"
+			continue
+		}
+		start, ok1 := parseClock(in.Start)
+		end, ok2 := parseClock(in.End)
+		if !ok1 || !ok2 || start >= 24*60 {
+			details[key] = "start/end 须为 HH:MM 格式"
+			continue
+		}
+		if start == end {
+			details[key] = "start 与 end 不能相同"
+			continue
+		}
+		rows = append(rows, dbpkg.AdminShift{
+			AdminUserID: uid,
+			Weekday:     int(in.Weekday),
+			StartMinute: start,
+			EndMinute:   end,
+		})
+	}
+	if len(details) > 0 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: details}
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		old, err := dbpkg.ListAdminShifts(tx, uid)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("admin_user_id = ?", uid).Delete(&dbpkg.AdminShift{}).Error; err != nil {
+			return err
+		}
+		if len(rows) > 0 {
+			if err := tx.Create(&rows).Error; err != nil {
+				return err
+			}
+		}
+		changes := audit.Changes(
+			map[string]interface{}{"shifts": shiftLabels(old)},
+			map[string]interface{}{"shifts": shiftLabels(rows)},
+		)
+		if len(changes) == 0 {
+			return nil
+		}
+		return audit.Record(ctx, tx, uid, "availability.shifts", auditEntityAvailability, uid, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(uid)
+}
+
+// CreateOutOfOffice 登记一段外出（可指定代理人）
+func (s *Service) CreateOutOfOffice(ctx context.Context, uid uint, in openapi.AdminOutOfOfficeCreate) (*openapi.AdminOutOfOffice, error) {
+	details := map[string]interface{}{}
+	if in.StartAt.IsZero() {
+		details["start_at"] = "必填"
+	}
+	if in.EndAt.IsZero() {
+		details["end_at"] = "必填"
+	} else if !in.EndAt.After(in.StartAt) {
+		details["end_at"] = "必须晚于 start_at"
+	} else if in.EndAt.Before(time.Now()) {
+		details["end_at"] = "不能早于当前时间"
+	}
+	reason := strings.TrimSpace(in.Reason)
+	if len([]rune(reason)) > 255 {
+		details["reason"] = "长度不能超过 255"
+	}
+
+	var delegateID *uint
+	if in.DelegateUserId != nil {
+		did := uint(*in.DelegateUserId)
+		if did == uid {
+			details["delegate_user_id"] = "不能指定自己为代理人"
+		} else if ok, err := dbpkg.IsAdminOrSuperAdmin(s.db, did); err != nil {
+			return nil, err
+		} else if !ok {
+			details["delegate_user_id"] = "代理人必须是管理员"
+		} else {
+			delegateID = &did
+		}
+	}
+	if len(details) > 0 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: details}
+	}
+
+	ooo := &dbpkg.AdminOutOfOffice{
+		AdminUserID:    uid,
+		StartAt:        in.StartAt.UTC().Truncate(time.Microsecond),
+		EndAt:          in.EndAt.UTC().Truncate(time.Microsecond),
+		DelegateUserID: delegateID,
+		Reason:         reason,
+		CreatedAt:      time.Now().UTC().Truncate(time.Microsecond),
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(ooo).Error; err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, uid, "availability.out_of_office_create", auditEntityOutOfOffice, ooo.ID, outOfOfficeSnapshot(ooo))
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := toAPIOutOfOffice(*ooo)
+	return &out, nil
+}
+
+// DeleteOutOfOffice 取消本人的外出登记
+func (s *Service) DeleteOutOfOffice(ctx context.Context, uid, id uint) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ooo dbpkg.AdminOutOfOffice
+		if err := tx.Where("id = ? AND admin_user_id = ?", id, uid).First(&ooo).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &ErrNotFound{Resource: "out_of_office"}
+			}
+			return err
+		}
+		res := tx.Where("id = ? AND admin_user_id = ?", id, uid).Delete(&dbpkg.AdminOutOfOffice{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return &ErrNotFound{Resource: "out_of_office"}
+		}
+		return audit.Record(ctx, tx, uid, "availability.out_of_office_delete", auditEntityOutOfOffice, ooo.ID, outOfOfficeSnapshot(&ooo))
+	})
+}
+
+// outOfOfficeSnapshot 外出登记的审计字段
+func outOfOfficeSnapshot(o *dbpkg.AdminOutOfOffice) map[string]interface{} {
+	return map[string]interface{}{
+		"start_at":         o.StartAt,
+		"end_at":           o.EndAt,
+		"delegate_user_id": o.DelegateUserID,
+		"reason":           o.Reason,
+	}
+}
+
+// shiftLabels 将班次格式化为便于阅读的 "周几 HH:MM-HH:MM"（0 表示周日）
+func shiftLabels(rows []dbpkg.AdminShift) []string {
+	out := make([]string, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, fmt.Sprintf("%d %s-%s", r.Weekday, formatClock(r.StartMinute), formatClock(r.EndMinute)))
+	}
+	return out
+}
+
+// build 组装单个管理员的在岗视图
+func (s *Service) build(u *dbpkg.User, now time.Time) (*openapi.AdminAvailability, error) {
+	av, err := dbpkg.GetAdminAvailability(s.db, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := dbpkg.ListAdminShifts(s.db, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	var ooos []dbpkg.AdminOutOfOffice
+	if err := s.db.Where("admin_user_id = ? AND end_at > ?", u.ID, now.UTC()).
+		Order("start_at ASC").
+		Find(&ooos).Error; err != nil {
+		return nil, err
+	}
+	onDuty, err := dbpkg.IsAdminOnDuty(s.db, u.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &openapi.AdminAvailability{
+		UserId:      int32(u.ID),
+		Name:        u.Name,
+		Status:      openapi.AvailabilityStatus(av.Status),
+		Note:        av.Note,
+		OnDuty:      onDuty,
+		Shifts:      make([]openapi.AdminShift, 0, len(shifts)),
+		OutOfOffice: make([]openapi.AdminOutOfOffice, 0, len(ooos)),
+	}
+	if !av.UpdatedAt.IsZero() {
+		t := av.UpdatedAt
+		out.UpdatedAt = &t
+	}
+	for _, sh := range shifts {
+		out.Shifts = append(out.Shifts, openapi.AdminShift{
+			Id:      int32(sh.ID),
+			Weekday: int32(sh.Weekday),
+			Start:   formatClock(sh.StartMinute),
+			End:     formatClock(sh.EndMinute),
+		})
+	}
+	for _, o := range ooos {
+		out.OutOfOffice = append(out.OutOfOffice, toAPIOutOfOffice(o))
+	}
+	return out, nil
+}
+
+func toAPIOutOfOffice(o dbpkg.AdminOutOfOffice) openapi.AdminOutOfOffice {
+	var delegate *int32
+	if o.DelegateUserID != nil {
+		v := int32(*o.DelegateUserID)
+		delegate = &v
+	}
+	return openapi.AdminOutOfOffice{
+		Id:             int32(o.ID),
+		AdminUserId:    int32(o.AdminUserID),
+		StartAt:        o.StartAt,
+		EndAt:          o.EndAt,
+		DelegateUserId: delegate,
+		Reason:         o.Reason,
+		CreatedAt:      o.CreatedAt,
+	}
+}
+
+// parseClock 解析 HH:MM（允许 24:00 表示当天结束）为分钟数
+func parseClock(s string) (int, bool) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		if strings.TrimSpace(s) == "24:00" {
+			return 24 * 60, true
+		}
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+func formatClock(m int) string {
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
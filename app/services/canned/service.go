@@ -1,10 +1,12 @@
 package canned
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
 
+	"student-services-platform-backend/internal/audit"
 	dbpkg "student-services-platform-backend/internal/db"
 	"student-services-platform-backend/internal/openapi"
 
@@ -17,6 +19,9 @@ type Service struct {
 
 func NewService(db *gorm.DB) *Service { return &Service{db: db} }
 
+// auditEntity 常用回复审计日志的实体类型
+const auditEntity = "CANNED_REPLY"
+
 func (s *Service) List(currentUID uint, page, pageSize int) (*openapi.PagedCannedReplies, error) {
 	if page < 1 {
 		page = 1
@@ -73,7 +78,7 @@ func validate(title, body string) error {
 	return nil
 }
 
-func (s *Service) Create(currentUID uint, in openapi.CannedReplyCreate) (*openapi.CannedReply, error) {
+func (s *Service) Create(ctx context.Context, currentUID uint, in openapi.CannedReplyCreate) (*openapi.CannedReply, error) {
 	if err := validate(in.Title, in.Body); err != nil {
 		return nil, err
 	}
@@ -82,7 +87,16 @@ func (s *Service) Create(currentUID uint, in openapi.CannedReplyCreate) (*openap
 		Title:       strings.TrimSpace(in.Title),
 		Body:        strings.TrimSpace(in.Body),
 	}
-	if err := s.db.Create(cr).Error; err != nil {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(cr).Error; err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, currentUID, "canned_reply.create", auditEntity, cr.ID, map[string]interface{}{
+			"title": cr.Title,
+			"body":  cr.Body,
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
 	
@@ -120,7 +134,7 @@ func (s *Service) canModify(currentUID uint, cr *dbpkg.CannedReply) (bool, error
 	return false, fmt.Errorf("无权限")
 }
 
-func (s *Service) Update(currentUID, id uint, in openapi.CannedReplyUpdate) (*openapi.CannedReply, error) {
+func (s *Service) Update(ctx context.Context, currentUID, id uint, in openapi.CannedReplyUpdate) (*openapi.CannedReply, error) {
 	var cr dbpkg.CannedReply
 	if err := s.db.First(&cr, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -131,6 +145,7 @@ func (s *Service) Update(currentUID, id uint, in openapi.CannedReplyUpdate) (*op
 	if ok, err := s.canModify(currentUID, &cr); !ok {
 		return nil, err
 	}
+	before := map[string]interface{}{"title": cr.Title, "body": cr.Body}
 	// 只更新提供的字段
 	if in.Title != "" {
 		if err := validate(in.Title, cr.Body); err != nil {
@@ -144,7 +159,17 @@ func (s *Service) Update(currentUID, id uint, in openapi.CannedReplyUpdate) (*op
 		}
 		cr.Body = strings.TrimSpace(in.Body)
 	}
-	if err := s.db.Save(&cr).Error; err != nil {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&cr).Error; err != nil {
+			return err
+		}
+		changes := audit.Changes(before, map[string]interface{}{"title": cr.Title, "body": cr.Body})
+		if len(changes) == 0 {
+			return nil
+		}
+		return audit.Record(ctx, tx, currentUID, "canned_reply.update", auditEntity, cr.ID, changes)
+	})
+	if err != nil {
 		return nil, err
 	}
 	
@@ -164,7 +189,7 @@ func (s *Service) Update(currentUID, id uint, in openapi.CannedReplyUpdate) (*op
 	return out, nil
 }
 
-func (s *Service) Delete(currentUID, id uint) error {
+func (s *Service) Delete(ctx context.Context, currentUID, id uint) error {
 	var cr dbpkg.CannedReply
 	if err := s.db.First(&cr, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -175,5 +200,14 @@ func (s *Service) Delete(currentUID, id uint) error {
 	if ok, err := s.canModify(currentUID, &cr); !ok {
 		return err
 	}
-	return s.db.Delete(&dbpkg.CannedReply{}, id).Error
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&dbpkg.CannedReply{}, id).Error; err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, currentUID, "canned_reply.delete", auditEntity, id, map[string]interface{}{
+			"owner_id": cr.AdminUserID,
+			"title":    cr.Title,
+			"body":     cr.Body,
+		})
+	})
 }
\ No newline at end of file
@@ -0,0 +1,237 @@
+package category
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	maxFieldsPerCategory = 30
+	maxFieldLabelLen     = 100
+	maxFieldOptions      = 100
+	maxFieldTextLen      = 4000
+)
+
+var validFieldTypes = map[openapi.CategoryFieldType]bool{
+	openapi.TEXT:   true,
+	openapi.NUMBER: true,
+	openapi.SELECT: true,
+	openapi.DATE:   true,
+}
+
+// ReplaceFields 以给定列表整体替换分类的自定义字段（按顺序）。
+// 同 key 的字段原地更新（已归档的会恢复）；列表中未出现的字段归档而非删除，工单上的历史值仍可查看。
+func (s *Service) ReplaceFields(ctx context.Context, actorUID, id uint, in []openapi.CategoryField) (*openapi.CategoryDetail, error) {
+	fields, err := validateFields(in)
+	if err != nil {
+		return nil, err
+	}
+
+	var out *openapi.CategoryDetail
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var c dbpkg.Category
+		if err := loadCategory(tx, id, &c); err != nil {
+			return err
+		}
+		var existing []dbpkg.CategoryField
+		if err := tx.Where(&dbpkg.CategoryField{CategoryID: id}).Find(&existing).Error; err != nil {
+			return err
+		}
+		byKey := make(map[string]dbpkg.CategoryField, len(existing))
+		for _, f := range existing {
+			byKey[f.Key] = f
+		}
+
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		keep := make(map[string]bool, len(fields))
+		var added, archived []string
+		for i, f := range fields {
+			f.CategoryID = id
+			f.Position = i
+			f.UpdatedAt = now
+			keep[f.Key] = true
+			old, ok := byKey[f.Key]
+			if !ok {
+				f.CreatedAt = now
+				if err := tx.Create(&f).Error; err != nil {
+					return err
+				}
+				added = append(added, f.Key)
+				continue
+			}
+			if err := tx.Model(&dbpkg.CategoryField{}).Where("id = ?", old.ID).Updates(map[string]interface{}{
+				"label":       f.Label,
+				"type":        f.Type,
+				"required":    f.Required,
+				"options":     f.Options,
+				"max_length":  f.MaxLength,
+				"min":         f.Min,
+				"max":         f.Max,
+				"position":    f.Position,
+				"is_archived": false,
+				"updated_at":  now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		for _, f := range existing {
+			if keep[f.Key] || f.IsArchived {
+				continue
+			}
+			if err := tx.Model(&dbpkg.CategoryField{}).Where("id = ?", f.ID).
+				Updates(map[string]interface{}{"is_archived": true, "updated_at": now}).Error; err != nil {
+				return err
+			}
+			archived = append(archived, f.Key)
+		}
+
+		keys := make([]string, 0, len(fields))
+		for _, f := range fields {
+			keys = append(keys, f.Key)
+		}
+		if err := audit.Record(ctx, tx, actorUID, "category.fields", auditEntity, id, map[string]interface{}{
+			"fields": keys, "added": added, "archived": archived,
+		}); err != nil {
+			return err
+		}
+		var err error
+		out, err = s.detail(tx, c)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// validateFields 校验字段定义并转换为数据库结构；错误以 "fields[i].<attr>" 为键
+func validateFields(in []openapi.CategoryField) ([]dbpkg.CategoryField, error) {
+	details := map[string]interface{}{}
+	if len(in) > maxFieldsPerCategory {
+		details["fields"] = fmt.Sprintf("最多 %d 个字段", maxFieldsPerCategory)
+		return nil, &ErrValidation{Message: "字段校验失败", Details: details}
+	}
+
+	seen := make(map[string]bool, len(in))
+	out := make([]dbpkg.CategoryField, 0, len(in))
+	for i, f := range in {
+		prefix := fmt.Sprintf("fields[%d].", i)
+		key := strings.TrimSpace(f.Key)
+		label := strings.TrimSpace(f.Label)
+		switch {
+		case !dbpkg.ValidCategoryFieldKey(key):
+			details[prefix+"key"] = "须以小写字母开头，仅含小写字母、数字和下划线，长度不超过 50"
+		case seen[key]:
+			details[prefix+"key"] = "字段键重复"
+		}
+		seen[key] = true
+		switch {
+		case label == "":
+			details[prefix+"label"] = "必填"
+		case len([]rune(label)) > maxFieldLabelLen:
+			details[prefix+"label"] = fmt.Sprintf("长度不能超过 %d", maxFieldLabelLen)
+		}
+		if !validFieldTypes[f.Type] {
+			details[prefix+"type"] = "无效的字段类型"
+			continue
+		}
+
+		row := dbpkg.CategoryField{
+			Key:      key,
+			Label:    label,
+			Type:     dbpkg.CategoryFieldType(f.Type),
+			Required: f.Required,
+		}
+		switch f.Type {
+		case openapi.SELECT:
+			opts, msg := normalizeOptions(f.Options)
+			if msg != "" {
+				details[prefix+"options"] = msg
+				continue
+			}
+			b, err := json.Marshal(opts)
+			if err != nil {
+				return nil, err
+			}
+			row.Options = datatypes.JSON(b)
+		case openapi.TEXT:
+			if f.MaxLength != nil {
+				if *f.MaxLength < 1 || *f.MaxLength > maxFieldTextLen {
+					details[prefix+"max_length"] = fmt.Sprintf("须在 1 到 %d 之间", maxFieldTextLen)
+					continue
+				}
+				n := int(*f.MaxLength)
+				row.MaxLength = &n
+			}
+		case openapi.NUMBER:
+			if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+				details[prefix+"max"] = "不能小于 min"
+				continue
+			}
+			row.Min, row.Max = f.Min, f.Max
+		}
+		out = append(out, row)
+	}
+	if len(details) > 0 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: details}
+	}
+	return out, nil
+}
+
+// normalizeOptions 去除首尾空白并校验 SELECT 可选值
+func normalizeOptions(in []string) ([]string, string) {
+	if len(in) == 0 {
+		return nil, "SELECT 类型至少需要一个可选值"
+	}
+	if len(in) > maxFieldOptions {
+		return nil, fmt.Sprintf("最多 %d 个可选值", maxFieldOptions)
+	}
+	seen := make(map[string]bool, len(in))
+	out := make([]string, 0, len(in))
+	for _, o := range in {
+		o = strings.TrimSpace(o)
+		if o == "" {
+			return nil, "可选值不能为空"
+		}
+		if seen[o] {
+			return nil, "可选值重复: " + o
+		}
+		seen[o] = true
+		out = append(out, o)
+	}
+	return out, ""
+}
+
+// toAPIFields 转换字段定义；解析失败的 SELECT 可选值视为空
+func toAPIFields(rows []dbpkg.CategoryField) []openapi.CategoryField {
+	out := make([]openapi.CategoryField, 0, len(rows))
+	for _, f := range rows {
+		opts, _ := dbpkg.CategoryFieldOptions(f)
+		var maxLen *int32
+		if f.MaxLength != nil {
+			n := int32(*f.MaxLength)
+			maxLen = &n
+		}
+		out = append(out, openapi.CategoryField{
+			Key:       f.Key,
+			Label:     f.Label,
+			Type:      openapi.CategoryFieldType(f.Type),
+			Required:  f.Required,
+			Options:   opts,
+			MaxLength: maxLen,
+			Min:       f.Min,
+			Max:       f.Max,
+		})
+	}
+	return out
+}
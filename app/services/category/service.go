@@ -0,0 +1,505 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+
+	"gorm.io/gorm"
+)
+
+// Service 工单分类目录（公开查询；增删改与合并仅限超级管理员）
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service { return &Service{db: db} }
+
+// ---- 错误类型 ----
+
+type ErrValidation struct {
+	Message string
+	Details map[string]interface{}
+}
+
+func (e *ErrValidation) Error() string { return e.Message }
+
+type ErrNotFound struct{ Resource string }
+
+func (e *ErrNotFound) Error() string { return "not found: " + e.Resource }
+
+type ErrConflict struct{ Message string }
+
+func (e *ErrConflict) Error() string { return "conflict: " + e.Message }
+
+// ListActive 提单表单使用：仅返回启用的分类（父分类停用时其子分类一并隐藏）
+func (s *Service) ListActive() (*openapi.CategoriesGet200Response, error) {
+	var rows []dbpkg.Category
+	if err := s.db.Where("is_active = ?", true).
+		Order("sort_order ASC, name ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	active := make(map[uint]bool, len(rows))
+	ids := make([]uint, 0, len(rows))
+	for _, c := range rows {
+		active[c.ID] = true
+		ids = append(ids, c.ID)
+	}
+	fields, err := dbpkg.ListCategoryFields(s.db, ids, false)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]openapi.Category, 0, len(rows))
+	for _, c := range rows {
+		if c.ParentID != nil && !active[*c.ParentID] {
+			continue
+		}
+		items = append(items, openapi.Category{
+			Id:          int32(c.ID),
+			Name:        c.Name,
+			Description: c.Description,
+			ParentId:    toPtrInt32(c.ParentID),
+			SortOrder:   int32(c.SortOrder),
+			Fields:      toAPIFields(fields[c.ID]),
+		})
+	}
+	return &openapi.CategoriesGet200Response{Items: items}, nil
+}
+
+// ListAll 超级管理员查看完整目录（含停用分类、别名、默认处理人与工单数）
+func (s *Service) ListAll() (*openapi.AdminCategoriesGet200Response, error) {
+	var rows []dbpkg.Category
+	if err := s.db.Order("sort_order ASC, name ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	items := make([]openapi.CategoryDetail, 0, len(rows))
+	for _, c := range rows {
+		out, err := s.detail(s.db, c)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *out)
+	}
+	return &openapi.AdminCategoriesGet200Response{Items: items}, nil
+}
+
+// Create 新建分类
+func (s *Service) Create(ctx context.Context, actorUID uint, in openapi.CategoryInput) (*openapi.CategoryDetail, error) {
+	var out *openapi.CategoryDetail
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		v, err := validate(tx, 0, in)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		c := &dbpkg.Category{CreatedAt: now}
+		v.apply(c, now)
+		if err := tx.Create(c).Error; err != nil {
+			return err
+		}
+		if err := saveRelations(tx, c.ID, v.aliases, v.assignees, now); err != nil {
+			return err
+		}
+		if err := audit.Record(ctx, tx, actorUID, "category.create", auditEntity, c.ID, map[string]interface{}{"name": c.Name}); err != nil {
+			return err
+		}
+		out, err = s.detail(tx, *c)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Update 修改分类；改名时同步工单上的分类名称，并把旧名称保留为别名
+func (s *Service) Update(ctx context.Context, actorUID, id uint, in openapi.CategoryInput) (*openapi.CategoryDetail, error) {
+	var out *openapi.CategoryDetail
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var c dbpkg.Category
+		if err := loadCategory(tx, id, &c); err != nil {
+			return err
+		}
+		v, err := validate(tx, id, in)
+		if err != nil {
+			return err
+		}
+		if v.parentID != nil {
+			var n int64
+			if err := tx.Model(&dbpkg.Category{}).Where("parent_id = ?", id).Count(&n).Error; err != nil {
+				return err
+			}
+			if n > 0 {
+				return &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"parent_id": "该分类已有子分类，不能再设置父分类"}}
+			}
+		}
+
+		diff := map[string]interface{}{}
+		if c.Name != v.name {
+			diff["name"] = map[string]interface{}{"from": c.Name, "to": v.name}
+			if key := dbpkg.CategoryKey(c.Name); key != dbpkg.CategoryKey(v.name) {
+				v.aliases = appendUnique(v.aliases, key)
+			}
+		}
+		if c.IsActive != v.isActive {
+			diff["is_active"] = map[string]interface{}{"from": c.IsActive, "to": v.isActive}
+		}
+
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		oldName := c.Name
+		v.apply(&c, now)
+		if err := tx.Model(&dbpkg.Category{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"name":        c.Name,
+			"description": c.Description,
+			"parent_id":   c.ParentID,
+			"is_active":   c.IsActive,
+			"sort_order":  c.SortOrder,
+			"department":  c.Department,
+			"updated_at":  c.UpdatedAt,
+		}).Error; err != nil {
+			return err
+		}
+		if oldName != c.Name {
+			if err := tx.Model(&dbpkg.Ticket{}).Where("category_id = ?", id).
+				UpdateColumn("category", c.Name).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("category_id = ?", id).Delete(&dbpkg.CategoryAlias{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("category_id = ?", id).Delete(&dbpkg.CategoryAssignee{}).Error; err != nil {
+			return err
+		}
+		if err := saveRelations(tx, id, v.aliases, v.assignees, now); err != nil {
+			return err
+		}
+		if err := audit.Record(ctx, tx, actorUID, "category.update", auditEntity, id, diff); err != nil {
+			return err
+		}
+		out, err = s.detail(tx, c)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Delete 删除分类；仍有工单或子分类使用时拒绝（应改为停用或合并）
+func (s *Service) Delete(ctx context.Context, actorUID, id uint) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var c dbpkg.Category
+		if err := loadCategory(tx, id, &c); err != nil {
+			return err
+		}
+		var n int64
+		if err := tx.Model(&dbpkg.Category{}).Where("parent_id = ?", id).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return &ErrConflict{Message: "该分类下仍有子分类"}
+		}
+		if err := tx.Model(&dbpkg.Ticket{}).Where("category_id = ?", id).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return &ErrConflict{Message: "仍有工单使用该分类，请停用或合并到其他分类"}
+		}
+		if err := tx.Where("category_id = ?", id).Delete(&dbpkg.CategoryField{}).Error; err != nil {
+			return err
+		}
+		if err := deleteCategory(tx, id); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, actorUID, "category.delete", auditEntity, id, map[string]interface{}{"name": c.Name})
+	})
+}
+
+// Merge 将分类 sourceID 合并到 targetID：工单改挂目标分类，源名称与别名成为目标的别名，源分类删除
+func (s *Service) Merge(ctx context.Context, actorUID, sourceID, targetID uint) (*openapi.CategoryDetail, error) {
+	if sourceID == targetID {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"target_id": "不能合并到自身"}}
+	}
+
+	var out *openapi.CategoryDetail
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var src, dst dbpkg.Category
+		if err := loadCategory(tx, sourceID, &src); err != nil {
+			return err
+		}
+		if err := loadCategory(tx, targetID, &dst); err != nil {
+			return err
+		}
+		var n int64
+		if err := tx.Model(&dbpkg.Category{}).Where("parent_id = ?", sourceID).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return &ErrConflict{Message: "源分类下仍有子分类，请先移动子分类"}
+		}
+
+		moved := tx.Model(&dbpkg.Ticket{}).Where("category_id = ?", sourceID).
+			UpdateColumns(map[string]interface{}{"category_id": targetID, "category": dst.Name})
+		if moved.Error != nil {
+			return moved.Error
+		}
+
+		var aliases []string
+		if err := tx.Model(&dbpkg.CategoryAlias{}).Where("category_id = ?", sourceID).
+			Pluck("alias", &aliases).Error; err != nil {
+			return err
+		}
+		aliases = appendUnique(aliases, dbpkg.CategoryKey(src.Name))
+		// 源分类的字段定义归档保留，迁移过来的工单仍可查看原有字段值
+		if err := tx.Model(&dbpkg.CategoryField{}).Where("category_id = ?", sourceID).
+			Update("is_archived", true).Error; err != nil {
+			return err
+		}
+		if err := deleteCategory(tx, sourceID); err != nil {
+			return err
+		}
+		dstKey := dbpkg.CategoryKey(dst.Name)
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		for _, a := range aliases {
+			if a == dstKey {
+				continue
+			}
+			if err := tx.Create(&dbpkg.CategoryAlias{Alias: a, CategoryID: targetID, CreatedAt: now}).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := audit.Record(ctx, tx, actorUID, "category.merge", auditEntity, targetID, map[string]interface{}{
+			"merged_from": src.Name, "merged_from_id": sourceID, "into": dst.Name, "tickets": moved.RowsAffected,
+		}); err != nil {
+			return err
+		}
+		var err error
+		out, err = s.detail(tx, dst)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ---- 内部辅助 ----
+
+// validated 校验并规范化后的分类输入
+type validated struct {
+	name        string
+	description string
+	parentID    *uint
+	isActive    bool
+	sortOrder   int
+	department  string
+	aliases     []string
+	assignees   []uint
+}
+
+func (v *validated) apply(c *dbpkg.Category, now time.Time) {
+	c.Name = v.name
+	c.Description = v.description
+	c.ParentID = v.parentID
+	c.IsActive = v.isActive
+	c.SortOrder = v.sortOrder
+	c.Department = v.department
+	c.UpdatedAt = now
+}
+
+// validate 校验分类输入；id 为 0 表示新建
+func validate(tx *gorm.DB, id uint, in openapi.CategoryInput) (*validated, error) {
+	details := map[string]interface{}{}
+	v := &validated{
+		name:        strings.TrimSpace(in.Name),
+		description: strings.TrimSpace(in.Description),
+		isActive:    in.IsActive == nil || *in.IsActive,
+		sortOrder:   int(in.SortOrder),
+		department:  strings.TrimSpace(in.Department),
+	}
+	if v.name == "" {
+		details["name"] = "必填"
+	} else if len([]rune(v.name)) > 100 {
+		details["name"] = "长度不能超过 100"
+	}
+	if len([]rune(v.description)) > 1000 {
+		details["description"] = "长度不能超过 1000"
+	}
+	if len([]rune(v.department)) > 100 {
+		details["department"] = "长度不能超过 100"
+	}
+
+	nameKey := dbpkg.CategoryKey(v.name)
+	for _, raw := range in.Aliases {
+		a := dbpkg.CategoryKey(raw)
+		switch {
+		case a == "" || a == nameKey:
+			continue
+		case len([]rune(a)) > 100:
+			details["aliases"] = "别名长度不能超过 100"
+		}
+		v.aliases = appendUnique(v.aliases, a)
+	}
+
+	if in.ParentId != nil && *in.ParentId > 0 {
+		pid := uint(*in.ParentId)
+		var p dbpkg.Category
+		switch err := tx.First(&p, pid).Error; {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			details["parent_id"] = "父分类不存在"
+		case err != nil:
+			return nil, err
+		case pid == id:
+			details["parent_id"] = "不能以自身为父分类"
+		case p.ParentID != nil:
+			details["parent_id"] = "仅支持一级父分类"
+		}
+		v.parentID = &pid
+	}
+
+	if ids := in.DefaultAssigneeIds; len(ids) > 0 {
+		seen := map[uint]bool{}
+		for _, id32 := range ids {
+			if id32 > 0 && !seen[uint(id32)] {
+				seen[uint(id32)] = true
+				v.assignees = append(v.assignees, uint(id32))
+			}
+		}
+		var n int64
+		if err := tx.Model(&dbpkg.User{}).
+			Where("id IN ? AND role IN ? AND is_active = ?", v.assignees, []dbpkg.Role{dbpkg.RoleAdmin, dbpkg.RoleSuperAdmin}, true).
+			Count(&n).Error; err != nil {
+			return nil, err
+		}
+		if int(n) != len(v.assignees) || len(v.assignees) != len(ids) {
+			details["default_assignee_ids"] = "必须为不重复的有效管理员"
+		}
+		sort.Slice(v.assignees, func(i, j int) bool { return v.assignees[i] < v.assignees[j] })
+	}
+
+	if len(details) > 0 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: details}
+	}
+
+	// 名称与别名在所有分类的名称与别名中唯一（不区分大小写）
+	keys := append([]string{nameKey}, v.aliases...)
+	var n int64
+	if err := tx.Model(&dbpkg.Category{}).Where("LOWER(name) IN ? AND id <> ?", keys, id).Count(&n).Error; err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		if err := tx.Model(&dbpkg.CategoryAlias{}).Where("alias IN ? AND category_id <> ?", keys, id).Count(&n).Error; err != nil {
+			return nil, err
+		}
+	}
+	if n > 0 {
+		return nil, &ErrConflict{Message: "分类名称或别名已被其他分类使用"}
+	}
+	return v, nil
+}
+
+func saveRelations(tx *gorm.DB, id uint, aliases []string, assignees []uint, now time.Time) error {
+	for _, a := range aliases {
+		if err := tx.Create(&dbpkg.CategoryAlias{Alias: a, CategoryID: id, CreatedAt: now}).Error; err != nil {
+			return err
+		}
+	}
+	for _, uid := range assignees {
+		if err := tx.Create(&dbpkg.CategoryAssignee{CategoryID: id, AdminUserID: uid, CreatedAt: now}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteCategory(tx *gorm.DB, id uint) error {
+	if err := tx.Where("category_id = ?", id).Delete(&dbpkg.CategoryAlias{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("category_id = ?", id).Delete(&dbpkg.CategoryAssignee{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&dbpkg.Category{}, id).Error
+}
+
+func loadCategory(tx *gorm.DB, id uint, c *dbpkg.Category) error {
+	if err := tx.First(c, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &ErrNotFound{Resource: "category"}
+		}
+		return err
+	}
+	return nil
+}
+
+// detail 组装管理端分类详情（别名、默认处理人、工单数、表单字段）
+func (s *Service) detail(d *gorm.DB, c dbpkg.Category) (*openapi.CategoryDetail, error) {
+	var aliases []string
+	if err := d.Model(&dbpkg.CategoryAlias{}).Where("category_id = ?", c.ID).
+		Order("alias ASC").Pluck("alias", &aliases).Error; err != nil {
+		return nil, err
+	}
+	assignees, err := dbpkg.ListCategoryAssigneeIDs(d, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	var n int64
+	if err := d.Model(&dbpkg.Ticket{}).Where("category_id = ?", c.ID).Count(&n).Error; err != nil {
+		return nil, err
+	}
+	fields, err := dbpkg.ListCategoryFields(d, []uint{c.ID}, false)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int32, 0, len(assignees))
+	for _, id := range assignees {
+		ids = append(ids, int32(id))
+	}
+	if aliases == nil {
+		aliases = []string{}
+	}
+	return &openapi.CategoryDetail{
+		Id:                 int32(c.ID),
+		Name:               c.Name,
+		Description:        c.Description,
+		ParentId:           toPtrInt32(c.ParentID),
+		SortOrder:          int32(c.SortOrder),
+		IsActive:           c.IsActive,
+		Department:         c.Department,
+		Aliases:            aliases,
+		DefaultAssigneeIds: ids,
+		TicketCount:        int32(n),
+		Fields:             toAPIFields(fields[c.ID]),
+		CreatedAt:          c.CreatedAt,
+		UpdatedAt:          c.UpdatedAt,
+	}, nil
+}
+
+// auditEntity 分类审计日志的实体类型
+const auditEntity = "CATEGORY"
+
+func appendUnique(list []string, v string) []string {
+	for _, x := range list {
+		if x == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
+
+func toPtrInt32(p *uint) *int32 {
+	if p == nil {
+		return nil
+	}
+	v := int32(*p)
+	return &v
+}
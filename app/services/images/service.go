@@ -2,6 +2,7 @@ package images
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -15,6 +16,7 @@ import (
 	"strconv"
 	"strings"
 
+	"student-services-platform-backend/internal/audit"
 	dbpkg "student-services-platform-backend/internal/db"
 	"student-services-platform-backend/internal/filestore"
 	"student-services-platform-backend/internal/openapi"
@@ -32,10 +34,16 @@ func NewService(db *gorm.DB, store filestore.Store) *Service {
 	return &Service{db: db, store: store}
 }
 
+// allowedMIMEs 允许上传的类型：图片，以及可作为消息附件的 PDF
 var allowedMIMEs = map[string]struct{}{
-	"image/jpeg": {},
-	"image/png":  {},
-	"image/webp": {},
+	"image/jpeg":      {},
+	"image/png":       {},
+	"image/webp":      {},
+	"application/pdf": {},
+}
+
+func isImageMime(m string) bool {
+	return strings.HasPrefix(strings.ToLower(m), "image/")
 }
 
 func isAllowed(m string) bool {
@@ -51,6 +59,8 @@ func extForMime(m string) string {
 		return ".png"
 	case "image/webp":
 		return ".webp"
+	case "application/pdf":
+		return ".pdf"
 	default:
 		// 尽力从 mime 包获取
 		if exts, _ := mime.ExtensionsByType(m); len(exts) > 0 {
@@ -60,7 +70,8 @@ func extForMime(m string) string {
 	}
 }
 
-func (s *Service) Upload(reader io.Reader) (*openapi.ImagesPost201Response, error) {
+// Upload 上传图片或附件；内容相同的文件复用已有记录。每次上传都记录审计日志（含是否复用）
+func (s *Service) Upload(ctx context.Context, uploaderUID uint, reader io.Reader) (*openapi.ImagesPost201Response, error) {
 	// 1) 读取前 512 字节用于内容嗅探
 	head := make([]byte, 512)
 	nHead, _ := io.ReadFull(reader, head)
@@ -71,7 +82,7 @@ func (s *Service) Upload(reader io.Reader) (*openapi.ImagesPost201Response, erro
 
 	ctype := http.DetectContentType(head)
 	if !isAllowed(ctype) {
-		return nil, fmt.Errorf("不支持的图片类型: %s", ctype)
+		return nil, fmt.Errorf("不支持的文件类型: %s", ctype)
 	}
 
 	// 2) 在计算哈希的同时，将数据流式写入临时文件
@@ -98,32 +109,29 @@ func (s *Service) Upload(reader io.Reader) (*openapi.ImagesPost201Response, erro
 	// 3) 如果文件已存在：复用并返回
 	if exists, _, _ := s.store.Exists(hashHex); exists {
 		if im, err := dbpkg.GetImageBySHA(s.db, hashHex); err == nil {
-			return &openapi.ImagesPost201Response{
-				ImageId: int32(im.ID),
-				Sha256:  im.Sha256,
-				Mime:    im.Mime,
-				Width:   int32(im.Width),
-				Height:  int32(im.Height),
-			}, nil
+			return s.uploaded(ctx, uploaderUID, im, true)
 		} // 否则继续重建数据库行
 	}
 
-	// 4) 解码配置以获取尺寸（验证文件确实可以解码）
-	if _, err := tmpFile.Seek(0, 0); err != nil {
-		return nil, fmt.Errorf("临时文件seek失败: %w", err)
-	}
-	var cfg image.Config
-	switch ctype {
-	case "image/webp":
-		// x/image/webp 只通过 webp 包暴露 DecodeConfig
-		cfg, err = webp.DecodeConfig(tmpFile)
-	default:
-		cfg, _, err = image.DecodeConfig(tmpFile)
-	}
-	if err != nil {
-		return nil, fmt.Errorf("图片解码失败（可能损坏或伪造）: %w", err)
+	// 4) 解码配置以获取尺寸（验证文件确实可以解码）；PDF 仅依赖内容嗅探，尺寸记为 0
+	var width, height int
+	if isImageMime(ctype) {
+		if _, err := tmpFile.Seek(0, 0); err != nil {
+			return nil, fmt.Errorf("临时文件seek失败: %w", err)
+		}
+		var cfg image.Config
+		switch ctype {
+		case "image/webp":
+			// x/image/webp 只通过 webp 包暴露 DecodeConfig
+			cfg, err = webp.DecodeConfig(tmpFile)
+		default:
+			cfg, _, err = image.DecodeConfig(tmpFile)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("图片解码失败（可能损坏或伪造）: %w", err)
+		}
+		width, height = cfg.Width, cfg.Height
 	}
-	width, height := cfg.Width, cfg.Height
 
 	// 5) 持久化到文件存储
 	objectKey, absPath, err := s.store.PutFromFile(hashHex, tmpFile.Name())
@@ -147,15 +155,29 @@ func (s *Service) Upload(reader io.Reader) (*openapi.ImagesPost201Response, erro
 		ObjectKey: objectKey,
 		RefCount:  1, // 逻辑上的初始引用计数
 	}
+	reused := false
 	if err := dbpkg.CreateImage(s.db, im); err != nil {
 		// 如果发生竞争（sha唯一），获取已存在的记录
 		if ex, er2 := dbpkg.GetImageBySHA(s.db, hashHex); er2 == nil {
-			im = ex
+			im, reused = ex, true
 		} else {
 			return nil, fmt.Errorf("写入数据库失败: %w", err)
 		}
 	}
+	return s.uploaded(ctx, uploaderUID, im, reused)
+}
 
+// uploaded 记录上传审计日志并返回上传结果
+func (s *Service) uploaded(ctx context.Context, uploaderUID uint, im *dbpkg.Image, reused bool) (*openapi.ImagesPost201Response, error) {
+	err := audit.Record(ctx, s.db, uploaderUID, "image.upload", "IMAGE", im.ID, map[string]interface{}{
+		"sha256": im.Sha256,
+		"mime":   im.Mime,
+		"size":   im.Size,
+		"reused": reused,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("记录审计日志失败: %w", err)
+	}
 	return &openapi.ImagesPost201Response{
 		ImageId: int32(im.ID),
 		Sha256:  im.Sha256,
@@ -203,7 +225,11 @@ func (s *Service) ResolveForDownload(requestUID, imageID uint) (f *os.File, size
 		return nil, 0, "", "", err
 	}
 
-	// 友好的内联文件名: image-<id>.<ext>
-	name = "image-" + strconv.FormatUint(uint64(im.ID), 10) + extForMime(im.Mime)
+	// 友好的内联文件名: image-<id>.<ext>（非图片附件为 file-<id>.<ext>）
+	prefix := "image-"
+	if !isImageMime(im.Mime) {
+		prefix = "file-"
+	}
+	name = prefix + strconv.FormatUint(uint64(im.ID), 10) + extForMime(im.Mime)
 	return file, stat.Size(), im.Mime, name, nil
 }
\ No newline at end of file
@@ -0,0 +1,354 @@
+package savedview
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	ticketsvc "student-services-platform-backend/app/services/ticket"
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// maxViewsPerAdmin 单个管理员最多保存的视图数量
+const maxViewsPerAdmin = 50
+
+// auditEntity 视图审计日志的实体类型
+const auditEntity = "SAVED_VIEW"
+
+// Service 管理员保存的工单队列视图
+type Service struct {
+	db      *gorm.DB
+	tickets *ticketsvc.Service // 复用工单筛选逻辑做校验与计数
+}
+
+func NewService(db *gorm.DB, tickets *ticketsvc.Service) *Service {
+	return &Service{db: db, tickets: tickets}
+}
+
+// ---- 错误类型 ----
+
+type ErrValidation struct {
+	Message string
+	Details map[string]interface{}
+}
+
+func (e *ErrValidation) Error() string { return e.Message }
+
+type ErrForbidden struct{ Reason string }
+
+func (e *ErrForbidden) Error() string { return "forbidden: " + e.Reason }
+
+type ErrNotFound struct{ Resource string }
+
+func (e *ErrNotFound) Error() string { return "not found: " + e.Resource }
+
+type ErrConflict struct{ Message string }
+
+func (e *ErrConflict) Error() string { return "conflict: " + e.Message }
+
+// List 返回本人的视图与其他管理员共享的视图，并附带当前管理员在每个视图下的工单数
+func (s *Service) List(currentUID uint) (*openapi.ViewsGet200Response, error) {
+	var rows []dbpkg.SavedView
+	if err := s.db.
+		Where("owner_user_id = ? OR is_shared = ?", currentUID, true).
+		Order("name ASC, id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	// 本人的视图排在共享视图之前
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].OwnerUserID == currentUID && rows[j].OwnerUserID != currentUID
+	})
+
+	names, err := s.ownerNames(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]openapi.SavedView, 0, len(rows))
+	for _, v := range rows {
+		out, err := toAPIView(v, names[v.OwnerUserID])
+		if err != nil {
+			return nil, err
+		}
+		n, err := s.tickets.CountTickets(currentUID, toListFilters(out.Filters))
+		if err == nil {
+			c := int32(n)
+			out.Count = &c
+		} else {
+			var ve *ticketsvc.ErrValidation
+			if !errors.As(err, &ve) {
+				return nil, err
+			}
+			// 历史数据不再合法时不返回计数，不影响其他视图
+		}
+		items = append(items, out)
+	}
+	return &openapi.ViewsGet200Response{Items: items}, nil
+}
+
+// Create 新建视图
+func (s *Service) Create(ctx context.Context, currentUID uint, in openapi.SavedViewCreate) (*openapi.SavedView, error) {
+	name, filters, err := s.validate(in)
+	if err != nil {
+		return nil, err
+	}
+
+	var n int64
+	if err := s.db.Model(&dbpkg.SavedView{}).Where("owner_user_id = ?", currentUID).Count(&n).Error; err != nil {
+		return nil, err
+	}
+	if n >= maxViewsPerAdmin {
+		return nil, &ErrValidation{Message: "视图数量已达上限", Details: map[string]interface{}{"limit": maxViewsPerAdmin}}
+	}
+	if err := s.ensureNameFree(currentUID, name, 0); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	v := &dbpkg.SavedView{
+		OwnerUserID: currentUID,
+		Name:        name,
+		Filters:     filters,
+		IsShared:    in.IsShared,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(v).Error; err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, currentUID, "saved_view.create", auditEntity, v.ID, viewSnapshot(v))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.get(v)
+}
+
+// Update 修改视图（仅创建者）
+func (s *Service) Update(ctx context.Context, currentUID, id uint, in openapi.SavedViewCreate) (*openapi.SavedView, error) {
+	v, err := s.loadOwned(currentUID, id)
+	if err != nil {
+		return nil, err
+	}
+	name, filters, err := s.validate(in)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureNameFree(currentUID, name, id); err != nil {
+		return nil, err
+	}
+
+	before := viewSnapshot(v)
+	v.Name = name
+	v.Filters = filters
+	v.IsShared = in.IsShared
+	v.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(v).Error; err != nil {
+			return err
+		}
+		changes := audit.Changes(before, viewSnapshot(v))
+		if len(changes) == 0 {
+			return nil
+		}
+		return audit.Record(ctx, tx, currentUID, "saved_view.update", auditEntity, v.ID, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.get(v)
+}
+
+// Delete 删除视图（仅创建者）
+func (s *Service) Delete(ctx context.Context, currentUID, id uint) error {
+	v, err := s.loadOwned(currentUID, id)
+	if err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(v).Error; err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, currentUID, "saved_view.delete", auditEntity, v.ID, viewSnapshot(v))
+	})
+}
+
+// viewSnapshot 视图的审计字段
+func viewSnapshot(v *dbpkg.SavedView) map[string]interface{} {
+	var filters json.RawMessage
+	if len(v.Filters) > 0 {
+		filters = json.RawMessage(v.Filters)
+	}
+	return map[string]interface{}{
+		"name":      v.Name,
+		"is_shared": v.IsShared,
+		"filters":   filters,
+	}
+}
+
+// Filters 返回视图的筛选条件（本人视图或共享视图），供按视图列出工单使用
+func (s *Service) Filters(currentUID, id uint) (ticketsvc.ListFilters, error) {
+	var v dbpkg.SavedView
+	if err := s.db.First(&v, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ticketsvc.ListFilters{}, &ErrNotFound{Resource: "view"}
+		}
+		return ticketsvc.ListFilters{}, err
+	}
+	if v.OwnerUserID != currentUID && !v.IsShared {
+		return ticketsvc.ListFilters{}, &ErrNotFound{Resource: "view"}
+	}
+	var f openapi.TicketViewFilters
+	if len(v.Filters) > 0 {
+		if err := json.Unmarshal(v.Filters, &f); err != nil {
+			return ticketsvc.ListFilters{}, err
+		}
+	}
+	return toListFilters(f), nil
+}
+
+// ---- 内部辅助 ----
+
+func (s *Service) validate(in openapi.SavedViewCreate) (string, datatypes.JSON, error) {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return "", nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"name": "必填"}}
+	}
+	if len([]rune(name)) > 100 {
+		return "", nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"name": "长度不能超过 100"}}
+	}
+	if err := s.tickets.ValidateListFilters(toListFilters(in.Filters)); err != nil {
+		var ve *ticketsvc.ErrValidation
+		if errors.As(err, &ve) {
+			return "", nil, &ErrValidation{Message: ve.Message, Details: map[string]interface{}{"filters": ve.Details}}
+		}
+		return "", nil, err
+	}
+	b, err := json.Marshal(in.Filters)
+	if err != nil {
+		return "", nil, err
+	}
+	return name, datatypes.JSON(b), nil
+}
+
+func (s *Service) ensureNameFree(ownerID uint, name string, excludeID uint) error {
+	var n int64
+	if err := s.db.Model(&dbpkg.SavedView{}).
+		Where("owner_user_id = ? AND name = ? AND id <> ?", ownerID, name, excludeID).
+		Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return &ErrConflict{Message: "已存在同名视图"}
+	}
+	return nil
+}
+
+func (s *Service) loadOwned(currentUID, id uint) (*dbpkg.SavedView, error) {
+	var v dbpkg.SavedView
+	if err := s.db.First(&v, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &ErrNotFound{Resource: "view"}
+		}
+		return nil, err
+	}
+	if v.OwnerUserID != currentUID {
+		if !v.IsShared {
+			return nil, &ErrNotFound{Resource: "view"}
+		}
+		return nil, &ErrForbidden{Reason: "只有创建者可以修改或删除视图"}
+	}
+	return &v, nil
+}
+
+func (s *Service) get(v *dbpkg.SavedView) (*openapi.SavedView, error) {
+	names, err := s.ownerNames([]dbpkg.SavedView{*v})
+	if err != nil {
+		return nil, err
+	}
+	out, err := toAPIView(*v, names[v.OwnerUserID])
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ownerNames 批量查询视图创建者姓名
+func (s *Service) ownerNames(rows []dbpkg.SavedView) (map[uint]string, error) {
+	ids := make([]uint, 0, len(rows))
+	for _, v := range rows {
+		ids = append(ids, v.OwnerUserID)
+	}
+	out := make(map[uint]string, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	var users []dbpkg.User
+	if err := s.db.Select("id", "name").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		out[u.ID] = u.Name
+	}
+	return out, nil
+}
+
+func toAPIView(v dbpkg.SavedView, ownerName string) (openapi.SavedView, error) {
+	var f openapi.TicketViewFilters
+	if len(v.Filters) > 0 {
+		if err := json.Unmarshal(v.Filters, &f); err != nil {
+			return openapi.SavedView{}, err
+		}
+	}
+	return openapi.SavedView{
+		Id:          int32(v.ID),
+		OwnerUserId: int32(v.OwnerUserID),
+		OwnerName:   ownerName,
+		Name:        v.Name,
+		Filters:     f,
+		IsShared:    v.IsShared,
+		CreatedAt:   v.CreatedAt,
+		UpdatedAt:   v.UpdatedAt,
+	}, nil
+}
+
+// toListFilters 将保存的筛选条件转换为工单服务的筛选结构
+func toListFilters(f openapi.TicketViewFilters) ticketsvc.ListFilters {
+	return ticketsvc.ListFilters{
+		Statuses:     f.Statuses,
+		Category:     strings.TrimSpace(f.Category),
+		IsUrgent:     f.IsUrgent,
+		Priorities:   f.Priorities,
+		AssignedToMe: f.AssignedToMe,
+		AssigneeID:   uintPtr(f.AssigneeId),
+		CreatorID:    uintPtr(f.CreatorId),
+		Unassigned:   f.Unassigned,
+		Tags:         f.Tags,
+		Keyword:      strings.TrimSpace(f.Keyword),
+		Fields:       f.Fields,
+		CreatedFrom:  f.CreatedFrom,
+		CreatedTo:    f.CreatedTo,
+		UpdatedFrom:  f.UpdatedFrom,
+		UpdatedTo:    f.UpdatedTo,
+		Sort:         f.Sort,
+		Order:        f.Order,
+	}
+}
+
+func uintPtr(p *int32) *uint {
+	if p == nil || *p <= 0 {
+		return nil
+	}
+	v := uint(*p)
+	return &v
+}
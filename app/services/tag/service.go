@@ -0,0 +1,217 @@
+package tag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Service 工单标签管理（查看：管理员；重命名/合并/删除：超级管理员）
+type Service struct {
+	db *gorm.DB
+}
+
+func NewService(db *gorm.DB) *Service { return &Service{db: db} }
+
+// ---- 错误类型 ----
+
+type ErrValidation struct {
+	Message string
+	Details map[string]interface{}
+}
+
+func (e *ErrValidation) Error() string { return e.Message }
+
+type ErrNotFound struct{ Resource string }
+
+func (e *ErrNotFound) Error() string { return "not found: " + e.Resource }
+
+type ErrConflict struct{ Message string }
+
+func (e *ErrConflict) Error() string { return "conflict: " + e.Message }
+
+// List 按名称列出全部标签及其使用次数；q 非空时按名称前缀过滤
+func (s *Service) List(q string) (*openapi.TagsGet200Response, error) {
+	type row struct {
+		dbpkg.Tag
+		TicketCount int64
+	}
+	query := s.db.Table("tags").
+		Select("tags.*, (SELECT COUNT(*) FROM ticket_tags tt WHERE tt.tag_id = tags.id) AS ticket_count")
+	if prefix := dbpkg.NormalizeTagName(q); prefix != "" {
+		query = query.Where("name LIKE ? ESCAPE '!'", escapeLike(prefix)+"%")
+	}
+	var rows []row
+	if err := query.Order("name ASC").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	items := make([]openapi.Tag, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, toAPITag(r.Tag, r.TicketCount))
+	}
+	return &openapi.TagsGet200Response{Items: items}, nil
+}
+
+// Rename 重命名标签；新名称已被其他标签占用时应改用合并
+func (s *Service) Rename(ctx context.Context, actorUID, id uint, name string) (*openapi.Tag, error) {
+	name, err := validateName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var t dbpkg.Tag
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := loadTag(tx, id, &t); err != nil {
+			return err
+		}
+		if t.Name == name {
+			return nil
+		}
+		var n int64
+		if err := tx.Model(&dbpkg.Tag{}).Where("name = ? AND id <> ?", name, id).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return &ErrConflict{Message: "标签名称已存在，请使用合并"}
+		}
+		old := t.Name
+		t.Name = name
+		t.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		if err := tx.Model(&dbpkg.Tag{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"name": t.Name, "updated_at": t.UpdatedAt}).Error; err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, actorUID, "tag.rename", auditEntity, id, map[string]interface{}{"from": old, "to": name})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.get(t)
+}
+
+// Merge 将标签 sourceID 合并到 targetID：工单改挂目标标签，源标签删除
+func (s *Service) Merge(ctx context.Context, actorUID, sourceID, targetID uint) (*openapi.Tag, error) {
+	if sourceID == targetID {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"target_id": "不能合并到自身"}}
+	}
+
+	var src, dst dbpkg.Tag
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := loadTag(tx, sourceID, &src); err != nil {
+			return err
+		}
+		if err := loadTag(tx, targetID, &dst); err != nil {
+			return err
+		}
+
+		var rels []dbpkg.TicketTag
+		if err := tx.Where("tag_id = ?", sourceID).Find(&rels).Error; err != nil {
+			return err
+		}
+		for i := range rels {
+			rels[i].TagID = targetID
+		}
+		if len(rels) > 0 {
+			// 已带目标标签的工单忽略
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rels, 500).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("tag_id = ?", sourceID).Delete(&dbpkg.TicketTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&dbpkg.Tag{}, sourceID).Error; err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, actorUID, "tag.merge", auditEntity, targetID, map[string]interface{}{
+			"merged_from": src.Name, "merged_from_id": sourceID, "into": dst.Name, "tickets": len(rels),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.get(dst)
+}
+
+// Delete 删除标签及其所有工单关联
+func (s *Service) Delete(ctx context.Context, actorUID, id uint) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var t dbpkg.Tag
+		if err := loadTag(tx, id, &t); err != nil {
+			return err
+		}
+		res := tx.Where("tag_id = ?", id).Delete(&dbpkg.TicketTag{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if err := tx.Delete(&dbpkg.Tag{}, id).Error; err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, actorUID, "tag.delete", auditEntity, id, map[string]interface{}{"name": t.Name, "tickets": res.RowsAffected})
+	})
+}
+
+// ---- 内部辅助 ----
+
+func validateName(raw string) (string, error) {
+	name := dbpkg.NormalizeTagName(raw)
+	var msg string
+	switch {
+	case name == "":
+		msg = "必填"
+	case len([]rune(name)) > dbpkg.MaxTagNameLen:
+		msg = fmt.Sprintf("长度不能超过 %d", dbpkg.MaxTagNameLen)
+	case strings.Contains(name, ","):
+		msg = "不能包含逗号"
+	}
+	if msg != "" {
+		return "", &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"name": msg}}
+	}
+	return name, nil
+}
+
+func loadTag(tx *gorm.DB, id uint, t *dbpkg.Tag) error {
+	if err := tx.First(t, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &ErrNotFound{Resource: "tag"}
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *Service) get(t dbpkg.Tag) (*openapi.Tag, error) {
+	var n int64
+	if err := s.db.Model(&dbpkg.TicketTag{}).Where("tag_id = ?", t.ID).Count(&n).Error; err != nil {
+		return nil, err
+	}
+	out := toAPITag(t, n)
+	return &out, nil
+}
+
+// auditEntity 标签审计日志的实体类型
+const auditEntity = "TAG"
+
+// escapeLike 转义 LIKE 通配符（配合 ESCAPE '!' 使用）
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+func toAPITag(t dbpkg.Tag, ticketCount int64) openapi.Tag {
+	return openapi.Tag{
+		Id:          int32(t.ID),
+		Name:        t.Name,
+		TicketCount: int32(ticketCount),
+		CreatedBy:   int32(t.CreatedBy),
+		CreatedAt:   t.CreatedAt,
+	}
+}
@@ -2,44 +2,31 @@ package ticket
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
+	"student-services-platform-backend/internal/audit"
 	dbpkg "student-services-platform-backend/internal/db"
 	"student-services-platform-backend/internal/openapi"
 
-	"gorm.io/datatypes"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
-// audit 记录审计日志
-func (s *Service) audit(ctx context.Context, tx *gorm.DB, actorID uint, action, entity string, entityID uint, diff map[string]interface{}) error {
-	var diffJSON datatypes.JSON
-	if diff != nil {
-		b, err := json.Marshal(diff)
-		if err != nil {
-			return fmt.Errorf("序列化diff失败: %w", err)
-		}
-		diffJSON = datatypes.JSON(b)
-	}
-	al := &dbpkg.AuditLog{
-		ActorUserID: actorID,
-		Action:      action,
-		Entity:      entity,
-		EntityID:    entityID,
-		Diff:        diffJSON,
-		CreatedAt:   time.Now().UTC().Truncate(time.Microsecond),
-	}
-	return tx.WithContext(ctx).Create(al).Error
-}
-
 // ClaimTicket 管理员接单（原子 CAS）
 func (s *Service) ClaimTicket(ctx context.Context, adminUID, ticketID uint) error {
+    // 不在岗的管理员不能成为负责人
+    reason, err := s.offDutyReason(s.db.WithContext(ctx), adminUID)
+    if err != nil {
+        return err
+    }
+    if reason != "" {
+        return &ErrInvalidState{Message: "当前不在岗（" + reason + "），无法认领工单"}
+    }
+
     // 先执行事务，拿到错误再决定后续动作
-    err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+    err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
         now := time.Now().UTC().Truncate(time.Microsecond)
         result := tx.Model(&dbpkg.Ticket{}).
             Where("id = ? AND status = ?", ticketID, dbpkg.TicketStatusNew).
@@ -70,7 +57,7 @@ func (s *Service) ClaimTicket(ctx context.Context, adminUID, ticketID uint) erro
         }
 
         diff := map[string]interface{}{"status_to": "CLAIMED", "assigned_admin_id": adminUID}
-        return s.audit(ctx, tx, adminUID, "ticket.claim", "TICKET", ticketID, diff)
+        return audit.Record(ctx, tx, adminUID, "ticket.claim", "TICKET", ticketID, diff)
     })
 
     // 事务失败，直接返回错误
@@ -98,7 +85,7 @@ func (s *Service) ClaimTicket(ctx context.Context, adminUID, ticketID uint) erro
 
             // 发送邮件通知
             s.notifier.NotifyTicketClaimed(
-                context.Background(),
+                notifyCtx(ticket.Priority),
                 ticketID,
                 ticket.Title,
                 handler.Name,
@@ -136,7 +123,7 @@ func (s *Service) UnclaimTicket(ctx context.Context, adminUID, ticketID uint) er
 			return &ErrInvalidState{Message: fmt.Sprintf("仅 'CLAIMED' 状态可撤销, 当前为 '%s'", cur.Status)}
 		}
 		diff := map[string]interface{}{"status_to": "NEW", "unassigned_admin_id": adminUID}
-		return s.audit(ctx, tx, adminUID, "ticket.unclaim", "TICKET", ticketID, diff)
+		return audit.Record(ctx, tx, adminUID, "ticket.unclaim", "TICKET", ticketID, diff)
 	})
 
 	// 事务失败，直接返回错误
@@ -160,7 +147,7 @@ func (s *Service) UnclaimTicket(ctx context.Context, adminUID, ticketID uint) er
 
 			// 发送邮件通知
 			s.notifier.NotifyTicketUnclaimed(
-				context.Background(),
+				notifyCtx(ticket.Priority),
 				ticketID,
 				ticket.Title,
 				creator.Email,
@@ -174,12 +161,17 @@ func (s *Service) UnclaimTicket(ctx context.Context, adminUID, ticketID uint) er
 // updateTicketStatusAsAdmin 通用的管理员状态更新函数
 func (s *Service) updateTicketStatusAsAdmin(ctx context.Context, adminUID, ticketID uint, newStatus dbpkg.TicketStatus, action string, allowedOldStatuses []dbpkg.TicketStatus) error {
 	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		updates := map[string]interface{}{
+			"status":     newStatus,
+			"updated_at": now,
+		}
+		if newStatus == dbpkg.TicketStatusResolved {
+			updates["resolved_at"] = now // 自动关闭以此为起点计时
+		}
 		result := tx.Model(&dbpkg.Ticket{}).
 			Where("id = ? AND assigned_admin_id = ? AND status IN ?", ticketID, adminUID, allowedOldStatuses).
-			Updates(map[string]interface{}{
-				"status":     newStatus,
-				"updated_at": time.Now().UTC().Truncate(time.Microsecond),
-			})
+			Updates(updates)
 		if result.Error != nil {
 			return result.Error
 		}
@@ -194,7 +186,7 @@ func (s *Service) updateTicketStatusAsAdmin(ctx context.Context, adminUID, ticke
 			return &ErrInvalidState{Message: fmt.Sprintf("工单当前状态 ('%s') 无法执行此操作", cur.Status)}
 		}
 		diff := map[string]interface{}{"status_to": string(newStatus)}
-		return s.audit(ctx, tx, adminUID, action, "TICKET", ticketID, diff)
+		return audit.Record(ctx, tx, adminUID, action, "TICKET", ticketID, diff)
 	})
 }
 
@@ -230,7 +222,7 @@ func (s *Service) ResolveTicket(ctx context.Context, adminUID, ticketID uint) er
 
 			// 发送邮件通知
 			s.notifier.NotifyTicketResolved(
-				context.Background(),
+				notifyCtx(ticket.Priority),
 				ticketID,
 				ticket.Title,
 				"您的工单已处理完成", // 默认处理结果消息
@@ -266,7 +258,7 @@ func (s *Service) CloseTicket(ctx context.Context, adminUID, ticketID uint) erro
             return err
         }
         diff := map[string]interface{}{"status_to": "CLOSED"}
-        return s.audit(ctx, tx, adminUID, "ticket.close", "TICKET", ticketID, diff)
+        return audit.Record(ctx, tx, adminUID, "ticket.close", "TICKET", ticketID, diff)
     })
     
     // 事务失败，直接返回错误
@@ -294,7 +286,7 @@ func (s *Service) CloseTicket(ctx context.Context, adminUID, ticketID uint) erro
 
             // 发送邮件通知
             s.notifier.NotifyTicketClosed(
-                context.Background(),
+                notifyCtx(ticket.Priority),
                 ticketID,
                 ticket.Title,
                 handler.Name,
@@ -338,7 +330,7 @@ func (s *Service) SpamFlag(ctx context.Context, adminUID, ticketID uint, reason
 			return err
 		}
 		diff := map[string]interface{}{"status_from": oldStatus, "status_to": "SPAM_PENDING", "reason": reason}
-		return s.audit(ctx, tx, adminUID, "ticket.spam_flag", "TICKET", ticketID, diff)
+		return audit.Record(ctx, tx, adminUID, "ticket.spam_flag", "TICKET", ticketID, diff)
 	})
 	if err != nil {
 		return nil, err
@@ -360,7 +352,7 @@ func (s *Service) SpamFlag(ctx context.Context, adminUID, ticketID uint, reason
 
 			// 发送邮件通知
 			s.notifier.NotifySpamFlagged(
-				context.Background(),
+				notifyCtx(ticket.Priority),
 				ticketID,
 				ticket.Title,
 				flagger.Name,
@@ -426,7 +418,7 @@ func (s *Service) SpamReview(ctx context.Context, superAdminUID, ticketID uint,
 		}
 		
 		diff := map[string]interface{}{"status_to": string(ticketTo), "review_action": act}
-		return s.audit(ctx, tx, superAdminUID, "ticket.spam_review", "TICKET", ticketID, diff)
+		return audit.Record(ctx, tx, superAdminUID, "ticket.spam_review", "TICKET", ticketID, diff)
 	})
 
 	// 事务失败，直接返回错误
@@ -458,7 +450,7 @@ func (s *Service) SpamReview(ctx context.Context, superAdminUID, ticketID uint,
 
 			// 发送邮件通知
 			s.notifier.NotifySpamReviewed(
-				context.Background(),
+				notifyCtx(ticket.Priority),
 				ticketID,
 				ticket.Title,
 				creator.Email,
@@ -0,0 +1,85 @@
+package ticket
+
+import (
+	"context"
+	"strings"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+)
+
+// hidesCreator 当前用户是否看不到工单创建者的身份：匿名工单对创建者以外的所有人（含超级管理员）隐藏，
+// 超级管理员需通过 RevealIdentity 查看
+func hidesCreator(viewer *dbpkg.User, t *dbpkg.Ticket) bool {
+	return t.IsAnonymous && viewer.ID != t.UserID
+}
+
+// creatorDisplayName 通知中展示的提交人名称：匿名工单使用代号
+func creatorDisplayName(t *dbpkg.Ticket, creator *dbpkg.User) string {
+	if t.IsAnonymous {
+		return dbpkg.AnonymousHandle(t.ID)
+	}
+	return creator.Name
+}
+
+// redactTicketCreator 隐藏列表项中的创建者身份
+func redactTicketCreator(item *openapi.Ticket) {
+	item.UserId = 0
+	item.CreatorHandle = dbpkg.AnonymousHandle(uint(item.Id))
+}
+
+// redactMessageCreator 隐藏消息中创建者的身份（发送者与已读信息）
+func redactMessageCreator(item *openapi.TicketMessage, t *dbpkg.Ticket) {
+	creator := int32(t.UserID)
+	if item.SenderUserId == creator {
+		item.SenderUserId = 0
+		item.SenderHandle = dbpkg.AnonymousHandle(t.ID)
+	}
+	seen := item.SeenBy[:0]
+	for _, id := range item.SeenBy {
+		if id != creator {
+			seen = append(seen, id)
+		}
+	}
+	if len(seen) == 0 {
+		seen = nil
+	}
+	item.SeenBy = seen
+}
+
+// RevealIdentity 超级管理员查看匿名工单创建者的真实身份；必须填写原因，每次查看都写入审计日志
+func (s *Service) RevealIdentity(ctx context.Context, superUID, ticketID uint, reason string) (*openapi.RevealedIdentity, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"reason": "必填"}}
+	}
+	u, t, err := s.getTicketWithAccessCheck(superUID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if u.Role != dbpkg.RoleSuperAdmin {
+		return nil, &ErrForbidden{Reason: "only super admins can reveal identity"}
+	}
+	if !t.IsAnonymous {
+		return nil, &ErrInvalidState{Message: "工单不是匿名工单"}
+	}
+
+	var creator dbpkg.User
+	if err := s.db.WithContext(ctx).First(&creator, t.UserID).Error; err != nil {
+		return nil, err
+	}
+	if err := audit.Record(ctx, s.db, superUID, "ticket.reveal_identity", "TICKET", t.ID, map[string]interface{}{
+		"reason": reason,
+	}); err != nil {
+		return nil, err
+	}
+	return &openapi.RevealedIdentity{
+		TicketId:      int32(t.ID),
+		UserId:        int32(creator.ID),
+		Name:          creator.Name,
+		Email:         creator.Email,
+		Dept:          creator.Dept,
+		CreatorHandle: dbpkg.AnonymousHandle(t.ID),
+	}, nil
+}
@@ -0,0 +1,120 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// checkAssignee 校验指派对象是启用中且当前在岗的管理员（含超级管理员）
+func (s *Service) checkAssignee(d *gorm.DB, assigneeID uint) (*dbpkg.User, error) {
+	var a dbpkg.User
+	if err := d.First(&a, assigneeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"assignee_id": "用户不存在"}}
+		}
+		return nil, err
+	}
+	if !isAdmin(a.Role) || !a.IsActive {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"assignee_id": "只能指派给启用中的管理员"}}
+	}
+	reason, err := s.offDutyReason(d, a.ID)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"assignee_id": "该管理员当前不在岗：" + reason}}
+	}
+	return &a, nil
+}
+
+// AssignTicket 管理员将工单指派给指定管理员：NEW 工单直接进入 CLAIMED，
+// 已受理（CLAIMED/IN_PROGRESS）的工单改为由新负责人处理（原子 CAS）
+func (s *Service) AssignTicket(ctx context.Context, adminUID, ticketID, assigneeID uint) error {
+	assignee, err := s.checkAssignee(s.db.WithContext(ctx), assigneeID)
+	if err != nil {
+		return err
+	}
+
+	claimed := false
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var t dbpkg.Ticket
+		if err := tx.First(&t, ticketID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &ErrNotFound{Resource: "ticket"}
+			}
+			return err
+		}
+		switch t.Status {
+		case dbpkg.TicketStatusNew, dbpkg.TicketStatusClaimed, dbpkg.TicketStatusInProgress:
+		default:
+			return &ErrInvalidState{Message: fmt.Sprintf("仅 'NEW'/'CLAIMED'/'IN_PROGRESS' 状态的工单可指派, 当前为 '%s'", t.Status)}
+		}
+		if t.AssignedAdminID != nil && *t.AssignedAdminID == assigneeID {
+			return nil // 已由该管理员负责
+		}
+
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		updates := map[string]interface{}{
+			"assigned_admin_id": assigneeID,
+			"updated_at":        now,
+		}
+		q := tx.Model(&dbpkg.Ticket{}).Where("id = ? AND status = ?", t.ID, t.Status)
+		if t.AssignedAdminID == nil {
+			q = q.Where("assigned_admin_id IS NULL")
+		} else {
+			q = q.Where("assigned_admin_id = ?", *t.AssignedAdminID)
+		}
+		if t.Status == dbpkg.TicketStatusNew {
+			updates["status"] = dbpkg.TicketStatusClaimed
+			updates["claimed_at"] = &now
+			claimed = true
+		}
+		res := q.Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return &ErrConflict{Message: "工单状态已变化，请刷新后重试"}
+		}
+
+		diff := map[string]interface{}{
+			"assigned_admin_id": map[string]interface{}{"from": t.AssignedAdminID, "to": assigneeID},
+		}
+		if claimed {
+			diff["status_to"] = dbpkg.TicketStatusClaimed
+		}
+		return audit.Record(ctx, tx, adminUID, "ticket.assign", "TICKET", t.ID, diff)
+	})
+	if err != nil {
+		return err
+	}
+
+	// 新工单被指派时与接单一样通知学生
+	if claimed && s.notifier != nil {
+		go func() {
+			var ticket dbpkg.Ticket
+			var creator dbpkg.User
+			if err := s.db.First(&ticket, ticketID).Error; err != nil {
+				return // 静默失败，不影响主流程
+			}
+			if err := s.db.First(&creator, ticket.UserID).Error; err != nil {
+				return
+			}
+			s.notifier.NotifyTicketClaimed(
+				notifyCtx(ticket.Priority),
+				ticketID,
+				ticket.Title,
+				assignee.Name,
+				creator.Email,
+			)
+		}()
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package ticket
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// autoCloseBatchSize 每批处理的工单数量
+const autoCloseBatchSize = 100
+
+// AutoCloseResolved 自动关闭已处理超过 after 且此后学生未再回复的工单，返回关闭数量。
+// 审计记录的操作人为 0（系统）。历史数据没有 resolved_at 时以 updated_at 代替。
+func (s *Service) AutoCloseResolved(ctx context.Context, after time.Duration) (int, error) {
+	cutoff := time.Now().UTC().Add(-after)
+	closed := 0
+	lastID := uint(0)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return closed, err
+		}
+
+		var batch []dbpkg.Ticket
+		err := s.db.WithContext(ctx).
+			Where("id > ? AND status = ? AND COALESCE(resolved_at, updated_at) <= ?", lastID, dbpkg.TicketStatusResolved, cutoff).
+			Where(`NOT EXISTS (SELECT 1 FROM ticket_messages m
+				WHERE m.ticket_id = tickets.id AND m.sender_user_id = tickets.user_id
+				AND m.created_at > COALESCE(tickets.resolved_at, tickets.updated_at))`).
+			Order("id ASC").
+			Limit(autoCloseBatchSize).
+			Find(&batch).Error
+		if err != nil {
+			return closed, err
+		}
+		if len(batch) == 0 {
+			return closed, nil
+		}
+
+		for i := range batch {
+			t := &batch[i]
+			lastID = t.ID
+			ok, err := s.autoCloseOne(ctx, t, after)
+			if err != nil {
+				return closed, err
+			}
+			if ok {
+				closed++
+				s.notifyAutoClosed(ctx, t)
+			}
+		}
+	}
+}
+
+// autoCloseOne 以 CAS 方式关闭单个工单；期间状态被他人修改时返回 false
+func (s *Service) autoCloseOne(ctx context.Context, t *dbpkg.Ticket, after time.Duration) (bool, error) {
+	var ok bool
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&dbpkg.Ticket{}).
+			Where("id = ? AND status = ?", t.ID, dbpkg.TicketStatusResolved).
+			Updates(map[string]interface{}{
+				"status":     dbpkg.TicketStatusClosed,
+				"updated_at": time.Now().UTC().Truncate(time.Microsecond),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		ok = true
+
+		resolvedAt := t.UpdatedAt
+		if t.ResolvedAt != nil {
+			resolvedAt = *t.ResolvedAt
+		}
+		diff := map[string]interface{}{
+			"status_from": string(dbpkg.TicketStatusResolved),
+			"status_to":   string(dbpkg.TicketStatusClosed),
+			"resolved_at": resolvedAt,
+			"after_days":  int(after.Hours() / 24),
+		}
+		return audit.Record(ctx, tx, 0, "ticket.auto_close", "TICKET", t.ID, diff)
+	})
+	return ok, err
+}
+
+// notifyAutoClosed 发送关闭通知（失败只记录日志，不影响任务）
+func (s *Service) notifyAutoClosed(ctx context.Context, t *dbpkg.Ticket) {
+	if s.notifier == nil {
+		return
+	}
+	var creator dbpkg.User
+	if err := s.db.WithContext(ctx).First(&creator, t.UserID).Error; err != nil {
+		return
+	}
+	handlerName, handlerEmail := "系统", ""
+	if t.AssignedAdminID != nil {
+		var handler dbpkg.User
+		if err := s.db.WithContext(ctx).First(&handler, *t.AssignedAdminID).Error; err == nil {
+			handlerName, handlerEmail = handler.Name, handler.Email
+		}
+	}
+	if err := s.notifier.NotifyTicketClosed(ctx, t.ID, t.Title, handlerName, creator.Email, handlerEmail); err != nil {
+		log.Printf("ticket: 自动关闭工单 %d 的通知发送失败: %v", t.ID, err)
+	}
+}
@@ -0,0 +1,180 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+)
+
+// bulkMaxTickets 单次批量操作最多处理的工单数
+const bulkMaxTickets = 100
+
+// 批量操作支持的动作
+const (
+	bulkActionClaim       = "claim"
+	bulkActionClose       = "close"
+	bulkActionTag         = "tag"
+	bulkActionAssign      = "assign"
+	bulkActionSpamFlag    = "spam_flag"
+	bulkActionSetPriority = "set_priority"
+)
+
+// BulkAction 管理员对一批工单执行同一动作。每张工单复用单张操作的服务逻辑（各自独立事务与审计），
+// 单张失败不影响其余工单；整批操作另记一条审计日志。
+func (s *Service) BulkAction(ctx context.Context, adminUID uint, in openapi.TicketsBulkPostRequest) (*openapi.BulkTicketResult, error) {
+	action := strings.ToLower(strings.TrimSpace(in.Action))
+	ids := uniqueIDsInOrder(in.TicketIds)
+
+	details := map[string]interface{}{}
+	switch {
+	case len(ids) == 0:
+		details["ticket_ids"] = "必填"
+	case len(ids) > bulkMaxTickets:
+		details["ticket_ids"] = "单次最多处理 100 个工单"
+	}
+
+	var run func(ticketID uint) error
+	params := map[string]interface{}{}
+	switch action {
+	case bulkActionClaim:
+		// 操作者不在岗时整批拒绝，而不是每张工单各失败一次
+		reason, err := s.offDutyReason(s.db.WithContext(ctx), adminUID)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			details["action"] = "当前不在岗（" + reason + "），无法接单"
+		}
+		run = func(id uint) error { return s.ClaimTicket(ctx, adminUID, id) }
+	case bulkActionClose:
+		run = func(id uint) error { return s.CloseTicket(ctx, adminUID, id) }
+	case bulkActionTag:
+		names, tagDetails := normalizeTagNames(in.Tags)
+		for k, v := range tagDetails {
+			details[k] = v
+		}
+		if len(names) == 0 && len(tagDetails) == 0 {
+			details["tags"] = "必填"
+		}
+		params["tags"] = names
+		run = func(id uint) error {
+			_, err := s.AddTicketTags(ctx, adminUID, id, names)
+			return err
+		}
+	case bulkActionAssign:
+		if in.AssigneeId <= 0 {
+			details["assignee_id"] = "必填"
+			break
+		}
+		// 指派对象只需校验一次
+		if _, err := s.checkAssignee(s.db.WithContext(ctx), uint(in.AssigneeId)); err != nil {
+			var ve *ErrValidation
+			if !errors.As(err, &ve) {
+				return nil, err
+			}
+			for k, v := range ve.Details {
+				details[k] = v
+			}
+		}
+		params["assignee_id"] = in.AssigneeId
+		run = func(id uint) error { return s.AssignTicket(ctx, adminUID, id, uint(in.AssigneeId)) }
+	case bulkActionSpamFlag:
+		reason := strings.TrimSpace(in.Reason)
+		if reason == "" {
+			details["reason"] = "必填"
+		}
+		params["reason"] = reason
+		run = func(id uint) error {
+			_, err := s.SpamFlag(ctx, adminUID, id, reason)
+			return err
+		}
+	case bulkActionSetPriority:
+		p := dbpkg.TicketPriority(strings.ToUpper(strings.TrimSpace(string(in.Priority))))
+		if !dbpkg.ValidTicketPriority(p) {
+			details["priority"] = "取值必须为 P1/P2/P3/P4"
+		}
+		params["priority"] = p
+		req := openapi.TicketsIdPriorityPutRequest{Priority: openapi.TicketPriority(p), Reason: in.Reason}
+		run = func(id uint) error {
+			_, err := s.SetPriority(ctx, adminUID, id, req)
+			return err
+		}
+	default:
+		details["action"] = "取值必须为 claim/close/tag/assign/spam_flag/set_priority"
+	}
+	if len(details) > 0 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: details}
+	}
+
+	out := &openapi.BulkTicketResult{
+		Action:  action,
+		Total:   int32(len(ids)),
+		Results: make([]openapi.BulkTicketItemResult, 0, len(ids)),
+	}
+	succeeded, failed := []uint{}, []uint{}
+	for _, id := range ids {
+		item := openapi.BulkTicketItemResult{TicketId: int32(id), Success: true}
+		if err := run(id); err != nil {
+			item.Success = false
+			item.Code, item.Error = bulkItemError(err)
+			if item.Code == "internal" {
+				log.Printf("ticket: 批量操作 %s 处理工单 %d 失败: %v", action, id, err)
+			}
+			failed = append(failed, id)
+		} else {
+			succeeded = append(succeeded, id)
+		}
+		out.Results = append(out.Results, item)
+	}
+	out.Succeeded, out.Failed = int32(len(succeeded)), int32(len(failed))
+
+	params["action"] = action
+	params["succeeded"] = succeeded
+	params["failed"] = failed
+	if err := audit.Record(ctx, s.db, adminUID, "ticket.bulk", "TICKET_BULK", 0, params); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// bulkItemError 将单张工单的错误转换为批量结果中的分类与说明（内部错误不暴露细节）
+func bulkItemError(err error) (code, msg string) {
+	switch e := err.(type) {
+	case *ErrNotFound:
+		return "not_found", "资源不存在"
+	case *ErrForbidden:
+		return "forbidden", e.Reason
+	case *ErrValidation:
+		return "validation", e.Message
+	case *ErrConflict:
+		return "conflict", e.Message
+	case *ErrInvalidState:
+		return "invalid_state", e.Message
+	case *ErrMerged:
+		return "merged", "工单已合并"
+	default:
+		return "internal", "处理失败"
+	}
+}
+
+// uniqueIDsInOrder 去重并过滤非法值（<= 0），保持原有顺序
+func uniqueIDsInOrder(ids []int32) []uint {
+	out := make([]uint, 0, len(ids))
+	seen := map[uint]struct{}{}
+	for _, id32 := range ids {
+		if id32 <= 0 {
+			continue
+		}
+		id := uint(id32)
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -4,19 +4,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
+
 	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
 
 	"gorm.io/gorm"
 )
 
 // EmailNotifier 邮件通知接口
 type EmailNotifier interface {
-	NotifyTicketCreated(ctx context.Context, ticketID uint, title, category, creatorName string) error
+	NotifyTicketCreated(ctx context.Context, ticketID uint, title, category, creatorName string, isAnonymous bool) error
 	NotifyTicketClaimed(ctx context.Context, ticketID uint, title, handlerName, creatorEmail string) error
 	NotifyTicketResolved(ctx context.Context, ticketID uint, title, resolution, handlerName, creatorEmail, handlerEmail string) error
 	NotifyTicketClosed(ctx context.Context, ticketID uint, title, handlerName, creatorEmail, handlerEmail string) error
 	NotifyNewMessage(ctx context.Context, ticketID uint, senderName, message, creatorEmail, handlerEmail string) error
+	NotifyMentioned(ctx context.Context, ticketID uint, title, senderName, message string, mentionedEmails []string) error
 	NotifyTicketUnclaimed(ctx context.Context, ticketID uint, title, creatorEmail string) error
+	NotifyTicketWithdrawn(ctx context.Context, ticketID uint, title, reason, creatorName, handlerName, handlerEmail string) error
+	NotifyTicketMerged(ctx context.Context, sourceID, targetID uint, sourceTitle, targetTitle, creatorEmail string) error
 	NotifyTicketRated(ctx context.Context, ticketID uint, title, rating string, comments, handlerEmail string) error
 	NotifySpamFlagged(ctx context.Context, ticketID uint, title, reporterName string) error
 	NotifySpamReviewed(ctx context.Context, ticketID uint, title, creatorEmail, result string) error
@@ -25,7 +31,10 @@ type EmailNotifier interface {
 // Service 封装工单领域逻辑
 type Service struct {
 	db       *gorm.DB
-	notifier EmailNotifier // 邮件通知器（可选）
+	notifier EmailNotifier  // 邮件通知器（可选）
+	dutyLoc  *time.Location // 值班时区，判断管理员是否在岗（默认 UTC）
+	// editWindow 发送者可编辑消息的时限（<= 0 时使用 defaultMessageEditWindow）
+	editWindow time.Duration
 }
 
 func NewService(db *gorm.DB) *Service {
@@ -40,6 +49,38 @@ func NewServiceWithNotifier(db *gorm.DB, notifier EmailNotifier) *Service {
 	}
 }
 
+// SetDutyLocation 设置判断管理员在岗状态所用的值班时区，需在开始处理请求前调用
+func (s *Service) SetDutyLocation(loc *time.Location) {
+	s.dutyLoc = loc
+}
+
+// SetMessageEditWindow 设置发送者可编辑消息的时限；<= 0 时保持默认值
+func (s *Service) SetMessageEditWindow(d time.Duration) {
+	if d > 0 {
+		s.editWindow = d
+	}
+}
+
+// dutyNow 返回值班时区下的当前时间
+func (s *Service) dutyNow() time.Time {
+	if s.dutyLoc == nil {
+		return time.Now().UTC()
+	}
+	return time.Now().In(s.dutyLoc)
+}
+
+// offDutyReason 返回管理员当前不在岗的原因（在岗时为空）；外出且设置了代理人时一并给出代理人
+func (s *Service) offDutyReason(d *gorm.DB, adminID uint) (string, error) {
+	reason, ooo, err := dbpkg.AdminOffDutyReason(d, adminID, s.dutyNow())
+	if err != nil || reason == "" {
+		return "", err
+	}
+	if ooo != nil && ooo.DelegateUserID != nil {
+		reason += fmt.Sprintf("，代理人为用户 %d", *ooo.DelegateUserID)
+	}
+	return reason, nil
+}
+
 // ---- 共享错误类型 ----
 
 type ErrValidation struct {
@@ -73,6 +114,11 @@ type ErrConflict struct{ Message string }
 
 func (e *ErrConflict) Error() string { return "conflict: " + e.Message }
 
+// ErrMerged 工单已合并到其他工单，读取方应转向 TargetID
+type ErrMerged struct{ TargetID uint }
+
+func (e *ErrMerged) Error() string { return fmt.Sprintf("ticket merged into %d", e.TargetID) }
+
 // ---- 共享辅助函数 ----
 
 func (s *Service) currentUser(db *gorm.DB, uid uint) (*dbpkg.User, error) {
@@ -83,6 +129,33 @@ func isAdmin(role dbpkg.Role) bool {
 	return role == dbpkg.RoleAdmin || role == dbpkg.RoleSuperAdmin
 }
 
+// toAPITicket 将数据库工单转换为 API 模型
+func toAPITicket(t *dbpkg.Ticket, imageIDs []uint) openapi.Ticket {
+	img32 := make([]int32, 0, len(imageIDs))
+	for _, id := range imageIDs {
+		img32 = append(img32, int32(id))
+	}
+	return openapi.Ticket{
+		Id:                 int32(t.ID),
+		UserId:             int32(t.UserID),
+		Title:              t.Title,
+		Content:            t.Content,
+		Category:           t.Category,
+		CategoryId:         toPtrInt32FromUintPtr(t.CategoryID),
+		IsUrgent:           t.IsUrgent,
+		Priority:           openapi.TicketPriority(t.Priority),
+		IsAnonymous:        t.IsAnonymous,
+		Status:             openapi.TicketStatus(t.Status),
+		AssignedAdminId:    toPtrInt32FromUintPtr(t.AssignedAdminID),
+		ClaimedAt:          t.ClaimedAt,
+		SlaDueAt:           t.SLADueAt,
+		MergedIntoTicketId: toPtrInt32FromUintPtr(t.MergedIntoTicketID),
+		CreatedAt:          t.CreatedAt,
+		UpdatedAt:          t.UpdatedAt,
+		ImageIds:           img32,
+	}
+}
+
 func toPtrInt32FromUintPtr(p *uint) *int32 {
 	if p == nil {
 		return nil
@@ -116,5 +189,10 @@ func (s *Service) getTicketWithAccessCheck(currentUID, ticketID uint) (*dbpkg.Us
 		return nil, nil, &ErrForbidden{Reason: "student cannot access others' ticket"}
 	}
 
+	// 已合并的工单统一转向合并目标
+	if t.Status == dbpkg.TicketStatusMerged && t.MergedIntoTicketID != nil {
+		return nil, nil, &ErrMerged{TargetID: *t.MergedIntoTicketID}
+	}
+
 	return u, &t, nil
 }
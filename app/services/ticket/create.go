@@ -2,10 +2,12 @@ package ticket
 
 import (
 	"context"
+	"errors"
 	"sort"
 	"strings"
 	"time"
 
+	"student-services-platform-backend/internal/audit"
 	dbpkg "student-services-platform-backend/internal/db"
 	"student-services-platform-backend/internal/openapi"
 
@@ -13,58 +15,42 @@ import (
 )
 
 // CreateTicket 创建工单并可选关联图片
-func (s *Service) CreateTicket(userID uint, in openapi.TicketCreate) (*openapi.Ticket, error) {
+func (s *Service) CreateTicket(ctx context.Context, userID uint, in openapi.TicketCreate) (*openapi.Ticket, error) {
+	return s.createTicket(ctx, userID, in, nil)
+}
+
+// createTicket 创建工单；afterCreate 非空时在同一事务内执行（用于预先建立关联等）
+func (s *Service) createTicket(ctx context.Context, userID uint, in openapi.TicketCreate, afterCreate func(tx *gorm.DB, t *dbpkg.Ticket) error) (*openapi.Ticket, error) {
 	// 输入校验（与 OpenAPI 对齐）
-	details := map[string]interface{}{}
-	if in.Title == "" {
-		details["title"] = "必填"
-	} else if len([]rune(in.Title)) > 120 {
-		details["title"] = "长度不能超过 120"
-	}
-	if in.Content == "" {
-		details["content"] = "必填"
-	} else if len([]rune(in.Content)) > 4000 {
-		details["content"] = "长度不能超过 4000"
+	details := validateTicketFields(in.Title, in.Content)
+	cat, msg, err := resolveCategory(s.db, in.CategoryId, in.Category)
+	if err != nil {
+		return nil, err
 	}
-	if in.Category == "" {
-		details["category"] = "必填"
+	var fieldVals map[uint]string
+	if msg != "" {
+		details["category"] = msg
+	} else if fieldVals, err = validateFieldValues(s.db, cat.ID, in.Fields, details); err != nil {
+		return nil, err
 	}
 	if len(details) > 0 {
 		return nil, &ErrValidation{Message: "字段校验失败", Details: details}
 	}
 
-	// 去重并转换 image_ids -> []uint
-	uniqImg := make([]uint, 0, len(in.ImageIds))
-	seen := map[uint]struct{}{}
-	for _, id32 := range in.ImageIds {
-		if id32 <= 0 {
-			continue
-		}
-		u := uint(id32)
-		if _, ok := seen[u]; !ok {
-			seen[u] = struct{}{}
-			uniqImg = append(uniqImg, u)
-		}
+	priority, pmsg := resolveCreatorPriority(in.Priority, in.IsUrgent, "")
+	if pmsg != "" {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"priority": pmsg}}
 	}
-	sort.Slice(uniqImg, func(i, j int) bool { return uniqImg[i] < uniqImg[j] })
+	isUrgent := dbpkg.IsUrgentPriority(priority)
+
+	// 去重并转换 image_ids -> []uint
+	uniqImg := normalizeIDs(in.ImageIds)
 
 	var created *dbpkg.Ticket
-	err := s.db.Transaction(func(tx *gorm.DB) error {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 校验图片是否存在
-		if len(uniqImg) > 0 {
-			existsMap, err := dbpkg.GetExistingImageIDs(tx, uniqImg)
-			if err != nil {
-				return err
-			}
-			var missing []uint
-			for _, id := range uniqImg {
-				if !existsMap[id] {
-					missing = append(missing, id)
-				}
-			}
-			if len(missing) > 0 {
-				return &ErrImageNotFound{Missing: missing}
-			}
+		if err := checkImagesExist(tx, uniqImg); err != nil {
+			return err
 		}
 
 		// 创建 Ticket（默认 NEW）
@@ -73,13 +59,17 @@ func (s *Service) CreateTicket(userID uint, in openapi.TicketCreate) (*openapi.T
 			UserID:      userID,
 			Title:       strings.TrimSpace(in.Title),
 			Content:     strings.TrimSpace(in.Content),
-			Category:    strings.TrimSpace(in.Category),
-			IsUrgent:    in.IsUrgent,
+			Category:    cat.Name,
+			CategoryID:  &cat.ID,
+			IsUrgent:    isUrgent,
+			Priority:    priority,
 			IsAnonymous: in.IsAnonymous,
 			Status:      dbpkg.TicketStatusNew,
 			CreatedAt:   now,
 			UpdatedAt:   now,
 		}
+		due := dbpkg.TicketSLADue(now, isUrgent)
+		t.SLADueAt = &due
 		if err := tx.Create(t).Error; err != nil {
 			return err
 		}
@@ -89,6 +79,28 @@ func (s *Service) CreateTicket(userID uint, in openapi.TicketCreate) (*openapi.T
 			return err
 		}
 
+		// 自定义字段值
+		if err := saveFieldValues(tx, t.ID, fieldVals, now); err != nil {
+			return err
+		}
+
+		if afterCreate != nil {
+			if err := afterCreate(tx, t); err != nil {
+				return err
+			}
+		}
+
+		err := audit.Record(ctx, tx, userID, "ticket.create", "TICKET", t.ID, map[string]interface{}{
+			"title":        t.Title,
+			"category":     t.Category,
+			"priority":     t.Priority,
+			"is_anonymous": t.IsAnonymous,
+			"image_ids":    uniqImg,
+		})
+		if err != nil {
+			return err
+		}
+
 		created = t
 		return nil
 	})
@@ -96,6 +108,9 @@ func (s *Service) CreateTicket(userID uint, in openapi.TicketCreate) (*openapi.T
 		return nil, err
 	}
 
+	// 建立全文检索索引
+	s.reindex(created.ID)
+
 	// 发送邮件通知（如果配置了notifier）
 	if s.notifier != nil {
 		go func() {
@@ -107,11 +122,12 @@ func (s *Service) CreateTicket(userID uint, in openapi.TicketCreate) (*openapi.T
 
 			// 发送工单创建通知
 			s.notifier.NotifyTicketCreated(
-				context.Background(),
+				notifyCtx(created.Priority),
 				created.ID,
 				created.Title,
 				created.Category,
-				user.Name,
+				creatorDisplayName(created, &user),
+				created.IsAnonymous,
 			)
 		}()
 	}
@@ -132,14 +148,110 @@ func (s *Service) CreateTicket(userID uint, in openapi.TicketCreate) (*openapi.T
 		Title:         created.Title,
 		Content:       created.Content,
 		Category:      created.Category,
+		CategoryId:    toPtrInt32FromUintPtr(created.CategoryID),
 		IsUrgent:      created.IsUrgent,
+		Priority:      openapi.TicketPriority(created.Priority),
 		IsAnonymous:   created.IsAnonymous,
 		Status:        openapi.TicketStatus(created.Status),
 		AssignedAdminId: toPtrInt32FromUintPtr(created.AssignedAdminID),
 		ClaimedAt:       created.ClaimedAt,
+		SlaDueAt:        created.SLADueAt,
 		CreatedAt:       created.CreatedAt,
 		UpdatedAt:       created.UpdatedAt,
 		ImageIds:        imgIDs32,
 	}
 	return out, nil
-}
\ No newline at end of file
+}
+
+// validateTicketFields 校验工单标题/正文，返回字段错误（为空表示通过）
+func validateTicketFields(title, content string) map[string]interface{} {
+	details := map[string]interface{}{}
+	if title == "" {
+		details["title"] = "必填"
+	} else if len([]rune(title)) > 120 {
+		details["title"] = "长度不能超过 120"
+	}
+	if content == "" {
+		details["content"] = "必填"
+	} else if len([]rune(content)) > 4000 {
+		details["content"] = "长度不能超过 4000"
+	}
+	return details
+}
+
+// resolveCategory 按 ID（优先）或名称/别名解析工单分类，要求分类已启用且没有启用的子分类。
+// 校验不通过时返回非空 msg；分类存在时 c 仍会返回，便于调用方放行未变更的旧分类。
+func resolveCategory(d *gorm.DB, id int32, name string) (c *dbpkg.Category, msg string, err error) {
+	switch {
+	case id > 0:
+		var row dbpkg.Category
+		if err := d.First(&row, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, "分类不存在", nil
+			}
+			return nil, "", err
+		}
+		c = &row
+	case strings.TrimSpace(name) != "":
+		c, err = dbpkg.FindCategoryByName(d, name)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, "未知分类: " + strings.TrimSpace(name), nil
+			}
+			return nil, "", err
+		}
+	default:
+		return nil, "必填", nil
+	}
+
+	if !c.IsActive {
+		return c, "分类已停用", nil
+	}
+	var n int64
+	if err := d.Model(&dbpkg.Category{}).Where("parent_id = ? AND is_active = ?", c.ID, true).Count(&n).Error; err != nil {
+		return nil, "", err
+	}
+	if n > 0 {
+		return c, "请选择具体的子分类", nil
+	}
+	return c, "", nil
+}
+
+// normalizeIDs 去重、过滤非法值（<= 0）并升序排列
+func normalizeIDs(ids []int32) []uint {
+	out := make([]uint, 0, len(ids))
+	seen := map[uint]struct{}{}
+	for _, id32 := range ids {
+		if id32 <= 0 {
+			continue
+		}
+		u := uint(id32)
+		if _, ok := seen[u]; !ok {
+			seen[u] = struct{}{}
+			out = append(out, u)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// checkImagesExist 校验图片均存在，缺失时返回 ErrImageNotFound
+func checkImagesExist(tx *gorm.DB, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	existsMap, err := dbpkg.GetExistingImageIDs(tx, ids)
+	if err != nil {
+		return err
+	}
+	var missing []uint
+	for _, id := range ids {
+		if !existsMap[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return &ErrImageNotFound{Missing: missing}
+	}
+	return nil
+}
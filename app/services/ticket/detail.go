@@ -9,7 +9,7 @@ import (
 )
 
 func (s *Service) GetTicketDetail(currentUID, ticketID uint) (*openapi.TicketDetail, error) {
-	_, t, err := s.getTicketWithAccessCheck(currentUID, ticketID)
+	u, t, err := s.getTicketWithAccessCheck(currentUID, ticketID)
 	if err != nil {
 		return nil, err
 	}
@@ -43,23 +43,75 @@ func (s *Service) GetTicketDetail(currentUID, ticketID uint) (*openapi.TicketDet
 		}
 	}
 
+	// 编辑历史、标签与关注者（仅管理员可见）
+	var revisions []openapi.TicketRevision
+	var tags []string
+	var watchers []openapi.TicketWatcher
+	if isAdmin(u.Role) {
+		if revisions, err = s.listRevisions(t.ID); err != nil {
+			return nil, err
+		}
+		tagsMap, err := dbpkg.GetTicketTagsMap(s.db, []uint{t.ID})
+		if err != nil {
+			return nil, err
+		}
+		tags = tagsMap[t.ID]
+		if watchers, err = watchersFor(s.db, t.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// 自定义字段
+	fields, _, err := ticketFields(s.db, t.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 关联工单
+	links, err := s.linksFor(u, t)
+	if err != nil {
+		return nil, err
+	}
+
 	out := &openapi.TicketDetail{
 		Id:              int32(t.ID),
 		UserId:          int32(t.UserID),
 		Title:           t.Title,
 		Content:         t.Content,
 		Category:        t.Category,
+		CategoryId:      toPtrInt32FromUintPtr(t.CategoryID),
 		IsUrgent:        t.IsUrgent,
+		Priority:        openapi.TicketPriority(t.Priority),
 		IsAnonymous:     t.IsAnonymous,
 		Status:          openapi.TicketStatus(t.Status),
 		AssignedAdminId: toPtrInt32FromUintPtr(t.AssignedAdminID),
 		ClaimedAt:       t.ClaimedAt,
+		SlaDueAt:        t.SLADueAt,
+		MergedIntoTicketId: toPtrInt32FromUintPtr(t.MergedIntoTicketID),
 		CreatedAt:       t.CreatedAt,
 		UpdatedAt:       t.UpdatedAt,
 		ImageIds:        img32,
 		// Messages 统一交给独立端点获取，此处不填充
 		Messages: nil, 
 		Rating:   rating, // 现在会正确加载或为 nil
+		Revisions: revisions,
+		Links:     links,
+		Tags:      tags,
+		Fields:    fields,
+		Watchers:  watchers,
+	}
+
+	if hidesCreator(u, t) {
+		out.UserId = 0
+		out.CreatorHandle = dbpkg.AnonymousHandle(t.ID)
+		if out.Rating != nil {
+			out.Rating.UserId = 0
+		}
+		for i := range out.Revisions {
+			if out.Revisions[i].EditedBy == int32(t.UserID) {
+				out.Revisions[i].EditedBy = 0
+			}
+		}
 	}
 
 	return out, nil
@@ -0,0 +1,179 @@
+package ticket
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+
+	"gorm.io/gorm"
+)
+
+// defaultFieldMaxLength TEXT 字段未配置长度时的默认上限
+const defaultFieldMaxLength = 500
+
+// validateFieldValues 按分类的字段定义校验提交的自定义字段值，返回 field_id -> 规范化字符串。
+// 字段错误以 "fields.<key>" 为键写入 details。
+func validateFieldValues(d *gorm.DB, categoryID uint, in map[string]interface{}, details map[string]interface{}) (map[uint]string, error) {
+	defs, err := dbpkg.ListCategoryFields(d, []uint{categoryID}, false)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(defs[categoryID]))
+	out := make(map[uint]string, len(in))
+	for _, f := range defs[categoryID] {
+		known[f.Key] = true
+		v, msg, err := normalizeFieldValue(f, in[f.Key])
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case msg != "":
+			details["fields."+f.Key] = msg
+		case v == "" && f.Required:
+			details["fields."+f.Key] = "必填"
+		case v != "":
+			out[f.ID] = v
+		}
+	}
+	for k := range in {
+		if !known[k] {
+			details["fields."+k] = "该分类没有此字段"
+		}
+	}
+	return out, nil
+}
+
+// normalizeFieldValue 将单个字段值转换为存储用的字符串；空值返回 ""
+func normalizeFieldValue(f dbpkg.CategoryField, raw interface{}) (string, string, error) {
+	if raw == nil {
+		return "", "", nil
+	}
+	switch f.Type {
+	case dbpkg.CategoryFieldNumber:
+		var n float64
+		switch v := raw.(type) {
+		case float64:
+			n = v
+		case string:
+			if strings.TrimSpace(v) == "" {
+				return "", "", nil
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return "", "必须为数字", nil
+			}
+			n = parsed
+		default:
+			return "", "必须为数字", nil
+		}
+		if f.Min != nil && n < *f.Min {
+			return "", fmt.Sprintf("不能小于 %v", *f.Min), nil
+		}
+		if f.Max != nil && n > *f.Max {
+			return "", fmt.Sprintf("不能大于 %v", *f.Max), nil
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), "", nil
+	}
+
+	s, ok := raw.(string)
+	if !ok {
+		return "", "必须为字符串", nil
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", "", nil
+	}
+	switch f.Type {
+	case dbpkg.CategoryFieldSelect:
+		opts, err := dbpkg.CategoryFieldOptions(f)
+		if err != nil {
+			return "", "", err
+		}
+		for _, o := range opts {
+			if o == s {
+				return s, "", nil
+			}
+		}
+		return "", "不在可选值中", nil
+	case dbpkg.CategoryFieldDate:
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			return "", "日期格式应为 YYYY-MM-DD", nil
+		}
+		return s, "", nil
+	default:
+		max := defaultFieldMaxLength
+		if f.MaxLength != nil && *f.MaxLength > 0 {
+			max = *f.MaxLength
+		}
+		if len([]rune(s)) > max {
+			return "", fmt.Sprintf("长度不能超过 %d", max), nil
+		}
+		return s, "", nil
+	}
+}
+
+// saveFieldValues 以给定值整体替换工单的自定义字段值
+func saveFieldValues(tx *gorm.DB, ticketID uint, vals map[uint]string, now time.Time) error {
+	if err := tx.Where("ticket_id = ?", ticketID).Delete(&dbpkg.TicketFieldValue{}).Error; err != nil {
+		return err
+	}
+	if len(vals) == 0 {
+		return nil
+	}
+	rows := make([]dbpkg.TicketFieldValue, 0, len(vals))
+	for fid, v := range vals {
+		rows = append(rows, dbpkg.TicketFieldValue{TicketID: ticketID, FieldID: fid, Value: v, CreatedAt: now})
+	}
+	return tx.Create(&rows).Error
+}
+
+// ticketFields 返回工单的自定义字段值（API 结构）以及 key -> value 映射（用于修订记录与比较）
+func ticketFields(d *gorm.DB, ticketID uint) ([]openapi.TicketFieldValue, map[string]interface{}, error) {
+	rows, err := dbpkg.GetTicketFieldValues(d, ticketID)
+	if err != nil {
+		return nil, nil, err
+	}
+	list := make([]openapi.TicketFieldValue, 0, len(rows))
+	byKey := make(map[string]interface{}, len(rows))
+	for _, r := range rows {
+		var v interface{} = r.Value
+		if r.Field.Type == dbpkg.CategoryFieldNumber {
+			if n, err := strconv.ParseFloat(r.Value, 64); err == nil {
+				v = n
+			}
+		}
+		list = append(list, openapi.TicketFieldValue{
+			Key:   r.Field.Key,
+			Label: r.Field.Label,
+			Type:  openapi.CategoryFieldType(r.Field.Type),
+			Value: v,
+		})
+		byKey[r.Field.Key] = v
+	}
+	return list, byKey, nil
+}
+
+// fieldFilterSubquery 返回自定义字段 key 取值为 value 的工单 ID 子查询
+func (s *Service) fieldFilterSubquery(key, value string) *gorm.DB {
+	return s.db.Table("ticket_field_values v").
+		Select("v.ticket_id").
+		Joins("JOIN category_fields f ON f.id = v.field_id").
+		Where("f.key = ? AND v.value = ?", key, value)
+}
+
+// currentFieldValues 返回工单当前的字段值（field_id -> 存储值）
+func currentFieldValues(tx *gorm.DB, ticketID uint) (map[uint]string, error) {
+	var rows []dbpkg.TicketFieldValue
+	if err := tx.Where("ticket_id = ?", ticketID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[uint]string, len(rows))
+	for _, r := range rows {
+		out[r.FieldID] = r.Value
+	}
+	return out, nil
+}
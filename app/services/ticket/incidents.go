@@ -0,0 +1,584 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxIncidentAttach 单次关联到事件的工单数上限
+const maxIncidentAttach = 500
+
+// incidentAttachBlocked 不能再关联到事件的工单状态
+var incidentAttachBlocked = map[dbpkg.TicketStatus]bool{
+	dbpkg.TicketStatusClosed:        true,
+	dbpkg.TicketStatusCancelled:     true,
+	dbpkg.TicketStatusMerged:        true,
+	dbpkg.TicketStatusSpamPending:   true,
+	dbpkg.TicketStatusSpamConfirmed: true,
+}
+
+// incidentActiveStatuses 事件进展同步与统一处理的目标工单状态
+var incidentActiveStatuses = []dbpkg.TicketStatus{
+	dbpkg.TicketStatusNew,
+	dbpkg.TicketStatusClaimed,
+	dbpkg.TicketStatusInProgress,
+}
+
+// CreateIncident 管理员创建事件，可同时关联一批工单
+func (s *Service) CreateIncident(ctx context.Context, adminUID uint, in openapi.IncidentCreate) (*openapi.IncidentDetail, error) {
+	title := strings.TrimSpace(in.Title)
+	desc := strings.TrimSpace(in.Description)
+	details := map[string]interface{}{}
+	if title == "" {
+		details["title"] = "必填"
+	} else if len([]rune(title)) > 120 {
+		details["title"] = "长度不能超过 120"
+	}
+	if len([]rune(desc)) > 4000 {
+		details["description"] = "长度不能超过 4000"
+	}
+	if len(details) > 0 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: details}
+	}
+	ids := normalizeIDs(in.TicketIds)
+
+	var inc dbpkg.Incident
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		inc = dbpkg.Incident{
+			Title:       title,
+			Description: desc,
+			Status:      dbpkg.IncidentStatusOpen,
+			CreatedBy:   adminUID,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := tx.Create(&inc).Error; err != nil {
+			return err
+		}
+		if err := audit.Record(ctx, tx, adminUID, "incident.create", "INCIDENT", inc.ID, map[string]interface{}{"title": title}); err != nil {
+			return err
+		}
+		return s.attachTickets(ctx, tx, adminUID, &inc, ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetIncident(ctx, inc.ID)
+}
+
+// ListIncidents 分页列出事件（status 为空表示全部）
+func (s *Service) ListIncidents(ctx context.Context, status string, page, pageSize int) (*openapi.PagedIncidents, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	q := s.db.WithContext(ctx).Model(&dbpkg.Incident{})
+	if status = strings.ToUpper(strings.TrimSpace(status)); status != "" {
+		if status != string(dbpkg.IncidentStatusOpen) && status != string(dbpkg.IncidentStatusResolved) {
+			return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"status": "仅支持 OPEN/RESOLVED"}}
+		}
+		q = q.Where("status = ?", status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, err
+	}
+	var rows []dbpkg.Incident
+	if err := q.Order("created_at DESC, id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, r := range rows {
+		ids = append(ids, r.ID)
+	}
+	counts, err := s.incidentTicketCounts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]openapi.Incident, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, toAPIIncident(r, counts[r.ID]))
+	}
+	return &openapi.PagedIncidents{
+		Items:    items,
+		Page:     int32(page),
+		PageSize: int32(pageSize),
+		Total:    int32(total),
+	}, nil
+}
+
+// GetIncident 事件详情（含关联工单与进展）
+func (s *Service) GetIncident(ctx context.Context, incidentID uint) (*openapi.IncidentDetail, error) {
+	d := s.db.WithContext(ctx)
+	inc, err := loadIncident(d, incidentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []dbpkg.Ticket
+	if err := d.Joins("JOIN incident_tickets it ON it.ticket_id = tickets.id").
+		Where("it.incident_id = ?", inc.ID).
+		Order("tickets.id ASC").
+		Find(&tickets).Error; err != nil {
+		return nil, err
+	}
+	ticketIDs := make([]uint, 0, len(tickets))
+	for _, t := range tickets {
+		ticketIDs = append(ticketIDs, t.ID)
+	}
+	imgMap, err := dbpkg.GetTicketImagesMap(d, ticketIDs)
+	if err != nil {
+		return nil, err
+	}
+	apiTickets := make([]openapi.Ticket, 0, len(tickets))
+	for i := range tickets {
+		item := toAPITicket(&tickets[i], imgMap[tickets[i].ID])
+		// 事件仅管理员可见，匿名工单一律隐藏创建者
+		if tickets[i].IsAnonymous {
+			redactTicketCreator(&item)
+		}
+		apiTickets = append(apiTickets, item)
+	}
+
+	var updates []dbpkg.IncidentUpdate
+	if err := d.Where("incident_id = ?", inc.ID).Order("created_at ASC, id ASC").Find(&updates).Error; err != nil {
+		return nil, err
+	}
+	apiUpdates := make([]openapi.IncidentUpdate, 0, len(updates))
+	for _, u := range updates {
+		apiUpdates = append(apiUpdates, toAPIIncidentUpdate(u))
+	}
+
+	base := toAPIIncident(*inc, len(tickets))
+	return &openapi.IncidentDetail{
+		Id:          base.Id,
+		Title:       base.Title,
+		Description: base.Description,
+		Status:      base.Status,
+		CreatedBy:   base.CreatedBy,
+		TicketCount: base.TicketCount,
+		ResolvedAt:  base.ResolvedAt,
+		CreatedAt:   base.CreatedAt,
+		UpdatedAt:   base.UpdatedAt,
+		Tickets:     apiTickets,
+		Updates:     apiUpdates,
+	}, nil
+}
+
+// AttachIncidentTickets 将工单关联到事件
+func (s *Service) AttachIncidentTickets(ctx context.Context, adminUID, incidentID uint, ticketIDs []int32) (*openapi.IncidentDetail, error) {
+	ids := normalizeIDs(ticketIDs)
+	if len(ids) == 0 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"ticket_ids": "必填"}}
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		inc, err := loadIncident(tx, incidentID)
+		if err != nil {
+			return err
+		}
+		return s.attachTickets(ctx, tx, adminUID, inc, ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetIncident(ctx, incidentID)
+}
+
+// DetachIncidentTicket 将工单移出事件
+func (s *Service) DetachIncidentTicket(ctx context.Context, adminUID, incidentID, ticketID uint) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		inc, err := loadIncident(tx, incidentID)
+		if err != nil {
+			return err
+		}
+		if inc.Status != dbpkg.IncidentStatusOpen {
+			return &ErrInvalidState{Message: "事件已处理，不能再调整关联工单"}
+		}
+		res := tx.Where("incident_id = ? AND ticket_id = ?", incidentID, ticketID).Delete(&dbpkg.IncidentTicket{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return &ErrNotFound{Resource: "incident ticket"}
+		}
+		return audit.Record(ctx, tx, adminUID, "incident.detach", "INCIDENT", incidentID, map[string]interface{}{"ticket_id": ticketID})
+	})
+}
+
+// PostIncidentUpdate 发布事件进展：在同一事务内作为公开消息写入每个处理中的关联工单，随后逐一通知学生
+func (s *Service) PostIncidentUpdate(ctx context.Context, adminUID, incidentID uint, body string) (*openapi.IncidentUpdate, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"body": "必填"}}
+	}
+	if len([]rune(body)) > 4000 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"body": "长度不能超过 4000"}}
+	}
+
+	var upd dbpkg.IncidentUpdate
+	var targets []dbpkg.Ticket
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		inc, err := loadIncident(tx, incidentID)
+		if err != nil {
+			return err
+		}
+		if inc.Status != dbpkg.IncidentStatusOpen {
+			return &ErrInvalidState{Message: "事件已处理，不能再发布进展"}
+		}
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		targets, upd, err = s.fanOutIncidentUpdate(tx, adminUID, inc.ID, body, now)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&dbpkg.Incident{}).Where("id = ?", inc.ID).Update("updated_at", now).Error; err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, adminUID, "incident.update", "INCIDENT", inc.ID, map[string]interface{}{
+			"update_id": upd.ID, "ticket_count": len(targets),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.afterIncidentFanOut(adminUID, targets, body)
+
+	out := toAPIIncidentUpdate(upd)
+	return &out, nil
+}
+
+// ResolveIncident 处理事件：在一个事务内将所有处理中的关联工单标记为 RESOLVED。
+// 未被接单的工单由操作者接手（操作者不在岗时拒绝，需先指派）；填写了处理说明时先作为最后一条进展同步到各工单。
+func (s *Service) ResolveIncident(ctx context.Context, adminUID, incidentID uint, resolution string) (*openapi.IncidentDetail, error) {
+	resolution = strings.TrimSpace(resolution)
+	if len([]rune(resolution)) > 4000 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"resolution": "长度不能超过 4000"}}
+	}
+	offDuty, err := s.offDutyReason(s.db.WithContext(ctx), adminUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []dbpkg.Ticket
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		res := tx.Model(&dbpkg.Incident{}).
+			Where("id = ? AND status = ?", incidentID, dbpkg.IncidentStatusOpen).
+			Updates(map[string]interface{}{
+				"status":      dbpkg.IncidentStatusResolved,
+				"resolved_at": now,
+				"updated_at":  now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			if _, err := loadIncident(tx, incidentID); err != nil {
+				return err
+			}
+			return &ErrInvalidState{Message: "事件已处理"}
+		}
+
+		if resolution != "" {
+			var err error
+			if targets, _, err = s.fanOutIncidentUpdate(tx, adminUID, incidentID, resolution, now); err != nil {
+				return err
+			}
+		} else if err := activeIncidentTickets(tx, incidentID, &targets); err != nil {
+			return err
+		}
+
+		for _, t := range targets {
+			updates := map[string]interface{}{
+				"status":      dbpkg.TicketStatusResolved,
+				"resolved_at": now,
+				"updated_at":  now,
+			}
+			if t.AssignedAdminID == nil {
+				if offDuty != "" {
+					return &ErrInvalidState{Message: fmt.Sprintf("当前不在岗（%s），无法接手未受理的工单 %d，请先指派给在岗管理员", offDuty, t.ID)}
+				}
+				updates["assigned_admin_id"] = adminUID
+				updates["claimed_at"] = now
+			}
+			if err := tx.Model(&dbpkg.Ticket{}).
+				Where("id = ? AND status IN ?", t.ID, incidentActiveStatuses).
+				Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		// 审计日志放在全部修改之后写入，缩短哈希链链头的加锁时间
+		for _, t := range targets {
+			diff := map[string]interface{}{"status_to": string(dbpkg.TicketStatusResolved), "incident_id": incidentID}
+			if err := audit.Record(ctx, tx, adminUID, "ticket.resolve", "TICKET", t.ID, diff); err != nil {
+				return err
+			}
+		}
+		return audit.Record(ctx, tx, adminUID, "incident.resolve", "INCIDENT", incidentID, map[string]interface{}{
+			"ticket_count": len(targets),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if resolution != "" {
+		s.afterIncidentFanOut(adminUID, targets, resolution)
+	}
+	if s.notifier != nil && len(targets) > 0 {
+		go func(targets []dbpkg.Ticket) {
+			var handler dbpkg.User
+			if err := s.db.First(&handler, adminUID).Error; err != nil {
+				return // 静默失败，不影响主流程
+			}
+			for _, t := range targets {
+				var creator dbpkg.User
+				if err := s.db.First(&creator, t.UserID).Error; err != nil {
+					continue
+				}
+				s.notifier.NotifyTicketResolved(
+					notifyCtx(t.Priority),
+					t.ID,
+					t.Title,
+					firstNonEmpty(resolution, "您的工单已处理完成"),
+					handler.Name,
+					creator.Email,
+					handler.Email,
+				)
+			}
+		}(targets)
+	}
+
+	return s.GetIncident(ctx, incidentID)
+}
+
+// ---- 内部辅助 ----
+
+func loadIncident(d *gorm.DB, id uint) (*dbpkg.Incident, error) {
+	var inc dbpkg.Incident
+	if err := d.First(&inc, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &ErrNotFound{Resource: "incident"}
+		}
+		return nil, err
+	}
+	return &inc, nil
+}
+
+// attachTickets 校验并关联工单（已在其他事件中的工单返回冲突）
+func (s *Service) attachTickets(ctx context.Context, tx *gorm.DB, adminUID uint, inc *dbpkg.Incident, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if inc.Status != dbpkg.IncidentStatusOpen {
+		return &ErrInvalidState{Message: "事件已处理，不能再调整关联工单"}
+	}
+	if len(ids) > maxIncidentAttach {
+		return &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"ticket_ids": "单次最多关联 500 个工单"}}
+	}
+
+	var tickets []dbpkg.Ticket
+	if err := tx.Select("id", "status").Where("id IN ?", ids).Find(&tickets).Error; err != nil {
+		return err
+	}
+	found := make(map[uint]dbpkg.TicketStatus, len(tickets))
+	for _, t := range tickets {
+		found[t.ID] = t.Status
+	}
+	var missing, blocked []uint
+	for _, id := range ids {
+		st, ok := found[id]
+		switch {
+		case !ok:
+			missing = append(missing, id)
+		case incidentAttachBlocked[st]:
+			blocked = append(blocked, id)
+		}
+	}
+	if len(missing) > 0 || len(blocked) > 0 {
+		details := map[string]interface{}{}
+		if len(missing) > 0 {
+			details["missing_ticket_ids"] = missing
+		}
+		if len(blocked) > 0 {
+			details["inactive_ticket_ids"] = blocked
+		}
+		return &ErrValidation{Message: "部分工单不存在或已结束", Details: details}
+	}
+
+	var taken []uint
+	if err := tx.Model(&dbpkg.IncidentTicket{}).
+		Where("ticket_id IN ? AND incident_id <> ?", ids, inc.ID).
+		Pluck("ticket_id", &taken).Error; err != nil {
+		return err
+	}
+	if len(taken) > 0 {
+		return &ErrConflict{Message: "部分工单已属于其他事件"}
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	rels := make([]dbpkg.IncidentTicket, 0, len(ids))
+	for _, id := range ids {
+		rels = append(rels, dbpkg.IncidentTicket{IncidentID: inc.ID, TicketID: id, AddedBy: adminUID, CreatedAt: now})
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rels).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&dbpkg.Incident{}).Where("id = ?", inc.ID).Update("updated_at", now).Error; err != nil {
+		return err
+	}
+	return audit.Record(ctx, tx, adminUID, "incident.attach", "INCIDENT", inc.ID, map[string]interface{}{"ticket_ids": ids})
+}
+
+// activeIncidentTickets 查询事件下处理中的关联工单
+func activeIncidentTickets(tx *gorm.DB, incidentID uint, out *[]dbpkg.Ticket) error {
+	return tx.Joins("JOIN incident_tickets it ON it.ticket_id = tickets.id").
+		Where("it.incident_id = ? AND tickets.status IN ?", incidentID, incidentActiveStatuses).
+		Order("tickets.id ASC").
+		Find(out).Error
+}
+
+// fanOutIncidentUpdate 记录事件进展，并为每个处理中的关联工单写入一条公开消息
+func (s *Service) fanOutIncidentUpdate(tx *gorm.DB, adminUID, incidentID uint, body string, now time.Time) ([]dbpkg.Ticket, dbpkg.IncidentUpdate, error) {
+	var targets []dbpkg.Ticket
+	if err := activeIncidentTickets(tx, incidentID, &targets); err != nil {
+		return nil, dbpkg.IncidentUpdate{}, err
+	}
+	upd := dbpkg.IncidentUpdate{
+		IncidentID:  incidentID,
+		AuthorID:    adminUID,
+		Body:        body,
+		TicketCount: len(targets),
+		CreatedAt:   now,
+	}
+	if err := tx.Create(&upd).Error; err != nil {
+		return nil, upd, err
+	}
+	if len(targets) == 0 {
+		return targets, upd, nil
+	}
+	msgs := make([]dbpkg.TicketMessage, 0, len(targets))
+	ids := make([]uint, 0, len(targets))
+	for _, t := range targets {
+		msgs = append(msgs, dbpkg.TicketMessage{
+			TicketID:     t.ID,
+			SenderUserID: adminUID,
+			Body:         body,
+			CreatedAt:    now,
+		})
+		ids = append(ids, t.ID)
+	}
+	if err := tx.CreateInBatches(&msgs, 100).Error; err != nil {
+		return nil, upd, err
+	}
+	if err := tx.Model(&dbpkg.Ticket{}).Where("id IN ?", ids).Update("updated_at", now).Error; err != nil {
+		return nil, upd, err
+	}
+	return targets, upd, nil
+}
+
+// afterIncidentFanOut 进展同步后重建检索索引并逐一发送新消息通知
+func (s *Service) afterIncidentFanOut(adminUID uint, targets []dbpkg.Ticket, body string) {
+	for _, t := range targets {
+		s.reindex(t.ID)
+	}
+	if s.notifier == nil || len(targets) == 0 {
+		return
+	}
+	go func() {
+		var sender dbpkg.User
+		if err := s.db.First(&sender, adminUID).Error; err != nil {
+			return // 静默失败，不影响主流程
+		}
+		for _, t := range targets {
+			var creator dbpkg.User
+			var handler dbpkg.User
+			if err := s.db.First(&creator, t.UserID).Error; err != nil {
+				continue
+			}
+			if t.AssignedAdminID != nil {
+				s.db.First(&handler, *t.AssignedAdminID)
+			}
+			s.notifier.NotifyNewMessage(
+				notifyCtx(t.Priority),
+				t.ID,
+				sender.Name,
+				body,
+				creator.Email,
+				handler.Email,
+			)
+		}
+	}()
+}
+
+// incidentTicketCounts 批量统计事件的关联工单数
+func (s *Service) incidentTicketCounts(ctx context.Context, ids []uint) (map[uint]int, error) {
+	out := make(map[uint]int, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	type row struct {
+		IncidentID uint
+		N          int
+	}
+	var rows []row
+	if err := s.db.WithContext(ctx).Model(&dbpkg.IncidentTicket{}).
+		Select("incident_id, COUNT(*) AS n").
+		Where("incident_id IN ?", ids).
+		Group("incident_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		out[r.IncidentID] = r.N
+	}
+	return out, nil
+}
+
+func toAPIIncident(inc dbpkg.Incident, ticketCount int) openapi.Incident {
+	return openapi.Incident{
+		Id:          int32(inc.ID),
+		Title:       inc.Title,
+		Description: inc.Description,
+		Status:      string(inc.Status),
+		CreatedBy:   int32(inc.CreatedBy),
+		TicketCount: int32(ticketCount),
+		ResolvedAt:  inc.ResolvedAt,
+		CreatedAt:   inc.CreatedAt,
+		UpdatedAt:   inc.UpdatedAt,
+	}
+}
+
+func toAPIIncidentUpdate(u dbpkg.IncidentUpdate) openapi.IncidentUpdate {
+	return openapi.IncidentUpdate{
+		Id:          int32(u.ID),
+		IncidentId:  int32(u.IncidentID),
+		AuthorId:    int32(u.AuthorID),
+		Body:        u.Body,
+		TicketCount: int32(u.TicketCount),
+		CreatedAt:   u.CreatedAt,
+	}
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
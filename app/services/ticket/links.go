@@ -0,0 +1,320 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+
+	"gorm.io/gorm"
+)
+
+// maxParentDepth 沿父工单链向上查找的最大深度（防御异常数据）
+const maxParentDepth = 100
+
+// linkTypeFromAPI 将当前工单角度的关联类型转换为存储方向：
+// 返回 reversed=true 表示存储时 From 为另一工单
+func linkTypeFromAPI(t openapi.TicketLinkType) (typ dbpkg.TicketLinkType, reversed bool, ok bool) {
+	switch t {
+	case openapi.RELATED:
+		return dbpkg.TicketLinkRelated, false, true
+	case openapi.DUPLICATE_OF:
+		return dbpkg.TicketLinkDuplicateOf, false, true
+	case openapi.DUPLICATED_BY:
+		return dbpkg.TicketLinkDuplicateOf, true, true
+	case openapi.PARENT_OF:
+		return dbpkg.TicketLinkParentOf, false, true
+	case openapi.CHILD_OF:
+		return dbpkg.TicketLinkParentOf, true, true
+	case openapi.FOLLOW_UP_OF:
+		return dbpkg.TicketLinkFollowUpOf, false, true
+	case openapi.FOLLOWED_UP_BY:
+		return dbpkg.TicketLinkFollowUpOf, true, true
+	}
+	return "", false, false
+}
+
+// linkTypeToAPI 从 ticketID 的角度描述一条关联，返回另一工单 ID 与关联类型
+func linkTypeToAPI(l dbpkg.TicketLink, ticketID uint) (uint, openapi.TicketLinkType) {
+	outgoing := l.FromTicketID == ticketID
+	other := l.ToTicketID
+	if !outgoing {
+		other = l.FromTicketID
+	}
+	switch l.Type {
+	case dbpkg.TicketLinkDuplicateOf:
+		if outgoing {
+			return other, openapi.DUPLICATE_OF
+		}
+		return other, openapi.DUPLICATED_BY
+	case dbpkg.TicketLinkParentOf:
+		if outgoing {
+			return other, openapi.PARENT_OF
+		}
+		return other, openapi.CHILD_OF
+	case dbpkg.TicketLinkFollowUpOf:
+		if outgoing {
+			return other, openapi.FOLLOW_UP_OF
+		}
+		return other, openapi.FOLLOWED_UP_BY
+	}
+	return other, openapi.RELATED
+}
+
+// ListLinks 列出工单的关联（学生仅能看到关联到本人工单的部分）
+func (s *Service) ListLinks(currentUID, ticketID uint) ([]openapi.TicketLink, error) {
+	u, t, err := s.getTicketWithAccessCheck(currentUID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	return s.linksFor(u, t)
+}
+
+// AddLink 管理员为工单添加关联
+func (s *Service) AddLink(ctx context.Context, adminUID, ticketID uint, in openapi.TicketLinkCreate) (*openapi.TicketLink, error) {
+	typ, reversed, ok := linkTypeFromAPI(in.Type)
+	if !ok {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"type": "不支持的关联类型"}}
+	}
+	if in.TicketId <= 0 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"ticket_id": "必填"}}
+	}
+	otherID := uint(in.TicketId)
+	if otherID == ticketID {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"ticket_id": "不能关联到自身"}}
+	}
+
+	var created dbpkg.TicketLink
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var n int64
+		if err := tx.Model(&dbpkg.Ticket{}).Where("id IN ?", []uint{ticketID, otherID}).Count(&n).Error; err != nil {
+			return err
+		}
+		if n != 2 {
+			return &ErrNotFound{Resource: "ticket"}
+		}
+
+		from, to := ticketID, otherID
+		if reversed {
+			from, to = otherID, ticketID
+		}
+		l, err := s.insertLink(tx, from, to, typ, adminUID)
+		if err != nil {
+			return err
+		}
+		created = *l
+		return audit.Record(ctx, tx, adminUID, "ticket.link", "TICKET", ticketID, map[string]interface{}{
+			"link_id": l.ID, "type": in.Type, "ticket_id": otherID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var other dbpkg.Ticket
+	if err := s.db.Select("id", "title", "status").First(&other, otherID).Error; err != nil {
+		return nil, err
+	}
+	out := toAPILink(created, ticketID, &other)
+	return &out, nil
+}
+
+// RemoveLink 管理员删除工单的一条关联
+func (s *Service) RemoveLink(ctx context.Context, adminUID, ticketID, linkID uint) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var l dbpkg.TicketLink
+		if err := tx.Where("id = ? AND (from_ticket_id = ? OR to_ticket_id = ?)", linkID, ticketID, ticketID).
+			First(&l).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &ErrNotFound{Resource: "link"}
+			}
+			return err
+		}
+		if err := tx.Delete(&l).Error; err != nil {
+			return err
+		}
+		otherID, typ := linkTypeToAPI(l, ticketID)
+		return audit.Record(ctx, tx, adminUID, "ticket.unlink", "TICKET", ticketID, map[string]interface{}{
+			"link_id": l.ID, "type": typ, "ticket_id": otherID,
+		})
+	})
+}
+
+// CreateFollowUp 学生基于本人已关闭的工单创建后续工单，新工单自动关联为 FOLLOW_UP_OF；
+// 源工单为匿名工单时后续工单强制匿名，避免通过关联反推源工单的提交人
+func (s *Service) CreateFollowUp(ctx context.Context, currentUID, ticketID uint, in openapi.TicketCreate) (*openapi.Ticket, error) {
+	_, src, err := s.getTicketWithAccessCheck(currentUID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if src.UserID != currentUID {
+		return nil, &ErrForbidden{Reason: "only the creator can create a follow-up"}
+	}
+	if src.Status != dbpkg.TicketStatusClosed {
+		return nil, &ErrInvalidState{Message: fmt.Sprintf("仅 'CLOSED' 状态的工单可创建后续工单, 当前为 '%s'", src.Status)}
+	}
+	if src.IsAnonymous {
+		in.IsAnonymous = true
+	}
+
+	return s.createTicket(ctx, currentUID, in, func(tx *gorm.DB, t *dbpkg.Ticket) error {
+		l, err := s.insertLink(tx, t.ID, src.ID, dbpkg.TicketLinkFollowUpOf, currentUID)
+		if err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, currentUID, "ticket.link", "TICKET", t.ID, map[string]interface{}{
+			"link_id": l.ID, "type": openapi.FOLLOW_UP_OF, "ticket_id": src.ID,
+		})
+	})
+}
+
+// ---- 内部辅助 ----
+
+// insertLink 校验并写入一条关联（已按存储方向给出 from/to）
+func (s *Service) insertLink(tx *gorm.DB, from, to uint, typ dbpkg.TicketLinkType, actorID uint) (*dbpkg.TicketLink, error) {
+	if typ == dbpkg.TicketLinkRelated && from > to {
+		from, to = to, from
+	}
+
+	var n int64
+	if err := tx.Model(&dbpkg.TicketLink{}).
+		Where("from_ticket_id = ? AND to_ticket_id = ? AND type = ?", from, to, typ).
+		Count(&n).Error; err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		return nil, &ErrConflict{Message: "关联已存在"}
+	}
+
+	if typ == dbpkg.TicketLinkParentOf {
+		// 每个工单最多一个父工单
+		if err := tx.Model(&dbpkg.TicketLink{}).
+			Where("to_ticket_id = ? AND type = ?", to, dbpkg.TicketLinkParentOf).
+			Count(&n).Error; err != nil {
+			return nil, err
+		}
+		if n > 0 {
+			return nil, &ErrConflict{Message: "该工单已有父工单"}
+		}
+		// 防止成环：子工单不能是父工单的祖先
+		cyclic, err := isAncestor(tx, to, from)
+		if err != nil {
+			return nil, err
+		}
+		if cyclic {
+			return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"ticket_id": "父子关系不能成环"}}
+		}
+	}
+
+	l := &dbpkg.TicketLink{
+		FromTicketID: from,
+		ToTicketID:   to,
+		Type:         typ,
+		CreatedBy:    actorID,
+		CreatedAt:    time.Now().UTC().Truncate(time.Microsecond),
+	}
+	if err := tx.Create(l).Error; err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// isAncestor 判断 candidate 是否为 ticketID 自身或其祖先
+func isAncestor(tx *gorm.DB, candidate, ticketID uint) (bool, error) {
+	cur := ticketID
+	for i := 0; i < maxParentDepth; i++ {
+		if cur == candidate {
+			return true, nil
+		}
+		var parents []uint
+		if err := tx.Model(&dbpkg.TicketLink{}).
+			Where("to_ticket_id = ? AND type = ?", cur, dbpkg.TicketLinkParentOf).
+			Limit(1).
+			Pluck("from_ticket_id", &parents).Error; err != nil {
+			return false, err
+		}
+		if len(parents) == 0 {
+			return false, nil
+		}
+		cur = parents[0]
+	}
+	return false, nil
+}
+
+// linksFor 查询工单的全部关联并从该工单角度输出
+func (s *Service) linksFor(u *dbpkg.User, t *dbpkg.Ticket) ([]openapi.TicketLink, error) {
+	ticketID := t.ID
+	var rows []dbpkg.TicketLink
+	if err := s.db.Where("from_ticket_id = ? OR to_ticket_id = ?", ticketID, ticketID).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []openapi.TicketLink{}, nil
+	}
+
+	otherIDs := make([]uint, 0, len(rows))
+	for _, l := range rows {
+		id, _ := linkTypeToAPI(l, ticketID)
+		otherIDs = append(otherIDs, id)
+	}
+	var others []dbpkg.Ticket
+	if err := s.db.Select("id", "user_id", "title", "status", "is_anonymous").Where("id IN ?", otherIDs).Find(&others).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*dbpkg.Ticket, len(others))
+	// 匿名工单的创建者（如学生发起的跟进工单）不能出现在 created_by 中
+	hidden := map[uint]bool{}
+	if hidesCreator(u, t) {
+		hidden[t.UserID] = true
+	}
+	for i := range others {
+		byID[others[i].ID] = &others[i]
+		if hidesCreator(u, &others[i]) {
+			hidden[others[i].UserID] = true
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].CreatedAt.Equal(rows[j].CreatedAt) {
+			return rows[i].CreatedAt.Before(rows[j].CreatedAt)
+		}
+		return rows[i].ID < rows[j].ID
+	})
+	out := make([]openapi.TicketLink, 0, len(rows))
+	for _, l := range rows {
+		id, _ := linkTypeToAPI(l, ticketID)
+		other, ok := byID[id]
+		if !ok {
+			continue
+		}
+		// 学生看不到其他人的工单
+		if !isAdmin(u.Role) && other.UserID != u.ID {
+			continue
+		}
+		item := toAPILink(l, ticketID, other)
+		if hidden[l.CreatedBy] {
+			item.CreatedBy = 0
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+func toAPILink(l dbpkg.TicketLink, ticketID uint, other *dbpkg.Ticket) openapi.TicketLink {
+	otherID, typ := linkTypeToAPI(l, ticketID)
+	return openapi.TicketLink{
+		Id:           int32(l.ID),
+		Type:         typ,
+		TicketId:     int32(otherID),
+		TicketTitle:  other.Title,
+		TicketStatus: openapi.TicketStatus(other.Status),
+		CreatedBy:    int32(l.CreatedBy),
+		CreatedAt:    l.CreatedAt,
+	}
+}
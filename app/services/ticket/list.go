@@ -1,117 +1,399 @@
 package ticket
 
 import (
-    dbpkg "student-services-platform-backend/internal/db"
-    "student-services-platform-backend/internal/openapi"
+	"errors"
+	"strings"
+	"time"
 
-    "errors"
-    "gorm.io/gorm"
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+	"student-services-platform-backend/internal/pagination"
+
+	"gorm.io/gorm"
 )
 
-// ListFilters 保持不变
+// ListFilters 工单列表筛选条件（所有字段可选）
 type ListFilters struct {
-    Status       string // optional
-    Category     string // optional
-    IsUrgent     *bool  // optional
-    AssignedToMe *bool  // admin only
+	Statuses     []string // 多个状态取并集
+	Category     string
+	IsUrgent     *bool
+	Priorities   []string          // 多个优先级取并集
+	AssignedToMe *bool             // admin only
+	AssigneeID   *uint             // admin only
+	CreatorID    *uint             // admin only
+	Unassigned   *bool             // admin only：仅未分配负责人的工单
+	Tags         []string          // admin only：需同时带有全部标签
+	Keyword      string            // 标题/正文模糊匹配（不区分大小写）
+	Fields       map[string]string // 自定义字段精确匹配（key -> value）
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	UpdatedFrom  *time.Time
+	UpdatedTo    *time.Time
+	Sort         string // created_at（默认）| updated_at | urgency | priority | sla_due
+	Order        string // asc | desc；为空时使用排序键的默认方向
+}
+
+// ticketSort 排序键定义：排序列（最后一列必须唯一，用作 keyset 决胜）与默认方向
+type ticketSort struct {
+	cols        []string
+	defaultDesc bool
+	values      func(t *dbpkg.Ticket) []string
+	parse       func(v []string) ([]interface{}, error)
+}
+
+var ticketSorts = map[string]ticketSort{
+	"created_at": {
+		cols:        []string{"created_at", "id"},
+		defaultDesc: true,
+		values: func(t *dbpkg.Ticket) []string {
+			return []string{pagination.FormatTime(t.CreatedAt), pagination.FormatUint(t.ID)}
+		},
+		parse: parseTimeIDKeys,
+	},
+	"updated_at": {
+		cols:        []string{"updated_at", "id"},
+		defaultDesc: true,
+		values: func(t *dbpkg.Ticket) []string {
+			return []string{pagination.FormatTime(t.UpdatedAt), pagination.FormatUint(t.ID)}
+		},
+		parse: parseTimeIDKeys,
+	},
+	"urgency": {
+		cols:        []string{"is_urgent", "created_at", "id"},
+		defaultDesc: true,
+		values: func(t *dbpkg.Ticket) []string {
+			return []string{pagination.FormatBool(t.IsUrgent), pagination.FormatTime(t.CreatedAt), pagination.FormatUint(t.ID)}
+		},
+		parse: func(v []string) ([]interface{}, error) {
+			urgent, err := pagination.ParseBool(v[0])
+			if err != nil {
+				return nil, err
+			}
+			rest, err := parseTimeIDKeys(v[1:])
+			if err != nil {
+				return nil, err
+			}
+			return append([]interface{}{urgent}, rest...), nil
+		},
+	},
+	"priority": {
+		cols:        []string{"priority", "created_at", "id"},
+		defaultDesc: false, // P1 在前，同优先级先提交的在前
+		values: func(t *dbpkg.Ticket) []string {
+			return []string{string(t.Priority), pagination.FormatTime(t.CreatedAt), pagination.FormatUint(t.ID)}
+		},
+		parse: func(v []string) ([]interface{}, error) {
+			p := dbpkg.TicketPriority(v[0])
+			if !dbpkg.ValidTicketPriority(p) {
+				return nil, errors.New("invalid priority")
+			}
+			rest, err := parseTimeIDKeys(v[1:])
+			if err != nil {
+				return nil, err
+			}
+			return append([]interface{}{p}, rest...), nil
+		},
+	},
+	"sla_due": {
+		cols:        []string{"sla_due_at", "id"},
+		defaultDesc: false, // 最紧迫的排在前面
+		values: func(t *dbpkg.Ticket) []string {
+			due := t.CreatedAt
+			if t.SLADueAt != nil {
+				due = *t.SLADueAt
+			}
+			return []string{pagination.FormatTime(due), pagination.FormatUint(t.ID)}
+		},
+		parse: parseTimeIDKeys,
+	},
+}
+
+func parseTimeIDKeys(v []string) ([]interface{}, error) {
+	ts, err := pagination.ParseTime(v[0])
+	if err != nil {
+		return nil, err
+	}
+	id, err := pagination.ParseUint(v[1])
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{ts, id}, nil
+}
+
+// validTicketStatuses 可用于筛选的状态
+var validTicketStatuses = map[dbpkg.TicketStatus]bool{
+	dbpkg.TicketStatusNew:           true,
+	dbpkg.TicketStatusClaimed:       true,
+	dbpkg.TicketStatusInProgress:    true,
+	dbpkg.TicketStatusResolved:      true,
+	dbpkg.TicketStatusClosed:        true,
+	dbpkg.TicketStatusSpamPending:   true,
+	dbpkg.TicketStatusSpamConfirmed: true,
+	dbpkg.TicketStatusSpamRejected:  true,
+	dbpkg.TicketStatusCancelled:     true,
+	dbpkg.TicketStatusMerged:        true,
+}
+
+// applyTicketFilters 在查询上叠加可见性与筛选条件。
+// 学生只能看到自己的工单，且仅管理员可用的筛选项对学生无效。
+func (s *Service) applyTicketFilters(q *gorm.DB, u *dbpkg.User, f ListFilters) (*gorm.DB, error) {
+	details := map[string]interface{}{}
+
+	if !isAdmin(u.Role) {
+		q = q.Where("user_id = ?", u.ID)
+	} else {
+		if f.AssignedToMe != nil && *f.AssignedToMe {
+			q = q.Where("assigned_admin_id = ?", u.ID)
+		}
+		if f.AssigneeID != nil {
+			q = q.Where("assigned_admin_id = ?", *f.AssigneeID)
+		}
+		if f.CreatorID != nil {
+			// 匿名工单不参与按创建者筛选，避免借此推断身份
+			q = q.Where("user_id = ? AND is_anonymous = ?", *f.CreatorID, false)
+		}
+		if f.Unassigned != nil && *f.Unassigned {
+			q = q.Where("assigned_admin_id IS NULL")
+		}
+		if len(f.Tags) > 0 {
+			names, tagDetails := normalizeTagNames(f.Tags)
+			for k, v := range tagDetails {
+				details[k] = v
+			}
+			if len(names) > 0 {
+				q = q.Where("id IN (?)", s.tagFilterSubquery(names))
+			}
+		}
+	}
+
+	if len(f.Statuses) > 0 {
+		statuses := make([]dbpkg.TicketStatus, 0, len(f.Statuses))
+		for _, st := range f.Statuses {
+			ts := dbpkg.TicketStatus(strings.ToUpper(strings.TrimSpace(st)))
+			if !validTicketStatuses[ts] {
+				details["status"] = "未知状态: " + st
+				break
+			}
+			statuses = append(statuses, ts)
+		}
+		q = q.Where("status IN ?", statuses)
+	}
+	if f.Category != "" {
+		q = q.Where("category = ?", f.Category)
+	}
+	if f.IsUrgent != nil {
+		q = q.Where("is_urgent = ?", *f.IsUrgent)
+	}
+	if len(f.Priorities) > 0 {
+		priorities := make([]dbpkg.TicketPriority, 0, len(f.Priorities))
+		for _, p := range f.Priorities {
+			tp := dbpkg.TicketPriority(strings.ToUpper(strings.TrimSpace(p)))
+			if !dbpkg.ValidTicketPriority(tp) {
+				details["priority"] = "未知优先级: " + p
+				break
+			}
+			priorities = append(priorities, tp)
+		}
+		q = q.Where("priority IN ?", priorities)
+	}
+	for k, v := range f.Fields {
+		if !dbpkg.ValidCategoryFieldKey(k) {
+			details["field."+k] = "无效的字段键"
+			continue
+		}
+		q = q.Where("id IN (?)", s.fieldFilterSubquery(k, strings.TrimSpace(v)))
+	}
+	if kw := strings.TrimSpace(f.Keyword); kw != "" {
+		if len([]rune(kw)) > 100 {
+			details["keyword"] = "长度不能超过 100"
+		}
+		pattern := "%" + escapeLike(strings.ToLower(kw)) + "%"
+		q = q.Where("(LOWER(title) LIKE ? ESCAPE '!' OR LOWER(content) LIKE ? ESCAPE '!')", pattern, pattern)
+	}
+
+	if f.CreatedFrom != nil && f.CreatedTo != nil && f.CreatedTo.Before(*f.CreatedFrom) {
+		details["created_to"] = "不能早于 created_from"
+	}
+	if f.UpdatedFrom != nil && f.UpdatedTo != nil && f.UpdatedTo.Before(*f.UpdatedFrom) {
+		details["updated_to"] = "不能早于 updated_from"
+	}
+	if f.CreatedFrom != nil {
+		q = q.Where("created_at >= ?", f.CreatedFrom.UTC())
+	}
+	if f.CreatedTo != nil {
+		q = q.Where("created_at < ?", f.CreatedTo.UTC())
+	}
+	if f.UpdatedFrom != nil {
+		q = q.Where("updated_at >= ?", f.UpdatedFrom.UTC())
+	}
+	if f.UpdatedTo != nil {
+		q = q.Where("updated_at < ?", f.UpdatedTo.UTC())
+	}
+
+	if len(details) > 0 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: details}
+	}
+	return q, nil
 }
 
-// ListTickets 根据角色与筛选返回分页工单
-func (s *Service) ListTickets(currentUID uint, f ListFilters, page, pageSize int) (*openapi.PagedTickets, error) {
-    // 1) 鉴权：拿当前用户
-    u, err := s.currentUser(s.db, currentUID)
-    if err != nil {
-        if errors.Is(err, gorm.ErrRecordNotFound) {
-            return nil, &ErrForbidden{Reason: "user not found"}
-        }
-        return nil, err
-    }
-
-    // 2) 组装查询（权限 + 过滤）
-    q := s.db.Model(&dbpkg.Ticket{})
-
-    // 学生：仅看自己的
-    if !isAdmin(u.Role) {
-        q = q.Where("user_id = ?", currentUID)
-    } else {
-        // 管理员：可筛选"我负责的"
-        if f.AssignedToMe != nil && *f.AssignedToMe {
-            q = q.Where("assigned_admin_id = ?", currentUID)
-        }
-    }
-
-    if f.Status != "" {
-        q = q.Where("status = ?", dbpkg.TicketStatus(f.Status))
-    }
-    if f.Category != "" {
-        q = q.Where("category = ?", f.Category)
-    }
-    if f.IsUrgent != nil {
-        q = q.Where("is_urgent = ?", *f.IsUrgent)
-    }
-
-    // 3) 统计总数
-    var total int64
-    if err := q.Count(&total).Error; err != nil {
-        return nil, err
-    }
-
-    // 4) 分页参数
-    if page < 1 {
-        page = 1
-    }
-    if pageSize < 1 {
-        pageSize = 20
-    }
-    offset := (page - 1) * pageSize
-
-    // 5) 拉取本页工单
-    var rows []dbpkg.Ticket
-    if err := q.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&rows).Error; err != nil {
-        return nil, err
-    }
-
-    // 一次性批量查询本页工单的图片关系，避免 N+1
-    ticketIDs := make([]uint, 0, len(rows))
-    for _, t := range rows {
-        ticketIDs = append(ticketIDs, t.ID)
-    }
-
-    imagesMap, err := dbpkg.GetTicketImagesMap(s.db, ticketIDs)
-    if err != nil {
-        return nil, err
-    }
-
-    // 6) 组装返回体（内存回填图片 ID）
-    items := make([]openapi.Ticket, 0, len(rows))
-    for _, t := range rows {
-        imgIDs := imagesMap[t.ID] // 可能为 nil，后面做零值处理
-        img32 := make([]int32, 0, len(imgIDs))
-        for _, id := range imgIDs {
-            img32 = append(img32, int32(id))
-        }
-        items = append(items, openapi.Ticket{
-            Id:              int32(t.ID),
-            UserId:          int32(t.UserID),
-            Title:           t.Title,
-            Content:         t.Content,
-            Category:        t.Category,
-            IsUrgent:        t.IsUrgent,
-            IsAnonymous:     t.IsAnonymous,
-            Status:          openapi.TicketStatus(t.Status),
-            AssignedAdminId: toPtrInt32FromUintPtr(t.AssignedAdminID),
-            ClaimedAt:       t.ClaimedAt,
-            CreatedAt:       t.CreatedAt,
-            UpdatedAt:       t.UpdatedAt,
-            ImageIds:        img32,
-        })
-    }
-
-    // 7) 返回分页数据
-    return &openapi.PagedTickets{
-        Items:    items,
-        Page:     int32(page),
-        PageSize: int32(pageSize),
-        Total:    int32(total),
-    }, nil
-}
\ No newline at end of file
+// escapeLike 转义 LIKE 通配符（配合 ESCAPE '!' 使用，兼容各数据库）
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+// resolveTicketSort 解析排序键与方向；未指定排序键时管理员按优先级、学生按创建时间排序
+func resolveTicketSort(f ListFilters, admin bool) (string, ticketSort, bool, error) {
+	key := f.Sort
+	if key == "" {
+		key = "created_at"
+		if admin {
+			key = "priority"
+		}
+	}
+	spec, ok := ticketSorts[key]
+	if !ok {
+		return "", ticketSort{}, false, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"sort": "必须为 created_at、updated_at、urgency、priority 或 sla_due"}}
+	}
+	desc := spec.defaultDesc
+	switch strings.ToLower(f.Order) {
+	case "":
+	case "asc":
+		desc = false
+	case "desc":
+		desc = true
+	default:
+		return "", ticketSort{}, false, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"order": "必须为 asc 或 desc"}}
+	}
+	return key, spec, desc, nil
+}
+
+// ListTickets 根据角色与筛选返回工单列表；支持 page/page_size 与游标两种分页方式
+func (s *Service) ListTickets(currentUID uint, f ListFilters, pg pagination.Params) (*openapi.PagedTickets, error) {
+	// 1) 鉴权：拿当前用户
+	u, err := s.currentUser(s.db, currentUID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &ErrForbidden{Reason: "user not found"}
+		}
+		return nil, err
+	}
+
+	// 2) 组装查询（权限 + 过滤）
+	q, err := s.applyTicketFilters(s.db.Model(&dbpkg.Ticket{}), u, f)
+	if err != nil {
+		return nil, err
+	}
+	sortKey, spec, desc, err := resolveTicketSort(f, isAdmin(u.Role))
+	if err != nil {
+		return nil, err
+	}
+	// 游标需同时记录方向，避免换方向后误用
+	cursorSort := sortKey + ":asc"
+	if desc {
+		cursorSort = sortKey + ":desc"
+	}
+
+	pg = pg.Normalize()
+	out := &openapi.PagedTickets{}
+	var rows []dbpkg.Ticket
+
+	if pg.UseCursor {
+		// 3a) 游标分页：不统计总数，多取一行判断是否还有下一页
+		if pg.Cursor != "" {
+			c, err := pagination.Decode(pg.Cursor, cursorSort, len(spec.cols))
+			if err != nil {
+				return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"cursor": "无效的游标"}}
+			}
+			vals, err := spec.parse(c.Values)
+			if err != nil {
+				return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"cursor": "无效的游标"}}
+			}
+			cond, args := pagination.KeysetWhere(spec.cols, desc, vals)
+			q = q.Where(cond, args...)
+		}
+		if err := q.Order(pagination.OrderBy(spec.cols, desc)).Limit(pg.PageSize + 1).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		if len(rows) > pg.PageSize {
+			rows = rows[:pg.PageSize]
+			out.NextCursor = pagination.Encode(pagination.Cursor{Sort: cursorSort, Values: spec.values(&rows[len(rows)-1])})
+		}
+		out.PageSize = int32(pg.PageSize)
+	} else {
+		// 3b) 页码分页
+		var total int64
+		if err := q.Count(&total).Error; err != nil {
+			return nil, err
+		}
+		offset := (pg.Page - 1) * pg.PageSize
+		if err := q.Order(pagination.OrderBy(spec.cols, desc)).Offset(offset).Limit(pg.PageSize).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		out.Page = int32(pg.Page)
+		out.PageSize = int32(pg.PageSize)
+		out.Total = int32(total)
+	}
+
+	// 4) 一次性批量查询本页工单的图片关系，避免 N+1
+	ticketIDs := make([]uint, 0, len(rows))
+	for _, t := range rows {
+		ticketIDs = append(ticketIDs, t.ID)
+	}
+	imagesMap, err := dbpkg.GetTicketImagesMap(s.db, ticketIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 标签仅管理员可见
+	var tagsMap map[uint][]string
+	if isAdmin(u.Role) {
+		if tagsMap, err = dbpkg.GetTicketTagsMap(s.db, ticketIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	unread, err := dbpkg.CountUnreadByTicket(s.db, currentUID, ticketIDs, isAdmin(u.Role))
+	if err != nil {
+		return nil, err
+	}
+
+	// 5) 组装返回体
+	out.Items = make([]openapi.Ticket, 0, len(rows))
+	for i := range rows {
+		item := toAPITicket(&rows[i], imagesMap[rows[i].ID])
+		item.Tags = tagsMap[rows[i].ID]
+		item.UnreadCount = int32(unread[rows[i].ID])
+		if hidesCreator(u, &rows[i]) {
+			redactTicketCreator(&item)
+		}
+		out.Items = append(out.Items, item)
+	}
+	return out, nil
+}
+
+// ValidateListFilters 校验筛选与排序条件（用于保存视图等场景，不访问数据库）
+func (s *Service) ValidateListFilters(f ListFilters) error {
+	if _, err := s.applyTicketFilters(s.db.Session(&gorm.Session{DryRun: true}), &dbpkg.User{Role: dbpkg.RoleAdmin}, f); err != nil {
+		return err
+	}
+	_, _, _, err := resolveTicketSort(f, true)
+	return err
+}
+
+// CountTickets 统计当前用户在给定筛选下可见的工单数量
+func (s *Service) CountTickets(currentUID uint, f ListFilters) (int64, error) {
+	u, err := s.currentUser(s.db, currentUID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, &ErrForbidden{Reason: "user not found"}
+		}
+		return 0, err
+	}
+	q, err := s.applyTicketFilters(s.db.Model(&dbpkg.Ticket{}), u, f)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	err = q.Count(&n).Error
+	return n, err
+}
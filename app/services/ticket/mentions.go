@@ -0,0 +1,235 @@
+package ticket
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+	"student-services-platform-backend/internal/pagination"
+
+	"gorm.io/gorm"
+)
+
+// mentionExcerptLen 提及收件箱中备注摘要的最大字符数
+const mentionExcerptLen = 200
+
+// parseMentions 解析正文中的 @ 提及，按出现顺序返回被提及的管理员 ID（去重）。
+// 中文姓名后常不留空格，因此按最长前缀匹配管理员姓名或邮箱；
+// 以 ASCII 字母数字结尾的名称要求其后不能紧跟字母或数字。重名的管理员无法通过姓名提及。
+func parseMentions(body string, admins []dbpkg.User) []uint {
+	keys := map[string]uint{}
+	add := func(k string, id uint) {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k == "" {
+			return
+		}
+		if prev, ok := keys[k]; ok && prev != id {
+			keys[k] = 0 // 有歧义
+			return
+		}
+		keys[k] = id
+	}
+	for _, a := range admins {
+		add(a.Name, a.ID)
+		add(a.Email, a.ID)
+	}
+
+	text := []rune(strings.ToLower(body))
+	seen := map[uint]bool{}
+	var out []uint
+	for i, r := range text {
+		if r != '@' || (i > 0 && isASCIIWord(text[i-1])) {
+			continue // 跳过 foo@bar.com 这类邮箱地址
+		}
+		rest := string(text[i+1:])
+		best, bestID := 0, uint(0)
+		for k, id := range keys {
+			if len(k) <= best || !strings.HasPrefix(rest, k) {
+				continue
+			}
+			if next := []rune(rest[len(k):]); len(next) > 0 {
+				last := []rune(k)
+				if isASCIIWord(last[len(last)-1]) && isASCIIWord(next[0]) {
+					continue
+				}
+			}
+			best, bestID = len(k), id
+		}
+		if bestID != 0 && !seen[bestID] {
+			seen[bestID] = true
+			out = append(out, bestID)
+		}
+	}
+	return out
+}
+
+func isASCIIWord(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// recordMentions 解析内部备注中的提及并写入提及记录（不包含作者本人与已记录过的管理员），
+// 返回新被提及的管理员 ID
+func recordMentions(tx *gorm.DB, m *dbpkg.TicketMessage) ([]uint, error) {
+	if !strings.Contains(m.Body, "@") {
+		return nil, nil
+	}
+	var existing []uint
+	if err := tx.Model(&dbpkg.TicketMention{}).
+		Where("message_id = ?", m.ID).
+		Pluck("mentioned_user_id", &existing).Error; err != nil {
+		return nil, err
+	}
+	var admins []dbpkg.User
+	if err := tx.Select("id", "name", "email").
+		Where("role IN ? AND is_active = ?", []dbpkg.Role{dbpkg.RoleAdmin, dbpkg.RoleSuperAdmin}, true).
+		Find(&admins).Error; err != nil {
+		return nil, err
+	}
+	var ids []uint
+	var rows []dbpkg.TicketMention
+	for _, id := range parseMentions(m.Body, admins) {
+		if id == m.SenderUserID || slices.Contains(existing, id) {
+			continue
+		}
+		ids = append(ids, id)
+		rows = append(rows, dbpkg.TicketMention{
+			TicketID:        m.TicketID,
+			MessageID:       m.ID,
+			MentionedUserID: id,
+			MentionedBy:     m.SenderUserID,
+			CreatedAt:       m.CreatedAt,
+		})
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return ids, tx.Create(&rows).Error
+}
+
+// mentionsByMessage 批量加载消息中被提及的管理员 ID
+func mentionsByMessage(d *gorm.DB, messageIDs []uint) (map[uint][]int32, error) {
+	out := map[uint][]int32{}
+	if len(messageIDs) == 0 {
+		return out, nil
+	}
+	var rows []dbpkg.TicketMention
+	if err := d.Where("message_id IN ?", messageIDs).Order("id ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		out[r.MessageID] = append(out[r.MessageID], int32(r.MentionedUserID))
+	}
+	return out, nil
+}
+
+// notifyMentioned 向被提及且允许邮件提醒的管理员发送通知
+func (s *Service) notifyMentioned(t *dbpkg.Ticket, senderName, body string, userIDs []uint) {
+	var emails []string
+	if err := s.db.Model(&dbpkg.User{}).
+		Where("id IN ? AND is_active = ? AND allow_email = ? AND email <> ''", userIDs, true, true).
+		Order("id ASC").
+		Pluck("email", &emails).Error; err != nil || len(emails) == 0 {
+		return
+	}
+	s.notifier.NotifyMentioned(notifyCtx(t.Priority), t.ID, t.Title, senderName, body, emails)
+}
+
+// ListMentions 当前管理员的提及收件箱（按时间倒序）
+func (s *Service) ListMentions(currentUID uint, unreadOnly bool, pg pagination.Params) (*openapi.PagedMentions, error) {
+	pg = pg.Normalize()
+	base := s.db.Model(&dbpkg.TicketMention{}).Where("mentioned_user_id = ?", currentUID)
+
+	var unread int64
+	if err := base.Session(&gorm.Session{}).Where("read_at IS NULL").Count(&unread).Error; err != nil {
+		return nil, err
+	}
+
+	q := base.Session(&gorm.Session{})
+	if unreadOnly {
+		q = q.Where("read_at IS NULL")
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, err
+	}
+	var rows []dbpkg.TicketMention
+	if err := q.Order("created_at DESC, id DESC").
+		Offset((pg.Page - 1) * pg.PageSize).
+		Limit(pg.PageSize).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	var ticketIDs, messageIDs, userIDs []uint
+	for _, r := range rows {
+		ticketIDs = append(ticketIDs, r.TicketID)
+		messageIDs = append(messageIDs, r.MessageID)
+		userIDs = append(userIDs, r.MentionedBy)
+	}
+	titles := map[uint]string{}
+	bodies := map[uint]string{}
+	names := map[uint]string{}
+	if len(rows) > 0 {
+		var tickets []dbpkg.Ticket
+		if err := s.db.Select("id", "title").Where("id IN ?", ticketIDs).Find(&tickets).Error; err != nil {
+			return nil, err
+		}
+		for _, t := range tickets {
+			titles[t.ID] = t.Title
+		}
+		var msgs []dbpkg.TicketMessage
+		if err := s.db.Select("id", "body").Where("id IN ? AND deleted_at IS NULL", messageIDs).Find(&msgs).Error; err != nil {
+			return nil, err
+		}
+		for _, m := range msgs {
+			bodies[m.ID] = m.Body
+		}
+		var users []dbpkg.User
+		if err := s.db.Select("id", "name").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			names[u.ID] = u.Name
+		}
+	}
+
+	out := &openapi.PagedMentions{
+		Items:       make([]openapi.Mention, 0, len(rows)),
+		Page:        int32(pg.Page),
+		PageSize:    int32(pg.PageSize),
+		Total:       int32(total),
+		UnreadCount: int32(unread),
+	}
+	for _, r := range rows {
+		excerpt := []rune(bodies[r.MessageID])
+		if len(excerpt) > mentionExcerptLen {
+			excerpt = excerpt[:mentionExcerptLen]
+		}
+		out.Items = append(out.Items, openapi.Mention{
+			Id:              int32(r.ID),
+			TicketId:        int32(r.TicketID),
+			TicketTitle:     titles[r.TicketID],
+			MessageId:       int32(r.MessageID),
+			Excerpt:         string(excerpt),
+			MentionedBy:     int32(r.MentionedBy),
+			MentionedByName: names[r.MentionedBy],
+			CreatedAt:       r.CreatedAt,
+			ReadAt:          r.ReadAt,
+		})
+	}
+	return out, nil
+}
+
+// MarkMentionsRead 将当前管理员的提及标记为已读；ids 为空时全部标记
+func (s *Service) MarkMentionsRead(ctx context.Context, currentUID uint, ids []uint) error {
+	q := s.db.WithContext(ctx).Model(&dbpkg.TicketMention{}).
+		Where("mentioned_user_id = ? AND read_at IS NULL", currentUID)
+	if len(ids) > 0 {
+		q = q.Where("id IN ?", ids)
+	}
+	return q.Update("read_at", time.Now().UTC().Truncate(time.Microsecond)).Error
+}
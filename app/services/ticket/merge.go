@@ -0,0 +1,368 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// mergeableSourceStatuses 可被合并的源工单状态（尚未处理完成）
+var mergeableSourceStatuses = []dbpkg.TicketStatus{
+	dbpkg.TicketStatusNew,
+	dbpkg.TicketStatusClaimed,
+	dbpkg.TicketStatusInProgress,
+}
+
+// mergeTargetBlocked 不能作为合并目标的状态
+var mergeTargetBlocked = map[dbpkg.TicketStatus]bool{
+	dbpkg.TicketStatusCancelled:     true,
+	dbpkg.TicketStatusMerged:        true,
+	dbpkg.TicketStatusSpamPending:   true,
+	dbpkg.TicketStatusSpamConfirmed: true,
+}
+
+// MergeTicket 将重复工单 sourceID 合并到 targetID（负责人、未分配工单的任意管理员或超管）：
+// 消息、图片、关注者、标签、自定义字段、关联与事件归属迁移到目标工单，源工单标记为 MERGED 并指向目标，随后通知学生。
+// 仅允许合并同一学生提交、且匿名属性相同的工单，避免把一个学生的对话暴露给另一个学生或把匿名对话挂到实名下；
+// 不满足时统一返回同一个校验错误，不透露两张工单的提交人是否相同。匿名工单之间的合并仅限超级管理员，
+// 否则普通管理员可以借合并是否成功判断两张匿名工单是否出自同一学生。
+func (s *Service) MergeTicket(ctx context.Context, adminUID, sourceID, targetID uint) error {
+	if sourceID == targetID {
+		return &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"target_id": "不能合并到自身"}}
+	}
+
+	var src, dst dbpkg.Ticket
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var u dbpkg.User
+		if err := tx.Select("role").First(&u, adminUID).Error; err != nil {
+			return &ErrForbidden{Reason: "user not found"}
+		}
+		for _, p := range []struct {
+			id uint
+			t  *dbpkg.Ticket
+		}{{sourceID, &src}, {targetID, &dst}} {
+			if err := tx.First(p.t, p.id).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return &ErrNotFound{Resource: "ticket"}
+				}
+				return err
+			}
+		}
+
+		if !isSuperAdmin(u.Role) && src.AssignedAdminID != nil && *src.AssignedAdminID != adminUID {
+			return &ErrForbidden{Reason: "只有负责人或超级管理员可以合并该工单"}
+		}
+		if src.IsAnonymous && dst.IsAnonymous && !isSuperAdmin(u.Role) {
+			return &ErrForbidden{Reason: "匿名工单仅超级管理员可以合并"}
+		}
+		if src.UserID != dst.UserID || src.IsAnonymous != dst.IsAnonymous {
+			return &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"target_id": "该工单不能作为合并目标"}}
+		}
+		if mergeTargetBlocked[dst.Status] {
+			return &ErrInvalidState{Message: fmt.Sprintf("目标工单状态为 '%s'，不能作为合并目标", dst.Status)}
+		}
+
+		// 1) 源工单 -> MERGED（CAS）
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		res := tx.Model(&dbpkg.Ticket{}).
+			Where("id = ? AND status IN ?", sourceID, mergeableSourceStatuses).
+			Updates(map[string]interface{}{
+				"status":                dbpkg.TicketStatusMerged,
+				"merged_into_ticket_id": targetID,
+				"updated_at":            now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return &ErrInvalidState{Message: fmt.Sprintf("仅 NEW/CLAIMED/IN_PROGRESS 状态的工单可被合并, 当前为 '%s'", src.Status)}
+		}
+
+		// 2) 迁移消息
+		moved := tx.Model(&dbpkg.TicketMessage{}).
+			Where("ticket_id = ?", sourceID).
+			Update("ticket_id", targetID)
+		if moved.Error != nil {
+			return moved.Error
+		}
+		if err := tx.Model(&dbpkg.TicketMention{}).
+			Where("ticket_id = ?", sourceID).
+			Update("ticket_id", targetID).Error; err != nil {
+			return err
+		}
+
+		// 3) 迁移图片关联（目标已有的忽略）
+		imgIDs, err := dbpkg.GetTicketImageIDs(tx, sourceID)
+		if err != nil {
+			return err
+		}
+		if err := dbpkg.LinkTicketImages(tx, targetID, imgIDs); err != nil {
+			return err
+		}
+		if err := tx.Where("ticket_id = ?", sourceID).Delete(&dbpkg.TicketImage{}).Error; err != nil {
+			return err
+		}
+
+		// 源工单的关注者同时关注目标工单
+		watchers, err := dbpkg.ListTicketWatchers(tx, sourceID)
+		if err != nil {
+			return err
+		}
+		for _, w := range watchers {
+			if _, err := dbpkg.AddTicketWatcher(tx, targetID, w.UserID, w.AddedBy, w.Source, now); err != nil {
+				return err
+			}
+		}
+
+		// 4) 迁移标签、自定义字段、关联与事件归属；无法迁移的保留在源工单并记入审计
+		carried, err := mergeTicketMeta(tx, &src, &dst)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&dbpkg.Ticket{}).Where("id = ?", targetID).Update("updated_at", now).Error; err != nil {
+			return err
+		}
+
+		// 5) 审计：源与目标各记一条，便于两边的历史都能看到
+		// （源工单此前的审计日志仍归属源工单，目标工单的时间线会一并列出）
+		diff := map[string]interface{}{
+			"status_from":    src.Status,
+			"status_to":      dbpkg.TicketStatusMerged,
+			"merged_into":    targetID,
+			"moved_messages": moved.RowsAffected,
+			"moved_images":   imgIDs,
+		}
+		for k, v := range carried {
+			diff[k] = v
+		}
+		if err := audit.Record(ctx, tx, adminUID, "ticket.merge", "TICKET", sourceID, diff); err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, adminUID, "ticket.merge_in", "TICKET", targetID, map[string]interface{}{"merged_from": sourceID})
+	})
+	if err != nil {
+		return err
+	}
+
+	// 消息迁移后两边的检索文档都需要重建
+	s.reindex(sourceID)
+	s.reindex(targetID)
+
+	if s.notifier != nil {
+		go func(src, dst dbpkg.Ticket) {
+			var creator dbpkg.User
+			if err := s.db.First(&creator, src.UserID).Error; err != nil {
+				return // 静默失败，不影响主流程
+			}
+			s.notifier.NotifyTicketMerged(
+				notifyCtx(src.Priority),
+				src.ID,
+				dst.ID,
+				src.Title,
+				dst.Title,
+				creator.Email,
+			)
+		}(src, dst)
+	}
+
+	return nil
+}
+
+// mergeTicketMeta 将源工单的标签、自定义字段值、工单关联与事件归属迁移到目标工单，
+// 返回需要写入合并审计的迁移结果（仅包含非空项）：
+//   - 标签：目标已有的忽略
+//   - 自定义字段：仅迁移属于目标工单分类、且目标尚未填写的字段，其余保留在源工单
+//   - 关联：改为指向目标；与目标之间的关联及目标已有的重复关联删除，违反父子约束的保留在源工单
+//   - 事件：目标未归属事件时随之归属，已归属其他事件时源工单保持原归属
+func mergeTicketMeta(tx *gorm.DB, src, dst *dbpkg.Ticket) (map[string]interface{}, error) {
+	out := map[string]interface{}{}
+
+	var tags []dbpkg.TicketTag
+	if err := tx.Where("ticket_id = ?", src.ID).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	if len(tags) > 0 {
+		tagIDs := make([]uint, 0, len(tags))
+		for i := range tags {
+			tagIDs = append(tagIDs, tags[i].TagID)
+			tags[i].TicketID = dst.ID
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Where("ticket_id = ?", src.ID).Delete(&dbpkg.TicketTag{}).Error; err != nil {
+			return nil, err
+		}
+		out["moved_tags"] = tagIDs
+	}
+
+	movedFields, keptFields, err := mergeFieldValues(tx, src, dst)
+	if err != nil {
+		return nil, err
+	}
+	if len(movedFields) > 0 {
+		out["moved_field_values"] = movedFields
+	}
+	if len(keptFields) > 0 {
+		out["kept_field_values"] = keptFields
+	}
+
+	var links []dbpkg.TicketLink
+	if err := tx.Where("from_ticket_id = ? OR to_ticket_id = ?", src.ID, src.ID).Order("id ASC").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	var movedLinks, droppedLinks, keptLinks []uint
+	for _, l := range links {
+		res, err := relinkForMerge(tx, l, src.ID, dst.ID)
+		if err != nil {
+			return nil, err
+		}
+		switch res {
+		case relinkMoved:
+			movedLinks = append(movedLinks, l.ID)
+		case relinkDropped:
+			droppedLinks = append(droppedLinks, l.ID)
+		default:
+			keptLinks = append(keptLinks, l.ID)
+		}
+	}
+	for k, v := range map[string][]uint{"moved_links": movedLinks, "dropped_links": droppedLinks, "kept_links": keptLinks} {
+		if len(v) > 0 {
+			out[k] = v
+		}
+	}
+
+	var srcIncident []dbpkg.IncidentTicket
+	if err := tx.Where("ticket_id = ?", src.ID).Limit(1).Find(&srcIncident).Error; err != nil {
+		return nil, err
+	}
+	if len(srcIncident) == 1 {
+		it := srcIncident[0]
+		var n int64
+		if err := tx.Model(&dbpkg.IncidentTicket{}).Where("ticket_id = ?", dst.ID).Count(&n).Error; err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			if err := tx.Where("ticket_id = ?", src.ID).Delete(&dbpkg.IncidentTicket{}).Error; err != nil {
+				return nil, err
+			}
+			it.TicketID = dst.ID
+			if err := tx.Create(&it).Error; err != nil {
+				return nil, err
+			}
+			out["moved_incident"] = it.IncidentID
+		} else {
+			out["kept_incident"] = it.IncidentID
+		}
+	}
+	return out, nil
+}
+
+// mergeFieldValues 迁移源工单中属于目标工单分类、且目标尚未填写的自定义字段值，返回迁移与保留的字段 ID
+func mergeFieldValues(tx *gorm.DB, src, dst *dbpkg.Ticket) (moved, kept []uint, err error) {
+	var values []dbpkg.TicketFieldValue
+	if err := tx.Where("ticket_id = ?", src.ID).Order("field_id ASC").Find(&values).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(values) == 0 {
+		return nil, nil, nil
+	}
+
+	allowed := map[uint]bool{}
+	if dst.CategoryID != nil {
+		var ids []uint
+		if err := tx.Model(&dbpkg.CategoryField{}).Where("category_id = ?", *dst.CategoryID).Pluck("id", &ids).Error; err != nil {
+			return nil, nil, err
+		}
+		for _, id := range ids {
+			allowed[id] = true
+		}
+		var filled []uint
+		if err := tx.Model(&dbpkg.TicketFieldValue{}).Where("ticket_id = ?", dst.ID).Pluck("field_id", &filled).Error; err != nil {
+			return nil, nil, err
+		}
+		for _, id := range filled {
+			delete(allowed, id)
+		}
+	}
+
+	for _, v := range values {
+		if !allowed[v.FieldID] {
+			kept = append(kept, v.FieldID)
+			continue
+		}
+		if err := tx.Model(&dbpkg.TicketFieldValue{}).
+			Where("ticket_id = ? AND field_id = ?", src.ID, v.FieldID).
+			Update("ticket_id", dst.ID).Error; err != nil {
+			return nil, nil, err
+		}
+		moved = append(moved, v.FieldID)
+	}
+	return moved, kept, nil
+}
+
+// relinkForMerge 的处理结果
+const (
+	relinkMoved   = iota // 改为指向目标工单
+	relinkDropped        // 已删除（与目标之间的关联或重复关联）
+	relinkKept           // 违反父子约束，保留在源工单
+)
+
+// relinkForMerge 将源工单的一条关联改为指向目标工单
+func relinkForMerge(tx *gorm.DB, l dbpkg.TicketLink, sourceID, targetID uint) (int, error) {
+	from, to := l.FromTicketID, l.ToTicketID
+	if from == sourceID {
+		from = targetID
+	}
+	if to == sourceID {
+		to = targetID
+	}
+	if from == to {
+		// 源与目标之间的关联已由合并本身取代
+		return relinkDropped, tx.Delete(&l).Error
+	}
+	if l.Type == dbpkg.TicketLinkRelated && from > to {
+		from, to = to, from
+	}
+
+	var n int64
+	if err := tx.Model(&dbpkg.TicketLink{}).
+		Where("from_ticket_id = ? AND to_ticket_id = ? AND type = ? AND id <> ?", from, to, l.Type, l.ID).
+		Count(&n).Error; err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		return relinkDropped, tx.Delete(&l).Error
+	}
+
+	if l.Type == dbpkg.TicketLinkParentOf {
+		// 每个工单最多一个父工单，且父子关系不能成环
+		if err := tx.Model(&dbpkg.TicketLink{}).
+			Where("to_ticket_id = ? AND type = ? AND id <> ?", to, dbpkg.TicketLinkParentOf, l.ID).
+			Count(&n).Error; err != nil {
+			return 0, err
+		}
+		if n > 0 {
+			return relinkKept, nil
+		}
+		cyclic, err := isAncestor(tx, to, from)
+		if err != nil {
+			return 0, err
+		}
+		if cyclic {
+			return relinkKept, nil
+		}
+	}
+
+	err := tx.Model(&dbpkg.TicketLink{}).Where("id = ?", l.ID).
+		Updates(map[string]interface{}{"from_ticket_id": from, "to_ticket_id": to}).Error
+	return relinkMoved, err
+}
@@ -0,0 +1,338 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+
+	"gorm.io/gorm"
+)
+
+// defaultMessageEditWindow 发送者可编辑消息的默认时限（自发送起），可通过 ticket.message_edit_window 配置
+const defaultMessageEditWindow = 15 * time.Minute
+
+// messageEditWindow 当前生效的消息编辑时限
+func (s *Service) messageEditWindow() time.Duration {
+	if s.editWindow > 0 {
+		return s.editWindow
+	}
+	return defaultMessageEditWindow
+}
+
+// EditMessage 发送者在时限内编辑自己的消息（正文，管理员还可切换内部备注/公开）。
+// 每次实际变更前先把旧内容存为一条 TicketMessageRevision；审计日志只记录版本号与正文长度变化，
+// 不含正文本身（审计日志不可修改，抹除消息时只能清理版本表中的正文）。
+func (s *Service) EditMessage(ctx context.Context, currentUID, ticketID, messageID uint, in openapi.TicketsIdMessagesMessageIdPatchRequest) (*openapi.TicketMessage, error) {
+	u, t, err := s.getTicketWithAccessCheck(currentUID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if in.IsInternalNote != nil && !isAdmin(u.Role) {
+		return nil, &ErrForbidden{Reason: "student cannot change internal note flag"}
+	}
+
+	var m dbpkg.TicketMessage
+	var mentioned []uint
+	changed := false
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := loadMessage(tx, t.ID, messageID, &m); err != nil {
+			return err
+		}
+		if m.IsInternalNote && !isAdmin(u.Role) {
+			return &ErrNotFound{Resource: "message"}
+		}
+		if m.SenderUserID != currentUID {
+			return &ErrForbidden{Reason: "only the sender can edit the message"}
+		}
+		if m.DeletedAt != nil || m.RedactedAt != nil {
+			return &ErrInvalidState{Message: "消息已删除，无法编辑"}
+		}
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		if window := s.messageEditWindow(); now.Sub(m.CreatedAt) > window {
+			return &ErrInvalidState{Message: "已超过可编辑时限（" + formatEditWindow(window) + "）"}
+		}
+
+		body := m.Body
+		if in.Body != nil {
+			body = strings.TrimSpace(*in.Body)
+		}
+		internal := m.IsInternalNote
+		if in.IsInternalNote != nil {
+			internal = *in.IsInternalNote
+		}
+		if body == "" {
+			atts, err := dbpkg.GetMessageAttachmentsMap(tx, []uint{m.ID})
+			if err != nil {
+				return err
+			}
+			if len(atts[m.ID]) == 0 {
+				return &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"body": "必填"}}
+			}
+		}
+
+		diff := map[string]interface{}{"message_id": m.ID}
+		if body != m.Body {
+			diff["body_length"] = audit.Change(utf8.RuneCountInString(m.Body), utf8.RuneCountInString(body))
+		}
+		if internal != m.IsInternalNote {
+			diff["is_internal_note"] = map[string]interface{}{"from": m.IsInternalNote, "to": internal}
+		}
+		if len(diff) == 1 {
+			// 内容未变化：不产生新版本
+			return nil
+		}
+
+		var maxVersion int
+		if err := tx.Model(&dbpkg.TicketMessageRevision{}).
+			Where("message_id = ?", m.ID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&maxVersion).Error; err != nil {
+			return err
+		}
+		rev := &dbpkg.TicketMessageRevision{
+			MessageID:      m.ID,
+			Version:        maxVersion + 1,
+			Body:           m.Body,
+			IsInternalNote: m.IsInternalNote,
+			EditedBy:       currentUID,
+			CreatedAt:      now,
+		}
+		if err := tx.Create(rev).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&dbpkg.TicketMessage{}).Where("id = ?", m.ID).Updates(map[string]interface{}{
+			"body":             body,
+			"is_internal_note": internal,
+			"edited_at":        now,
+		}).Error; err != nil {
+			return err
+		}
+		m.Body, m.IsInternalNote, m.EditedAt = body, internal, &now
+
+		// 编辑后的内部备注补记新增的提及
+		if m.IsInternalNote {
+			var err error
+			if mentioned, err = recordMentions(tx, &m); err != nil {
+				return err
+			}
+		}
+
+		diff["version"] = rev.Version
+		changed = true
+		return audit.Record(ctx, tx, currentUID, "message.edit", "TICKET", t.ID, diff)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		s.reindex(t.ID)
+		if s.notifier != nil && len(mentioned) > 0 {
+			go s.notifyMentioned(t, u.Name, m.Body, mentioned)
+		}
+	}
+	return s.toAPIMessage(m, isAdmin(u.Role))
+}
+
+// DeleteMessage 管理员软删除消息：线程中保留墓碑，正文与附件不再返回；原内容仍保存在数据库中
+func (s *Service) DeleteMessage(ctx context.Context, adminUID, ticketID, messageID uint) error {
+	u, t, err := s.getTicketWithAccessCheck(adminUID, ticketID)
+	if err != nil {
+		return err
+	}
+	if !isAdmin(u.Role) {
+		return &ErrForbidden{Reason: "only admins can delete messages"}
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var m dbpkg.TicketMessage
+		if err := loadMessage(tx, t.ID, messageID, &m); err != nil {
+			return err
+		}
+		if m.DeletedAt != nil {
+			return &ErrInvalidState{Message: "消息已删除"}
+		}
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		if err := tx.Model(&dbpkg.TicketMessage{}).Where("id = ?", m.ID).Updates(map[string]interface{}{
+			"deleted_at": now,
+			"deleted_by": adminUID,
+		}).Error; err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, adminUID, "message.delete", "TICKET", t.ID, map[string]interface{}{
+			"message_id":       m.ID,
+			"sender_user_id":   m.SenderUserID,
+			"is_internal_note": m.IsInternalNote,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.reindex(t.ID)
+	return nil
+}
+
+// RedactMessage 超级管理员抹除消息内容：清空正文及全部编辑历史中的正文，并解除附件关联。
+// 审计日志只记录抹除动作与原因，不包含被抹除的内容。
+func (s *Service) RedactMessage(ctx context.Context, superUID, ticketID, messageID uint, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"reason": "必填"}}
+	}
+	u, t, err := s.getTicketWithAccessCheck(superUID, ticketID)
+	if err != nil {
+		return err
+	}
+	if u.Role != dbpkg.RoleSuperAdmin {
+		return &ErrForbidden{Reason: "only super admins can redact messages"}
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var m dbpkg.TicketMessage
+		if err := loadMessage(tx, t.ID, messageID, &m); err != nil {
+			return err
+		}
+		if m.RedactedAt != nil {
+			return &ErrInvalidState{Message: "消息内容已被抹除"}
+		}
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		updates := map[string]interface{}{
+			"body":        "",
+			"redacted_at": now,
+			"redacted_by": superUID,
+		}
+		// 抹除同时视为删除，线程中保留墓碑
+		if m.DeletedAt == nil {
+			updates["deleted_at"] = now
+			updates["deleted_by"] = superUID
+		}
+		if err := tx.Model(&dbpkg.TicketMessage{}).Where("id = ?", m.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		revs := tx.Model(&dbpkg.TicketMessageRevision{}).Where("message_id = ?", m.ID).Update("body", "")
+		if revs.Error != nil {
+			return revs.Error
+		}
+		atts := tx.Where("message_id = ?", m.ID).Delete(&dbpkg.MessageAttachment{})
+		if atts.Error != nil {
+			return atts.Error
+		}
+		return audit.Record(ctx, tx, superUID, "message.redact", "TICKET", t.ID, map[string]interface{}{
+			"message_id":           m.ID,
+			"reason":               reason,
+			"revisions_redacted":   revs.RowsAffected,
+			"attachments_unlinked": atts.RowsAffected,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.reindex(t.ID)
+	return nil
+}
+
+// ListMessageRevisions 消息的编辑历史（按版本号升序）；管理员或消息发送者可查看
+func (s *Service) ListMessageRevisions(currentUID, ticketID, messageID uint) ([]openapi.TicketMessageRevision, error) {
+	u, t, err := s.getTicketWithAccessCheck(currentUID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	var m dbpkg.TicketMessage
+	if err := loadMessage(s.db, t.ID, messageID, &m); err != nil {
+		return nil, err
+	}
+	if !isAdmin(u.Role) {
+		// 学生不应感知内部备注的存在
+		if m.IsInternalNote {
+			return nil, &ErrNotFound{Resource: "message"}
+		}
+		if m.SenderUserID != currentUID || m.DeletedAt != nil {
+			return nil, &ErrForbidden{Reason: "only the sender can view message history"}
+		}
+	}
+
+	var rows []dbpkg.TicketMessageRevision
+	if err := s.db.Where("message_id = ?", m.ID).Order("version ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	hideEditor := hidesCreator(u, t) && m.SenderUserID == t.UserID
+	out := make([]openapi.TicketMessageRevision, 0, len(rows))
+	for _, r := range rows {
+		editedBy := int32(r.EditedBy)
+		if hideEditor {
+			editedBy = 0
+		}
+		out = append(out, openapi.TicketMessageRevision{
+			Id:             int32(r.ID),
+			Version:        int32(r.Version),
+			Body:           r.Body,
+			IsInternalNote: r.IsInternalNote,
+			EditedBy:       editedBy,
+			CreatedAt:      r.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+// formatEditWindow 以便于阅读的形式输出编辑时限，整分钟时显示为 "N 分钟"
+func formatEditWindow(d time.Duration) string {
+	if d%time.Minute == 0 {
+		return fmt.Sprintf("%d 分钟", int(d/time.Minute))
+	}
+	return d.String()
+}
+
+// loadMessage 加载属于指定工单的消息
+func loadMessage(d *gorm.DB, ticketID, messageID uint, m *dbpkg.TicketMessage) error {
+	if err := d.Where("id = ? AND ticket_id = ?", messageID, ticketID).First(m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &ErrNotFound{Resource: "message"}
+		}
+		return err
+	}
+	return nil
+}
+
+// toAPIMessage 转换单条消息（含附件与提及）；已删除的消息只返回墓碑信息
+func (s *Service) toAPIMessage(m dbpkg.TicketMessage, admin bool) (*openapi.TicketMessage, error) {
+	atts, err := dbpkg.GetMessageAttachmentsMap(s.db, []uint{m.ID})
+	if err != nil {
+		return nil, err
+	}
+	mentions := map[uint][]int32{}
+	if admin && m.IsInternalNote {
+		if mentions, err = mentionsByMessage(s.db, []uint{m.ID}); err != nil {
+			return nil, err
+		}
+	}
+	out := messageToAPI(m, atts[m.ID], mentions[m.ID])
+	return &out, nil
+}
+
+// messageToAPI 组装消息输出；已删除的消息隐藏正文、附件与提及
+func messageToAPI(m dbpkg.TicketMessage, attachments []uint, mentions []int32) openapi.TicketMessage {
+	out := openapi.TicketMessage{
+		Id:             int32(m.ID),
+		TicketId:       int32(m.TicketID),
+		SenderUserId:   int32(m.SenderUserID),
+		IsInternalNote: m.IsInternalNote,
+		CreatedAt:      m.CreatedAt,
+		EditedAt:       m.EditedAt,
+		IsDeleted:      m.DeletedAt != nil,
+		DeletedAt:      m.DeletedAt,
+		IsRedacted:     m.RedactedAt != nil,
+	}
+	if m.DeletedAt == nil {
+		out.Body = m.Body
+		out.AttachmentIds = toInt32IDs(attachments)
+		out.MentionedUserIds = mentions
+	}
+	return out
+}
@@ -2,15 +2,22 @@ package ticket
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"strings"
 	"time"
 
+	"student-services-platform-backend/internal/audit"
 	dbpkg "student-services-platform-backend/internal/db"
 	"student-services-platform-backend/internal/openapi"
+	"student-services-platform-backend/internal/pagination"
+
+	"gorm.io/gorm"
 )
 
-func (s *Service) ListMessages(currentUID, ticketID uint, page, pageSize int) (*openapi.PagedTicketMessages, error) {
-	u, _, err := s.getTicketWithAccessCheck(currentUID, ticketID)
+// ListMessages 列出工单消息（按时间正序）；支持 page/page_size 与游标两种分页方式
+func (s *Service) ListMessages(currentUID, ticketID uint, pg pagination.Params) (*openapi.PagedTicketMessages, error) {
+	u, t, err := s.getTicketWithAccessCheck(currentUID, ticketID)
 	if err != nil {
 		return nil, err
 	}
@@ -21,52 +28,110 @@ func (s *Service) ListMessages(currentUID, ticketID uint, page, pageSize int) (*
 		q = q.Where("is_internal_note = ?", false)
 	}
 
-	var total int64
-	if err := q.Count(&total).Error; err != nil {
-		return nil, err
+	pg = pg.Normalize()
+	out := &openapi.PagedTicketMessages{PageSize: int32(pg.PageSize)}
+	var rows []dbpkg.TicketMessage
+
+	if pg.UseCursor {
+		// 游标分页：消息 ID 单调递增，直接以 ID 作为 keyset
+		if pg.Cursor != "" {
+			c, err := pagination.Decode(pg.Cursor, "id:asc", 1)
+			if err != nil {
+				return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"cursor": "无效的游标"}}
+			}
+			afterID, err := pagination.ParseUint(c.Values[0])
+			if err != nil {
+				return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"cursor": "无效的游标"}}
+			}
+			q = q.Where("id > ?", afterID)
+		}
+		if err := q.Order("id ASC").Limit(pg.PageSize + 1).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		if len(rows) > pg.PageSize {
+			rows = rows[:pg.PageSize]
+			last := rows[len(rows)-1].ID
+			out.NextCursor = pagination.Encode(pagination.Cursor{Sort: "id:asc", Values: []string{pagination.FormatUint(last)}})
+		}
+	} else {
+		var total int64
+		if err := q.Count(&total).Error; err != nil {
+			return nil, err
+		}
+		offset := (pg.Page - 1) * pg.PageSize
+		if err := q.Order("id ASC").Offset(offset).Limit(pg.PageSize).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		out.Page = int32(pg.Page)
+		out.Total = int32(total)
 	}
 
-	if page < 1 {
-		page = 1
+	msgIDs := make([]uint, 0, len(rows))
+	for _, m := range rows {
+		msgIDs = append(msgIDs, m.ID)
 	}
-	if pageSize < 1 {
-		pageSize = 20
+	attachments, err := dbpkg.GetMessageAttachmentsMap(s.db, msgIDs)
+	if err != nil {
+		return nil, err
 	}
-	offset := (page - 1) * pageSize
 
-	var rows []dbpkg.TicketMessage
-	if err := q.Order("id ASC").Offset(offset).Limit(pageSize).Find(&rows).Error; err != nil {
+	// 拉取消息即视为已读到本页最后一条
+	if len(rows) > 0 {
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		if err := dbpkg.MarkTicketRead(s.db, ticketID, currentUID, rows[len(rows)-1].ID, now); err != nil {
+			return nil, err
+		}
+	}
+	seenBy, err := seenByMessage(s.db, ticketID, rows)
+	if err != nil {
 		return nil, err
 	}
 
-	items := make([]openapi.TicketMessage, 0, len(rows))
-	for _, m := range rows {
-		items = append(items, openapi.TicketMessage{
-			Id:             int32(m.ID),
-			TicketId:       int32(m.TicketID),
-			SenderUserId:   int32(m.SenderUserID),
-			Body:           m.Body,
-			IsInternalNote: m.IsInternalNote,
-			CreatedAt:      m.CreatedAt,
-		})
+	// 提及只出现在内部备注中，仅管理员可见
+	mentions := map[uint][]int32{}
+	if isAdmin(u.Role) {
+		ids := make([]uint, 0, len(rows))
+		for _, m := range rows {
+			if m.IsInternalNote {
+				ids = append(ids, m.ID)
+			}
+		}
+		if mentions, err = mentionsByMessage(s.db, ids); err != nil {
+			return nil, err
+		}
 	}
 
-	return &openapi.PagedTicketMessages{
-		Items:    items,
-		Page:     int32(page),
-		PageSize: int32(pageSize),
-		Total:    int32(total),
-	}, nil
+	out.Items = make([]openapi.TicketMessage, 0, len(rows))
+	for _, m := range rows {
+		item := messageToAPI(m, attachments[m.ID], mentions[m.ID])
+		item.SeenBy = seenBy[m.ID]
+		item.SeenByCreator = slices.Contains(item.SeenBy, int32(t.UserID))
+		if hidesCreator(u, t) {
+			redactMessageCreator(&item, t)
+		}
+		out.Items = append(out.Items, item)
+	}
+	return out, nil
 }
 
-func (s *Service) PostMessage(currentUID, ticketID uint, body string, isInternal bool) (*openapi.TicketMessage, error) {
+// maxMessageAttachments 单条消息的附件数量上限
+const maxMessageAttachments = 10
+
+func (s *Service) PostMessage(ctx context.Context, currentUID, ticketID uint, body string, isInternal bool, attachmentIDs []int32) (*openapi.TicketMessage, error) {
 	body = strings.TrimSpace(body)
-	if body == "" {
+	attIDs := normalizeIDs(attachmentIDs)
+	if body == "" && len(attIDs) == 0 {
 		return nil, &ErrValidation{
 			Message: "字段校验失败",
 			Details: map[string]interface{}{"body": "必填"},
 		}
 	}
+	if len(attIDs) > maxMessageAttachments {
+		return nil, &ErrValidation{
+			Message: "字段校验失败",
+			Details: map[string]interface{}{"attachment_ids": fmt.Sprintf("最多 %d 个附件", maxMessageAttachments)},
+		}
+	}
 
 	u, t, err := s.getTicketWithAccessCheck(currentUID, ticketID)
 	if err != nil {
@@ -86,10 +151,60 @@ func (s *Service) PostMessage(currentUID, ticketID uint, body string, isInternal
 		IsInternalNote: isInternal && isAdmin(u.Role),
 		CreatedAt:      now,
 	}
-	if err := s.db.Create(m).Error; err != nil {
+	var mentioned []uint
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkImagesExist(tx, attIDs); err != nil {
+			return err
+		}
+		// 学生只能附加新上传的文件或本人本来就能访问的文件，避免借附件获取他人文件
+		if !isAdmin(u.Role) {
+			if err := checkAttachmentsUsable(tx, currentUID, attIDs); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+		// 发送者自然已读到自己的消息（及之前的消息）
+		if err := dbpkg.MarkTicketRead(tx, t.ID, currentUID, m.ID, now); err != nil {
+			return err
+		}
+		if len(attIDs) > 0 {
+			rels := make([]dbpkg.MessageAttachment, 0, len(attIDs))
+			for _, id := range attIDs {
+				rels = append(rels, dbpkg.MessageAttachment{MessageID: m.ID, ImageID: id, CreatedAt: now})
+			}
+			if err := tx.Create(&rels).Error; err != nil {
+				return err
+			}
+		}
+		err := audit.Record(ctx, tx, currentUID, "ticket.message", "TICKET", t.ID, map[string]interface{}{
+			"message_id":       m.ID,
+			"is_internal_note": m.IsInternalNote,
+			"attachment_ids":   attIDs,
+		})
+		if err != nil {
+			return err
+		}
+		if !m.IsInternalNote {
+			return nil
+		}
+		// 发布内部备注的管理员自动关注该工单
+		if _, err := dbpkg.AddTicketWatcher(tx, t.ID, currentUID, currentUID, dbpkg.TicketWatcherInternalNote, now); err != nil {
+			return err
+		}
+		mentioned, err = recordMentions(tx, m)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	// 内部备注不进入检索索引，避免学生通过搜索探测到内容
+	if !m.IsInternalNote {
+		s.reindex(t.ID)
+	}
+
 	// 发送邮件通知（如果配置了notifier）
 	if s.notifier != nil {
 		go func() {
@@ -102,6 +217,11 @@ func (s *Service) PostMessage(currentUID, ticketID uint, body string, isInternal
 				return // 静默失败，不影响主流程
 			}
 
+			// 被 @ 提及的管理员单独通知
+			if len(mentioned) > 0 {
+				s.notifyMentioned(t, sender.Name, body, mentioned)
+			}
+
 			if err := s.db.First(&creator, t.UserID).Error; err != nil {
 				return
 			}
@@ -124,27 +244,77 @@ func (s *Service) PostMessage(currentUID, ticketID uint, body string, isInternal
 				recipientEmail = creator.Email
 			}
 
-			if recipientEmail != "" {
+			// 内部备注不通知学生，只发给处理人与关注者
+			creatorEmail := creator.Email
+			if m.IsInternalNote {
+				creatorEmail = ""
+			}
+			watchers, err := dbpkg.CountTicketWatchers(s.db, t.ID)
+			if err != nil {
+				return
+			}
+
+			if recipientEmail != "" || watchers > 0 {
 				// 发送新消息通知
+				// 匿名工单中学生的回复以代号通知处理人
+				senderName := sender.Name
+				if sender.ID == t.UserID {
+					senderName = creatorDisplayName(t, &sender)
+				}
 				s.notifier.NotifyNewMessage(
-					context.Background(),
+					notifyCtx(t.Priority),
 					t.ID,
-					sender.Name,
+					senderName,
 					body,
-					creator.Email,
+					creatorEmail,
 					handler.Email,
 				)
 			}
 		}()
 	}
 
+	mentionedIDs := toInt32IDs(mentioned)
 	out := &openapi.TicketMessage{
-		Id:             int32(m.ID),
-		TicketId:       int32(m.TicketID),
-		SenderUserId:   int32(m.SenderUserID),
-		Body:           m.Body,
-		IsInternalNote: m.IsInternalNote,
-		CreatedAt:      m.CreatedAt,
+		Id:               int32(m.ID),
+		TicketId:         int32(m.TicketID),
+		SenderUserId:     int32(m.SenderUserID),
+		Body:             m.Body,
+		IsInternalNote:   m.IsInternalNote,
+		MentionedUserIds: mentionedIDs,
+		AttachmentIds:    toInt32IDs(attIDs),
+		CreatedAt:        m.CreatedAt,
 	}
 	return out, nil
-}
\ No newline at end of file
+}
+// checkAttachmentsUsable 校验附件未被其他工单/消息使用，或当前用户本来就有权访问
+func checkAttachmentsUsable(tx *gorm.DB, uid uint, ids []uint) error {
+	for _, id := range ids {
+		linked, err := dbpkg.DoesImageHaveAnyTicket(tx, id)
+		if err != nil {
+			return err
+		}
+		if !linked {
+			continue
+		}
+		ok, err := dbpkg.IsImageAccessibleByUser(tx, id, uid)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return &ErrForbidden{Reason: fmt.Sprintf("attachment %d is not accessible", id)}
+		}
+	}
+	return nil
+}
+
+// toInt32IDs 转换 ID 列表；空列表返回 nil
+func toInt32IDs(ids []uint) []int32 {
+	if len(ids) == 0 {
+		return nil
+	}
+	out := make([]int32, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, int32(id))
+	}
+	return out
+}
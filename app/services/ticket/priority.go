@@ -0,0 +1,128 @@
+package ticket
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+	"student-services-platform-backend/internal/worker"
+
+	"gorm.io/gorm"
+)
+
+// resolveCreatorPriority 解析创建者（学生）提交的优先级：可选 P2-P4，P1 仅管理员可设置；
+// 未传优先级时按 is_urgent 推导，但紧急程度未变化时保留当前优先级（避免覆盖管理员的调整）。
+// current 为空表示新建工单。校验不通过时返回非空 msg。
+func resolveCreatorPriority(in *openapi.TicketPriority, isUrgent bool, current dbpkg.TicketPriority) (p dbpkg.TicketPriority, msg string) {
+	if in == nil {
+		if current != "" && dbpkg.IsUrgentPriority(current) == isUrgent {
+			return current, ""
+		}
+		return dbpkg.PriorityFromUrgent(isUrgent), ""
+	}
+	p = dbpkg.TicketPriority(strings.ToUpper(strings.TrimSpace(string(*in))))
+	if !dbpkg.ValidTicketPriority(p) {
+		return "", "取值必须为 P1/P2/P3/P4"
+	}
+	if p == dbpkg.TicketPriorityP1 && current != dbpkg.TicketPriorityP1 {
+		return "", "P1 仅管理员可设置"
+	}
+	return p, ""
+}
+
+// emailPriority 工单优先级对应的邮件优先级。
+// 通知目前同步发送，不经过队列，优先级只体现在邮件头 X-Priority 上，供收件端排序
+func emailPriority(p dbpkg.TicketPriority) worker.EmailPriority {
+	switch p {
+	case dbpkg.TicketPriorityP1:
+		return worker.EmailPriorityCritical
+	case dbpkg.TicketPriorityP2:
+		return worker.EmailPriorityHigh
+	case dbpkg.TicketPriorityP4:
+		return worker.EmailPriorityLow
+	default:
+		return worker.EmailPriorityNormal
+	}
+}
+
+// notifyCtx 发送工单相关通知使用的 ctx，携带按工单优先级确定的邮件优先级
+func notifyCtx(p dbpkg.TicketPriority) context.Context {
+	return worker.WithPriority(context.Background(), emailPriority(p))
+}
+
+// SetPriority 管理员调整工单优先级（P1-P4 均可），同步紧急标记与 SLA 截止时间，并写入审计日志
+func (s *Service) SetPriority(ctx context.Context, adminUID, ticketID uint, in openapi.TicketsIdPriorityPutRequest) (*openapi.Ticket, error) {
+	p := dbpkg.TicketPriority(strings.ToUpper(strings.TrimSpace(string(in.Priority))))
+	if !dbpkg.ValidTicketPriority(p) {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"priority": "取值必须为 P1/P2/P3/P4"}}
+	}
+	reason := strings.TrimSpace(in.Reason)
+	if len([]rune(reason)) > 500 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"reason": "长度不能超过 500"}}
+	}
+
+	u, t, err := s.getTicketWithAccessCheck(adminUID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin(u.Role) {
+		return nil, &ErrForbidden{Reason: "only admins can set priority"}
+	}
+	switch t.Status {
+	case dbpkg.TicketStatusClosed, dbpkg.TicketStatusCancelled, dbpkg.TicketStatusMerged, dbpkg.TicketStatusSpamConfirmed:
+		return nil, &ErrInvalidState{Message: "工单已结束，无法调整优先级"}
+	}
+
+	if t.Priority != p {
+		err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			now := time.Now().UTC().Truncate(time.Microsecond)
+			isUrgent := dbpkg.IsUrgentPriority(p)
+			updates := map[string]interface{}{
+				"priority":   p,
+				"is_urgent":  isUrgent,
+				"updated_at": now,
+			}
+			if t.IsUrgent != isUrgent {
+				updates["sla_due_at"] = dbpkg.TicketSLADue(t.CreatedAt, isUrgent).UTC().Truncate(time.Microsecond)
+			}
+			res := tx.Model(&dbpkg.Ticket{}).
+				Where("id = ? AND priority = ?", t.ID, t.Priority).
+				Updates(updates)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return &ErrConflict{Message: "工单优先级已被修改，请刷新后重试"}
+			}
+			diff := map[string]interface{}{
+				"priority": map[string]interface{}{"from": t.Priority, "to": p},
+			}
+			if t.IsUrgent != isUrgent {
+				diff["is_urgent"] = map[string]interface{}{"from": t.IsUrgent, "to": isUrgent}
+			}
+			if reason != "" {
+				diff["reason"] = reason
+			}
+			if err := audit.Record(ctx, tx, adminUID, "ticket.priority", "TICKET", t.ID, diff); err != nil {
+				return err
+			}
+			return tx.First(t, t.ID).Error
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	imgIDs, err := dbpkg.GetTicketImageIDs(s.db, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	out := toAPITicket(t, imgIDs)
+	if hidesCreator(u, t) {
+		redactTicketCreator(&out)
+	}
+	return &out, nil
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"student-services-platform-backend/internal/audit"
 	dbpkg "student-services-platform-backend/internal/db"
 	"student-services-platform-backend/internal/openapi"
 
@@ -14,7 +15,7 @@ import (
 )
 
 // RateTicket 学生对自己的工单进行一次性评分
-func (s *Service) RateTicket(currentUID, ticketID uint, stars int, comment string) (*openapi.Rating, error) {
+func (s *Service) RateTicket(ctx context.Context, currentUID, ticketID uint, stars int, comment string) (*openapi.Rating, error) {
 	// 校验登录用户存在和工单权限
 	_, t, err := s.getTicketWithAccessCheck(currentUID, ticketID)
 	if err != nil {
@@ -56,7 +57,16 @@ func (s *Service) RateTicket(currentUID, ticketID uint, stars int, comment strin
 		Comment:  comment,
 		CreatedAt: now,
 	}
-	if err := s.db.Create(r).Error; err != nil {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(r).Error; err != nil {
+			return err
+		}
+		return audit.Record(ctx, tx, currentUID, "ticket.rate", "TICKET", ticketID, map[string]interface{}{
+			"stars":   stars,
+			"comment": comment,
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -79,7 +89,7 @@ func (s *Service) RateTicket(currentUID, ticketID uint, stars int, comment strin
 
 				// 发送邮件通知
 				s.notifier.NotifyTicketRated(
-					context.Background(),
+					notifyCtx(ticket.Priority),
 					ticketID,
 					ticket.Title,
 					fmt.Sprintf("%d星", stars),
@@ -0,0 +1,113 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+
+	"gorm.io/gorm"
+)
+
+// unreadSummaryLimit 未读汇总中最多列出的工单数
+const unreadSummaryLimit = 50
+
+// MarkRead 将当前用户在工单中的已读位置推进到 messageID；messageID 为 0 时标记到最新一条可见消息
+func (s *Service) MarkRead(ctx context.Context, currentUID, ticketID, messageID uint) error {
+	u, t, err := s.getTicketWithAccessCheck(currentUID, ticketID)
+	if err != nil {
+		return err
+	}
+	q := s.db.WithContext(ctx).Model(&dbpkg.TicketMessage{}).Where("ticket_id = ?", t.ID)
+	if !isAdmin(u.Role) {
+		q = q.Where("is_internal_note = ?", false)
+	}
+	var m dbpkg.TicketMessage
+	if messageID != 0 {
+		q = q.Where("id = ?", messageID)
+	}
+	if err := q.Select("id").Order("id DESC").Take(&m).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if messageID != 0 {
+			return &ErrNotFound{Resource: "message"}
+		}
+		return nil // 工单还没有消息
+	}
+	return dbpkg.MarkTicketRead(s.db.WithContext(ctx), t.ID, currentUID, m.ID, time.Now().UTC().Truncate(time.Microsecond))
+}
+
+// UnreadSummary 当前用户名下工单（学生：本人提交；管理员：本人受理）的未读汇总
+func (s *Service) UnreadSummary(currentUID uint) (*openapi.UnreadSummary, error) {
+	u, err := s.currentUser(s.db, currentUID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &ErrForbidden{Reason: "user not found"}
+		}
+		return nil, err
+	}
+	asAdmin := isAdmin(u.Role)
+
+	total, tickets, err := dbpkg.CountUnreadOwned(s.db, currentUID, asAdmin)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := dbpkg.ListUnreadTickets(s.db, currentUID, asAdmin, unreadSummaryLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	lastIDs := make([]uint, 0, len(rows))
+	for _, r := range rows {
+		lastIDs = append(lastIDs, r.LastMessageID)
+	}
+	lastAt := map[uint]time.Time{}
+	if len(lastIDs) > 0 {
+		var msgs []dbpkg.TicketMessage
+		if err := s.db.Select("id", "created_at").Where("id IN ?", lastIDs).Find(&msgs).Error; err != nil {
+			return nil, err
+		}
+		for _, m := range msgs {
+			lastAt[m.ID] = m.CreatedAt
+		}
+	}
+
+	out := &openapi.UnreadSummary{
+		TotalUnread: int32(total),
+		TicketCount: int32(tickets),
+		Items:       make([]openapi.UnreadTicket, 0, len(rows)),
+	}
+	for _, r := range rows {
+		out.Items = append(out.Items, openapi.UnreadTicket{
+			TicketId:      int32(r.TicketID),
+			Title:         r.Title,
+			Status:        openapi.TicketStatus(r.Status),
+			UnreadCount:   int32(r.UnreadCount),
+			LastMessageAt: lastAt[r.LastMessageID],
+		})
+	}
+	return out, nil
+}
+
+// seenByMessage 根据工单内各用户的已读位置，计算每条消息已被哪些其他用户读到
+func seenByMessage(d *gorm.DB, ticketID uint, rows []dbpkg.TicketMessage) (map[uint][]int32, error) {
+	out := map[uint][]int32{}
+	if len(rows) == 0 {
+		return out, nil
+	}
+	states, err := dbpkg.ListTicketReadStates(d, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range rows {
+		for _, st := range states {
+			if st.UserID != m.SenderUserID && st.LastReadMessageID >= m.ID {
+				out[m.ID] = append(out[m.ID], int32(st.UserID))
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,136 @@
+package ticket
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+	"student-services-platform-backend/internal/search"
+
+	"gorm.io/gorm"
+)
+
+// snippetRunes 搜索结果片段的长度（字符数）
+const snippetRunes = 80
+
+// reindex 重建工单检索索引；失败只记录日志，不影响主流程
+func (s *Service) reindex(ticketID uint) {
+	if err := dbpkg.ReindexTicket(s.db, ticketID); err != nil {
+		log.Printf("ticket: 工单 %d 检索索引更新失败: %v", ticketID, err)
+	}
+}
+
+// SearchTickets 全文检索工单标题、正文与非内部备注消息。
+// 可见性与 ListTickets 一致：学生只能搜到自己的工单，管理员可搜全部。
+func (s *Service) SearchTickets(currentUID uint, q string, page, pageSize int) (*openapi.PagedTicketSearchHits, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"q": "必填"}}
+	}
+	if len([]rune(q)) > 100 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"q": "长度不能超过 100"}}
+	}
+	terms := search.QueryTerms(q)
+	if len(terms) == 0 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"q": "缺少可检索的文字"}}
+	}
+
+	u, err := s.currentUser(s.db, currentUID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &ErrForbidden{Reason: "user not found"}
+		}
+		return nil, err
+	}
+	var owner *uint
+	if !isAdmin(u.Role) {
+		owner = &currentUID
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+	ids, total, err := dbpkg.SearchTicketIDs(s.db, terms, owner, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]openapi.TicketSearchHit, 0, len(ids))
+	if len(ids) > 0 {
+		var rows []dbpkg.Ticket
+		if err := s.db.Where("id IN ?", ids).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		byID := make(map[uint]*dbpkg.Ticket, len(rows))
+		for i := range rows {
+			byID[rows[i].ID] = &rows[i]
+		}
+
+		imagesMap, err := dbpkg.GetTicketImagesMap(s.db, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		// 本页工单未删除的非内部备注消息，用于定位命中片段
+		var msgs []dbpkg.TicketMessage
+		if err := s.db.Where("ticket_id IN ? AND is_internal_note = ? AND deleted_at IS NULL", ids, false).
+			Order("id ASC").Find(&msgs).Error; err != nil {
+			return nil, err
+		}
+		msgsByTicket := make(map[uint][]dbpkg.TicketMessage)
+		for _, m := range msgs {
+			msgsByTicket[m.TicketID] = append(msgsByTicket[m.TicketID], m)
+		}
+
+		// 保持相关度顺序
+		for _, id := range ids {
+			t, ok := byID[id]
+			if !ok {
+				continue // 索引滞后于删除
+			}
+			hit := buildSearchHit(t, imagesMap[id], msgsByTicket[id], terms)
+			if hidesCreator(u, t) {
+				redactTicketCreator(&hit.Ticket)
+			}
+			items = append(items, hit)
+		}
+	}
+
+	return &openapi.PagedTicketSearchHits{
+		Items:    items,
+		Page:     int32(page),
+		PageSize: int32(pageSize),
+		Total:    int32(total),
+	}, nil
+}
+
+// buildSearchHit 生成带高亮的搜索结果：优先展示正文命中，其次是消息命中
+func buildSearchHit(t *dbpkg.Ticket, imageIDs []uint, msgs []dbpkg.TicketMessage, terms []string) openapi.TicketSearchHit {
+	hit := openapi.TicketSearchHit{Ticket: toAPITicket(t, imageIDs)}
+
+	title, titleHit := search.Highlight(t.Title, terms, 0)
+	hit.TitleHighlight = title
+
+	if snippet, ok := search.Highlight(t.Content, terms, snippetRunes); ok {
+		hit.Snippet, hit.MatchedIn = snippet, "content"
+		return hit
+	}
+	for _, m := range msgs {
+		if snippet, ok := search.Highlight(m.Body, terms, snippetRunes); ok {
+			mid := int32(m.ID)
+			hit.Snippet, hit.MatchedIn, hit.MessageId = snippet, "message", &mid
+			return hit
+		}
+	}
+
+	hit.Snippet, _ = search.Highlight(t.Content, terms, snippetRunes)
+	if titleHit {
+		hit.MatchedIn = "title"
+	}
+	return hit
+}
@@ -0,0 +1,147 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxTagsPerRequest 单次添加/筛选的标签数量上限
+const maxTagsPerRequest = 20
+
+// normalizeTagNames 规范化并去重标签名称，返回按输入顺序排列的结果
+func normalizeTagNames(names []string) ([]string, map[string]interface{}) {
+	details := map[string]interface{}{}
+	if len(names) > maxTagsPerRequest {
+		details["tags"] = fmt.Sprintf("最多 %d 个标签", maxTagsPerRequest)
+		return nil, details
+	}
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+	for _, raw := range names {
+		n := dbpkg.NormalizeTagName(raw)
+		switch {
+		case n == "":
+			details["tags"] = "标签不能为空"
+		case len([]rune(n)) > dbpkg.MaxTagNameLen:
+			details["tags"] = fmt.Sprintf("标签长度不能超过 %d: %s", dbpkg.MaxTagNameLen, raw)
+		case strings.Contains(n, ","):
+			details["tags"] = "标签不能包含逗号: " + raw
+		}
+		if len(details) > 0 {
+			return nil, details
+		}
+		if !seen[n] {
+			seen[n] = true
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+// AddTicketTags 管理员为工单添加标签（不存在的标签自动创建），返回工单当前的全部标签
+func (s *Service) AddTicketTags(ctx context.Context, adminUID, ticketID uint, names []string) ([]string, error) {
+	tags, details := normalizeTagNames(names)
+	if len(details) > 0 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: details}
+	}
+	if len(tags) == 0 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"tags": "必填"}}
+	}
+
+	var current []string
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&dbpkg.Ticket{}, ticketID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &ErrNotFound{Resource: "ticket"}
+			}
+			return err
+		}
+		rows, err := dbpkg.EnsureTags(tx, tags, adminUID)
+		if err != nil {
+			return err
+		}
+
+		existing, err := dbpkg.GetTicketTagsMap(tx, []uint{ticketID})
+		if err != nil {
+			return err
+		}
+		had := make(map[string]bool, len(existing[ticketID]))
+		for _, n := range existing[ticketID] {
+			had[n] = true
+		}
+
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		var added []string
+		rels := make([]dbpkg.TicketTag, 0, len(rows))
+		for _, tg := range rows {
+			if had[tg.Name] {
+				continue
+			}
+			added = append(added, tg.Name)
+			rels = append(rels, dbpkg.TicketTag{TicketID: ticketID, TagID: tg.ID, AddedBy: adminUID, CreatedAt: now})
+		}
+		if len(rels) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rels).Error; err != nil {
+				return err
+			}
+			if err := audit.Record(ctx, tx, adminUID, "ticket.tag", "TICKET", ticketID, map[string]interface{}{"added": added}); err != nil {
+				return err
+			}
+		}
+
+		m, err := dbpkg.GetTicketTagsMap(tx, []uint{ticketID})
+		if err != nil {
+			return err
+		}
+		current = m[ticketID]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		current = []string{}
+	}
+	return current, nil
+}
+
+// RemoveTicketTag 管理员移除工单上的一个标签（标签本身保留）
+func (s *Service) RemoveTicketTag(ctx context.Context, adminUID, ticketID uint, name string) error {
+	name = dbpkg.NormalizeTagName(name)
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tg dbpkg.Tag
+		if err := tx.Where("name = ?", name).First(&tg).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &ErrNotFound{Resource: "tag"}
+			}
+			return err
+		}
+		res := tx.Where("ticket_id = ? AND tag_id = ?", ticketID, tg.ID).Delete(&dbpkg.TicketTag{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return &ErrNotFound{Resource: "tag"}
+		}
+		return audit.Record(ctx, tx, adminUID, "ticket.untag", "TICKET", ticketID, map[string]interface{}{"removed": tg.Name})
+	})
+}
+
+// tagFilterSubquery 返回同时带有全部给定标签的工单 ID 子查询
+func (s *Service) tagFilterSubquery(names []string) *gorm.DB {
+	return s.db.Table("ticket_tags tt").
+		Select("tt.ticket_id").
+		Joins("JOIN tags tg ON tg.id = tt.tag_id").
+		Where("tg.name IN ?", names).
+		Group("tt.ticket_id").
+		Having("COUNT(*) = ?", len(names))
+}
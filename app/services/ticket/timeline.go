@@ -0,0 +1,247 @@
+package ticket
+
+import (
+	"encoding/json"
+	"sort"
+
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+
+	"gorm.io/gorm"
+)
+
+// 时间线事件类型
+const (
+	timelineCreated        = "created"
+	timelineMessage        = "message"
+	timelineAttachment     = "attachment"
+	timelineStatusChange   = "status_change"
+	timelineAssignment     = "assignment"
+	timelinePriorityChange = "priority_change"
+	timelineSpamFlag       = "spam_flag"
+	timelineSpamReview     = "spam_review"
+	timelineRating         = "rating"
+)
+
+// GetTimeline 工单的统一时间线：消息、附件、状态流转、指派、优先级调整、垃圾标记/复核与评价按时间合并。
+// 学生看不到内部事件（内部备注及其附件、垃圾标记与复核）；匿名工单对其他人隐藏创建者身份。
+func (s *Service) GetTimeline(currentUID, ticketID uint) (*openapi.TicketTimeline, error) {
+	u, t, err := s.getTicketWithAccessCheck(currentUID, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	admin := isAdmin(u.Role)
+	hide := hidesCreator(u, t)
+
+	actor := func(ev *openapi.TicketTimelineEvent, uid uint) {
+		if hide && uid == t.UserID {
+			ev.ActorHandle = dbpkg.AnonymousHandle(t.ID)
+			return
+		}
+		ev.ActorUserId = int32(uid)
+	}
+
+	var items []openapi.TicketTimelineEvent
+	created := openapi.TicketTimelineEvent{Type: timelineCreated, At: t.CreatedAt}
+	actor(&created, t.UserID)
+	items = append(items, created)
+
+	// 工单附件
+	var images []dbpkg.TicketImage
+	if err := s.db.Where("ticket_id = ?", t.ID).Order("created_at ASC, image_id ASC").Find(&images).Error; err != nil {
+		return nil, err
+	}
+	for _, img := range images {
+		ev := openapi.TicketTimelineEvent{Type: timelineAttachment, At: img.CreatedAt, ImageId: int32(img.ImageID)}
+		actor(&ev, t.UserID)
+		items = append(items, ev)
+	}
+
+	// 消息及消息附件（已删除的消息保留墓碑，不再列出其附件）
+	q := s.db.Where("ticket_id = ?", t.ID)
+	if !admin {
+		q = q.Where("is_internal_note = ?", false)
+	}
+	var msgs []dbpkg.TicketMessage
+	if err := q.Order("id ASC").Find(&msgs).Error; err != nil {
+		return nil, err
+	}
+	msgIDs := make([]uint, 0, len(msgs))
+	noteIDs := make([]uint, 0)
+	for _, m := range msgs {
+		msgIDs = append(msgIDs, m.ID)
+		if m.IsInternalNote {
+			noteIDs = append(noteIDs, m.ID)
+		}
+	}
+	attachments, err := dbpkg.GetMessageAttachmentsMap(s.db, msgIDs)
+	if err != nil {
+		return nil, err
+	}
+	mentions, err := mentionsByMessage(s.db, noteIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range msgs {
+		msg := messageToAPI(m, attachments[m.ID], mentions[m.ID])
+		if hide {
+			redactMessageCreator(&msg, t)
+		}
+		ev := openapi.TicketTimelineEvent{Type: timelineMessage, At: m.CreatedAt, IsInternal: m.IsInternalNote, Message: &msg}
+		actor(&ev, m.SenderUserID)
+		items = append(items, ev)
+		if m.DeletedAt != nil {
+			continue
+		}
+		mid := int32(m.ID)
+		for _, imgID := range attachments[m.ID] {
+			att := openapi.TicketTimelineEvent{
+				Type:       timelineAttachment,
+				At:         m.CreatedAt,
+				IsInternal: m.IsInternalNote,
+				MessageId:  &mid,
+				ImageId:    int32(imgID),
+			}
+			actor(&att, m.SenderUserID)
+			items = append(items, att)
+		}
+	}
+
+	// 审计日志中的状态流转、指派、优先级调整与垃圾标记/复核；
+	// 已合并到本工单的源工单的审计日志仍归属源工单，一并列出并标明来源
+	ticketIDs, err := mergedHistoryIDs(s.db, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	var logs []dbpkg.AuditLog
+	if err := s.db.Where("entity = ? AND entity_id IN ?", "TICKET", ticketIDs).Order("id ASC").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	// 早于“匿名属性不同不可合并”规则合并进来的匿名源工单，其创建者仍按源工单隐藏
+	var anonSources []uint
+	if len(ticketIDs) > 1 && u.ID != t.UserID {
+		if err := s.db.Model(&dbpkg.Ticket{}).
+			Where("id IN ? AND id <> ? AND is_anonymous = ?", ticketIDs, t.ID, true).
+			Pluck("id", &anonSources).Error; err != nil {
+			return nil, err
+		}
+	}
+	anonSource := make(map[uint]bool, len(anonSources))
+	for _, id := range anonSources {
+		anonSource[id] = true
+	}
+	for _, l := range logs {
+		ev, ok := timelineFromAudit(l)
+		if !ok || (ev.IsInternal && !admin) {
+			continue
+		}
+		if l.EntityID != t.ID {
+			ev.TicketId = int32(l.EntityID)
+		}
+		if ev.Type == timelinePriorityChange && !admin {
+			ev.Reason = "" // 管理员调整优先级的原因仅内部可见
+		}
+		if anonSource[l.EntityID] && l.ActorUserID == t.UserID {
+			ev.ActorHandle = dbpkg.AnonymousHandle(l.EntityID)
+		} else {
+			actor(&ev, l.ActorUserID)
+		}
+		items = append(items, ev)
+	}
+
+	// 评价
+	var ratings []dbpkg.Rating
+	if err := s.db.Where("ticket_id = ?", t.ID).Find(&ratings).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range ratings {
+		ev := openapi.TicketTimelineEvent{Type: timelineRating, At: r.CreatedAt, Stars: int32(r.Stars), Comment: r.Comment}
+		actor(&ev, r.UserID)
+		items = append(items, ev)
+	}
+
+	// 同一时刻的事件保持上面的收集顺序
+	sort.SliceStable(items, func(i, j int) bool { return items[i].At.Before(items[j].At) })
+	return &openapi.TicketTimeline{TicketId: int32(t.ID), Items: items}, nil
+}
+
+// mergedHistoryIDs 返回工单自身及（逐级）合并到它的全部源工单 ID
+func mergedHistoryIDs(d *gorm.DB, ticketID uint) ([]uint, error) {
+	ids := []uint{ticketID}
+	frontier := []uint{ticketID}
+	for depth := 0; depth < maxParentDepth && len(frontier) > 0; depth++ {
+		var next []uint
+		if err := d.Model(&dbpkg.Ticket{}).Where("merged_into_ticket_id IN ?", frontier).Pluck("id", &next).Error; err != nil {
+			return nil, err
+		}
+		ids = append(ids, next...)
+		frontier = next
+	}
+	return ids, nil
+}
+
+// timelineFromAudit 将工单审计日志转换为时间线事件；与时间线无关的动作返回 false
+func timelineFromAudit(l dbpkg.AuditLog) (openapi.TicketTimelineEvent, bool) {
+	var diff map[string]interface{}
+	if len(l.Diff) > 0 {
+		if err := json.Unmarshal(l.Diff, &diff); err != nil {
+			return openapi.TicketTimelineEvent{}, false
+		}
+	}
+	ev := openapi.TicketTimelineEvent{
+		At:         l.CreatedAt,
+		StatusFrom: openapi.TicketStatus(diffString(diff["status_from"])),
+		StatusTo:   openapi.TicketStatus(diffString(diff["status_to"])),
+		Reason:     diffString(diff["reason"]),
+	}
+	switch l.Action {
+	case "ticket.claim":
+		ev.Type = timelineAssignment
+		ev.StatusFrom = openapi.TicketStatus(dbpkg.TicketStatusNew)
+		ev.AssigneeTo = diffInt32(diff["assigned_admin_id"])
+	case "ticket.unclaim":
+		ev.Type = timelineAssignment
+		ev.StatusFrom = openapi.TicketStatus(dbpkg.TicketStatusClaimed)
+		ev.AssigneeFrom = diffInt32(diff["unassigned_admin_id"])
+	case "ticket.assign":
+		ev.Type = timelineAssignment
+		if ch, ok := diff["assigned_admin_id"].(map[string]interface{}); ok {
+			ev.AssigneeFrom, ev.AssigneeTo = diffInt32(ch["from"]), diffInt32(ch["to"])
+		}
+		if ev.StatusTo != "" {
+			ev.StatusFrom = openapi.TicketStatus(dbpkg.TicketStatusNew)
+		}
+	case "ticket.priority":
+		ev.Type = timelinePriorityChange
+		if ch, ok := diff["priority"].(map[string]interface{}); ok {
+			ev.PriorityFrom = openapi.TicketPriority(diffString(ch["from"]))
+			ev.PriorityTo = openapi.TicketPriority(diffString(ch["to"]))
+		}
+	case "ticket.spam_flag":
+		ev.Type, ev.IsInternal = timelineSpamFlag, true
+	case "ticket.spam_review":
+		ev.Type, ev.IsInternal = timelineSpamReview, true
+	default:
+		// 其余带状态变化的动作：处理中/已处理/关闭/撤回/合并/事件批量处理等
+		if ev.StatusTo == "" {
+			return ev, false
+		}
+		ev.Type = timelineStatusChange
+	}
+	return ev, true
+}
+
+func diffString(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+// diffInt32 审计 diff 中的 ID（JSON 数字解码为 float64）
+func diffInt32(v interface{}) *int32 {
+	f, ok := v.(float64)
+	if !ok || f <= 0 {
+		return nil
+	}
+	id := int32(f)
+	return &id
+}
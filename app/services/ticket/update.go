@@ -0,0 +1,268 @@
+package ticket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// UpdateTicket 创建者在工单被受理前（NEW）编辑标题/正文/分类/紧急程度与优先级/图片。
+// 每次实际发生变更时，先把旧内容存为一条 TicketRevision，再覆盖工单。
+func (s *Service) UpdateTicket(ctx context.Context, currentUID, ticketID uint, in openapi.TicketUpdate) (*openapi.Ticket, error) {
+	title := strings.TrimSpace(in.Title)
+	content := strings.TrimSpace(in.Content)
+	if details := validateTicketFields(title, content); len(details) > 0 {
+		return nil, &ErrValidation{Message: "字段校验失败", Details: details}
+	}
+	newImg := normalizeIDs(in.ImageIds)
+
+	var updated dbpkg.Ticket
+	var newImgIDs []uint
+	changed := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var t dbpkg.Ticket
+		if err := tx.First(&t, ticketID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &ErrNotFound{Resource: "ticket"}
+			}
+			return err
+		}
+		if t.UserID != currentUID {
+			return &ErrForbidden{Reason: "only the creator can edit the ticket"}
+		}
+		if t.Status != dbpkg.TicketStatusNew {
+			return &ErrInvalidState{Message: "工单已被受理，无法编辑"}
+		}
+
+		// 分类：保持原分类时即使其已停用也放行
+		cat, msg, err := resolveCategory(tx, in.CategoryId, in.Category)
+		if err != nil {
+			return err
+		}
+		if msg != "" && (cat == nil || t.CategoryID == nil || *t.CategoryID != cat.ID) {
+			return &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"category": msg}}
+		}
+		category := cat.Name
+
+		priority, pmsg := resolveCreatorPriority(in.Priority, in.IsUrgent, t.Priority)
+		if pmsg != "" {
+			return &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"priority": pmsg}}
+		}
+		isUrgent := dbpkg.IsUrgentPriority(priority)
+
+		fieldDetails := map[string]interface{}{}
+		newFields, err := validateFieldValues(tx, cat.ID, in.Fields, fieldDetails)
+		if err != nil {
+			return err
+		}
+		if len(fieldDetails) > 0 {
+			return &ErrValidation{Message: "字段校验失败", Details: fieldDetails}
+		}
+		oldFields, err := currentFieldValues(tx, t.ID)
+		if err != nil {
+			return err
+		}
+		_, oldFieldsByKey, err := ticketFields(tx, t.ID)
+		if err != nil {
+			return err
+		}
+		// 分类不变时，已归档字段的历史值原样保留
+		if t.CategoryID != nil && *t.CategoryID == cat.ID {
+			var archived []uint
+			if err := tx.Model(&dbpkg.CategoryField{}).
+				Where("category_id = ? AND is_archived = ?", cat.ID, true).
+				Pluck("id", &archived).Error; err != nil {
+				return err
+			}
+			for _, fid := range archived {
+				if v, ok := oldFields[fid]; ok {
+					newFields[fid] = v
+				}
+			}
+		}
+
+		oldImg, err := dbpkg.GetTicketImageIDs(tx, t.ID)
+		if err != nil {
+			return err
+		}
+		slices.Sort(oldImg)
+
+		diff := map[string]interface{}{}
+		if t.Title != title {
+			diff["title"] = map[string]interface{}{"from": t.Title, "to": title}
+		}
+		if t.Content != content {
+			diff["content"] = map[string]interface{}{"from": t.Content, "to": content}
+		}
+		if t.Category != category || t.CategoryID == nil || *t.CategoryID != cat.ID {
+			diff["category"] = map[string]interface{}{"from": t.Category, "to": category}
+		}
+		if t.IsUrgent != isUrgent {
+			diff["is_urgent"] = map[string]interface{}{"from": t.IsUrgent, "to": isUrgent}
+		}
+		if t.Priority != priority {
+			diff["priority"] = map[string]interface{}{"from": t.Priority, "to": priority}
+		}
+		if !slices.Equal(oldImg, newImg) {
+			diff["image_ids"] = map[string]interface{}{"from": oldImg, "to": newImg}
+		}
+		fieldsChanged := !maps.Equal(oldFields, newFields)
+		if fieldsChanged {
+			diff["fields"] = map[string]interface{}{"from": oldFieldsByKey}
+		}
+		if len(diff) == 0 {
+			// 内容未变化：不产生新版本
+			updated = t
+			newImgIDs = oldImg
+			return nil
+		}
+
+		if err := checkImagesExist(tx, newImg); err != nil {
+			return err
+		}
+
+		// 1) 保存旧版本
+		var maxVersion int
+		if err := tx.Model(&dbpkg.TicketRevision{}).
+			Where("ticket_id = ?", t.ID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&maxVersion).Error; err != nil {
+			return err
+		}
+		imgJSON, err := json.Marshal(oldImg)
+		if err != nil {
+			return err
+		}
+		fieldsJSON, err := json.Marshal(oldFieldsByKey)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		rev := &dbpkg.TicketRevision{
+			TicketID:  t.ID,
+			Version:   maxVersion + 1,
+			Title:     t.Title,
+			Content:   t.Content,
+			Category:  t.Category,
+			IsUrgent:  t.IsUrgent,
+			Priority:  t.Priority,
+			ImageIDs:  datatypes.JSON(imgJSON),
+			Fields:    datatypes.JSON(fieldsJSON),
+			EditedBy:  currentUID,
+			CreatedAt: now,
+		}
+		if err := tx.Create(rev).Error; err != nil {
+			return err
+		}
+
+		// 2) 覆盖工单（CAS：防止与接单并发）
+		updates := map[string]interface{}{
+			"title":       title,
+			"content":     content,
+			"category":    category,
+			"category_id": cat.ID,
+			"is_urgent":   isUrgent,
+			"priority":    priority,
+			"updated_at":  now,
+		}
+		if t.IsUrgent != isUrgent {
+			updates["sla_due_at"] = dbpkg.TicketSLADue(t.CreatedAt, isUrgent).UTC().Truncate(time.Microsecond)
+		}
+		res := tx.Model(&dbpkg.Ticket{}).
+			Where("id = ? AND status = ?", t.ID, dbpkg.TicketStatusNew).
+			Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return &ErrInvalidState{Message: "工单已被受理，无法编辑"}
+		}
+
+		// 3) 调整图片关联
+		if err := dbpkg.ReplaceTicketImages(tx, t.ID, newImg); err != nil {
+			return err
+		}
+
+		// 4) 替换自定义字段值
+		if fieldsChanged {
+			if err := saveFieldValues(tx, t.ID, newFields, now); err != nil {
+				return err
+			}
+			_, newFieldsByKey, err := ticketFields(tx, t.ID)
+			if err != nil {
+				return err
+			}
+			diff["fields"] = map[string]interface{}{"from": oldFieldsByKey, "to": newFieldsByKey}
+		}
+
+		diff["version"] = rev.Version
+		if err := audit.Record(ctx, tx, currentUID, "ticket.edit", "TICKET", t.ID, diff); err != nil {
+			return err
+		}
+
+		if err := tx.First(&updated, t.ID).Error; err != nil {
+			return err
+		}
+		newImgIDs = newImg
+		changed = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if changed {
+		s.reindex(updated.ID)
+	}
+
+	out := toAPITicket(&updated, newImgIDs)
+	return &out, nil
+}
+
+// listRevisions 按版本号升序返回工单的编辑历史
+func (s *Service) listRevisions(ticketID uint) ([]openapi.TicketRevision, error) {
+	var rows []dbpkg.TicketRevision
+	if err := s.db.Where("ticket_id = ?", ticketID).Order("version ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]openapi.TicketRevision, 0, len(rows))
+	for _, r := range rows {
+		var imgIDs []int32
+		if len(r.ImageIDs) > 0 {
+			if err := json.Unmarshal(r.ImageIDs, &imgIDs); err != nil {
+				return nil, err
+			}
+		}
+		var fields map[string]interface{}
+		if len(r.Fields) > 0 {
+			if err := json.Unmarshal(r.Fields, &fields); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, openapi.TicketRevision{
+			Id:        int32(r.ID),
+			Version:   int32(r.Version),
+			Title:     r.Title,
+			Content:   r.Content,
+			Category:  r.Category,
+			IsUrgent:  r.IsUrgent,
+			Priority:  openapi.TicketPriority(r.Priority),
+			ImageIds:  imgIDs,
+			Fields:    fields,
+			EditedBy:  int32(r.EditedBy),
+			CreatedAt: r.CreatedAt,
+		})
+	}
+	return out, nil
+}
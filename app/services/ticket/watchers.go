@@ -0,0 +1,91 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/openapi"
+
+	"gorm.io/gorm"
+)
+
+// WatchTicket 管理员关注工单；userID 不是本人时视为抄送给该管理员。返回工单当前的关注者列表
+func (s *Service) WatchTicket(ctx context.Context, adminUID, ticketID, userID uint) ([]openapi.TicketWatcher, error) {
+	source := dbpkg.TicketWatcherManual
+	if userID != adminUID {
+		source = dbpkg.TicketWatcherCC
+	}
+
+	var out []openapi.TicketWatcher
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&dbpkg.Ticket{}, ticketID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &ErrNotFound{Resource: "ticket"}
+			}
+			return err
+		}
+		var u dbpkg.User
+		err := tx.Where("id = ? AND role IN ? AND is_active = ?", userID,
+			[]dbpkg.Role{dbpkg.RoleAdmin, dbpkg.RoleSuperAdmin}, true).First(&u).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"user_id": "只能添加在职的管理员"}}
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		added, err := dbpkg.AddTicketWatcher(tx, ticketID, userID, adminUID, source, now)
+		if err != nil {
+			return err
+		}
+		if added {
+			if err := audit.Record(ctx, tx, adminUID, "ticket.watch", "TICKET", ticketID, map[string]interface{}{
+				"user_id": userID, "source": source,
+			}); err != nil {
+				return err
+			}
+		}
+		out, err = watchersFor(tx, ticketID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UnwatchTicket 取消关注（本人取消或移除其他管理员的抄送）
+func (s *Service) UnwatchTicket(ctx context.Context, adminUID, ticketID, userID uint) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("ticket_id = ? AND user_id = ?", ticketID, userID).Delete(&dbpkg.TicketWatcher{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return &ErrNotFound{Resource: "watcher"}
+		}
+		return audit.Record(ctx, tx, adminUID, "ticket.unwatch", "TICKET", ticketID, map[string]interface{}{"user_id": userID})
+	})
+}
+
+// watchersFor 返回工单的关注者（API 结构）
+func watchersFor(d *gorm.DB, ticketID uint) ([]openapi.TicketWatcher, error) {
+	rows, err := dbpkg.ListTicketWatchers(d, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]openapi.TicketWatcher, 0, len(rows))
+	for _, w := range rows {
+		out = append(out, openapi.TicketWatcher{
+			UserId:    int32(w.UserID),
+			Source:    openapi.TicketWatcherSource(w.Source),
+			AddedBy:   int32(w.AddedBy),
+			CreatedAt: w.CreatedAt,
+		})
+	}
+	return out, nil
+}
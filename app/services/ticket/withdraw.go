@@ -0,0 +1,94 @@
+package ticket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"student-services-platform-backend/internal/audit"
+	dbpkg "student-services-platform-backend/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// withdrawableStatuses 创建者可撤回的状态（尚未处理完成）
+var withdrawableStatuses = []dbpkg.TicketStatus{
+	dbpkg.TicketStatusNew,
+	dbpkg.TicketStatusClaimed,
+	dbpkg.TicketStatusInProgress,
+}
+
+// WithdrawTicket 创建者撤回工单（-> CANCELLED，终态），并通知负责的管理员
+func (s *Service) WithdrawTicket(ctx context.Context, currentUID, ticketID uint, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if len([]rune(reason)) > 500 {
+		return &ErrValidation{Message: "字段校验失败", Details: map[string]interface{}{"reason": "长度不能超过 500"}}
+	}
+
+	var t dbpkg.Ticket
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&t, ticketID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return &ErrNotFound{Resource: "ticket"}
+			}
+			return err
+		}
+		if t.UserID != currentUID {
+			return &ErrForbidden{Reason: "only the creator can withdraw the ticket"}
+		}
+
+		// CAS：防止与管理员的处理操作并发
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		res := tx.Model(&dbpkg.Ticket{}).
+			Where("id = ? AND status IN ?", ticketID, withdrawableStatuses).
+			Updates(map[string]interface{}{
+				"status":     dbpkg.TicketStatusCancelled,
+				"updated_at": now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return &ErrInvalidState{Message: fmt.Sprintf("仅 NEW/CLAIMED/IN_PROGRESS 状态的工单可撤回, 当前为 '%s'", t.Status)}
+		}
+
+		diff := map[string]interface{}{
+			"status_from": t.Status,
+			"status_to":   dbpkg.TicketStatusCancelled,
+		}
+		if reason != "" {
+			diff["reason"] = reason
+		}
+		return audit.Record(ctx, tx, currentUID, "ticket.withdraw", "TICKET", ticketID, diff)
+	})
+	if err != nil {
+		return err
+	}
+
+	// 已有负责人时通知负责人
+	if s.notifier != nil && t.AssignedAdminID != nil {
+		go func(t dbpkg.Ticket) {
+			var creator dbpkg.User
+			var handler dbpkg.User
+			if err := s.db.First(&creator, t.UserID).Error; err != nil {
+				return // 静默失败，不影响主流程
+			}
+			if err := s.db.First(&handler, *t.AssignedAdminID).Error; err != nil {
+				return
+			}
+			s.notifier.NotifyTicketWithdrawn(
+				notifyCtx(t.Priority),
+				t.ID,
+				t.Title,
+				reason,
+				creatorDisplayName(&t, &creator),
+				handler.Name,
+				handler.Email,
+			)
+		}(t)
+	}
+
+	return nil
+}
@@ -1,10 +1,14 @@
 package user
 
 import (
+	"context"
 	"fmt"
 
+	"student-services-platform-backend/internal/audit"
 	dbpkg "student-services-platform-backend/internal/db"
 	"student-services-platform-backend/internal/openapi"
+
+	"gorm.io/gorm"
 )
 
 type UpdateFields struct {
@@ -19,12 +23,14 @@ type UpdateFields struct {
 type ErrEmailTaken struct{ Email string }
 func (e *ErrEmailTaken) Error() string { return fmt.Sprintf("邮箱已被占用: %s", e.Email) }
 
-func (s *Service) UpdateByID(id uint, f UpdateFields) (*openapi.User, error) {
+// UpdateByID 用户更新自己的资料；审计日志记录变化的字段
+func (s *Service) UpdateByID(ctx context.Context, id uint, f UpdateFields) (*openapi.User, error) {
 	// 取用户
 	u, err := dbpkg.GetUserByID(s.db, id)
 	if err != nil {
 		return nil, err
 	}
+	before := profileSnapshot(u)
 
 	// email 必填 & 唯一
 	if f.Email == nil || *f.Email == "" {
@@ -63,7 +69,17 @@ func (s *Service) UpdateByID(id uint, f UpdateFields) (*openapi.User, error) {
 		u.AllowEmail = *f.AllowEmail
 	}
 
-	if err := dbpkg.UpdateUser(s.db, u); err != nil {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := dbpkg.UpdateUser(tx, u); err != nil {
+			return err
+		}
+		changes := audit.Changes(before, profileSnapshot(u))
+		if len(changes) == 0 {
+			return nil
+		}
+		return audit.Record(ctx, tx, u.ID, "user.profile_update", "USER", u.ID, changes)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -80,4 +96,22 @@ func (s *Service) UpdateByID(id uint, f UpdateFields) (*openapi.User, error) {
 		UpdatedAt:  u.UpdatedAt,
 	}
 	return &apiUser, nil
-}
\ No newline at end of file
+}
+
+// profileSnapshot 用户可自行修改的资料字段
+func profileSnapshot(u *dbpkg.User) map[string]interface{} {
+	phone, dept := "", ""
+	if u.Phone != nil {
+		phone = *u.Phone
+	}
+	if u.Dept != nil {
+		dept = *u.Dept
+	}
+	return map[string]interface{}{
+		"email":       u.Email,
+		"name":        u.Name,
+		"phone":       phone,
+		"dept":        dept,
+		"allow_email": u.AllowEmail,
+	}
+}
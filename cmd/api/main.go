@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"time"
@@ -10,21 +11,35 @@ import (
 	// API Handlers
 	adminstatsapi "student-services-platform-backend/app/api/adminstats"
 	adminuserapi "student-services-platform-backend/app/api/adminuser"
+	auditlogapi "student-services-platform-backend/app/api/auditlog"
 	authapi "student-services-platform-backend/app/api/auth"
+	availabilityapi "student-services-platform-backend/app/api/availability"
 	cannedapi "student-services-platform-backend/app/api/canned"
+	categoryapi "student-services-platform-backend/app/api/category"
 	imagesapi "student-services-platform-backend/app/api/images"
+	savedviewapi "student-services-platform-backend/app/api/savedview"
+	tagapi "student-services-platform-backend/app/api/tag"
 	ticketapi "student-services-platform-backend/app/api/ticket"
 	userapi "student-services-platform-backend/app/api/user"
 
 	// Router
+	"student-services-platform-backend/app/middleware"
 	"student-services-platform-backend/app/router"
 
+	// Jobs
+	"student-services-platform-backend/app/jobs"
+
 	// Services
 	adminstatssvc "student-services-platform-backend/app/services/adminstats"
 	adminusersvc "student-services-platform-backend/app/services/adminuser"
+	auditlogsvc "student-services-platform-backend/app/services/auditlog"
 	authsvc "student-services-platform-backend/app/services/auth"
+	availabilitysvc "student-services-platform-backend/app/services/availability"
 	cannedsvc "student-services-platform-backend/app/services/canned"
+	categorysvc "student-services-platform-backend/app/services/category"
 	imagessvc "student-services-platform-backend/app/services/images"
+	savedviewsvc "student-services-platform-backend/app/services/savedview"
+	tagsvc "student-services-platform-backend/app/services/tag"
 	ticketsvc "student-services-platform-backend/app/services/ticket"
 	usersvc "student-services-platform-backend/app/services/user"
 
@@ -33,16 +48,37 @@ import (
 	"student-services-platform-backend/internal/email"
 	"student-services-platform-backend/internal/filestore"
 	httpserver "student-services-platform-backend/internal/httpserver"
+	"student-services-platform-backend/internal/scheduler"
 )
 
 func main() {
 	cfg := config.MustLoad()
 	gin.SetMode(cfg.Server.Mode)
 
+	dbpkg.SetAuditChainKey(cfg.Audit.ChainKey)
+	if !dbpkg.AuditChainKeyed() {
+		log.Println("警告：未配置审计日志哈希链密钥（SSP_AUDIT_CHAIN_KEY），整链重算只能通过链头锚定发现，请确保 audit.anchor_file 位于数据库之外的存储")
+	}
+
 	database := dbpkg.MustOpen(cfg.Database)
 	if err := dbpkg.AutoMigrate(database); err != nil {
 		log.Fatalf("db: 自动迁移失败: %v", err)
 	}
+	if err := dbpkg.EnsureSearchIndex(database); err != nil {
+		log.Fatalf("db: 初始化全文检索索引失败: %v", err)
+	}
+	if err := dbpkg.BackfillTicketSLADue(database); err != nil {
+		log.Fatalf("db: 补全工单 SLA 截止时间失败: %v", err)
+	}
+	if err := dbpkg.BackfillTicketPriority(database); err != nil {
+		log.Fatalf("db: 补全工单优先级失败: %v", err)
+	}
+	if err := dbpkg.BackfillTicketCategories(database); err != nil {
+		log.Fatalf("db: 迁移工单分类失败: %v", err)
+	}
+	if err := dbpkg.BackfillAuditChain(database); err != nil {
+		log.Fatalf("db: 补算审计日志哈希链失败: %v", err)
+	}
 	if sqlDB, err := database.DB(); err == nil {
 		defer sqlDB.Close()
 	}
@@ -68,7 +104,9 @@ func main() {
 			TemplatesPath: cfg.Email.TemplatesPath,
 		}
 
-		emailService, err := email.NewService(emailConfig)
+		// 收件人解析感知管理员在岗状态，不在岗的管理员不会收到通知
+		resolver := email.NewDutyAwareRecipientResolver(database, cfg.Duty.Location(), cfg.Email.FromEmail)
+		emailService, err := email.NewServiceWithResolver(emailConfig, resolver)
 		if err != nil {
 			log.Printf("创建邮件服务失败，禁用邮件通知: %v", err)
 		} else if err := emailService.ValidateConfig(); err != nil {
@@ -99,21 +137,40 @@ func main() {
 	} else {
 		ticketSvc = ticketsvc.NewService(database)
 	}
+	ticketSvc.SetDutyLocation(cfg.Duty.Location())
+	editWindow, _ := time.ParseDuration(cfg.Ticket.MessageEditWindow)
+	ticketSvc.SetMessageEditWindow(editWindow)
 	ticketH := ticketapi.New(ticketSvc)
 	imagesH := imagesapi.New(imagessvc.NewService(database, store))
 	adminStatsH := adminstatsapi.New(adminstatssvc.NewService(database))
 	cannedH := cannedapi.New(cannedsvc.NewService(database))
 	adminUserH := adminuserapi.New(adminusersvc.NewService(database))
+	availabilityH := availabilityapi.New(availabilitysvc.NewService(database, cfg.Duty.Location()))
+	savedViewH := savedviewapi.New(savedviewsvc.NewService(database, ticketSvc), ticketSvc)
+	tagH := tagapi.New(tagsvc.NewService(database))
+	categoryH := categoryapi.New(categorysvc.NewService(database))
+	auditLogSvc := auditlogsvc.NewService(database, cfg.Audit.AnchorFile)
+	auditLogH := auditlogapi.New(auditLogSvc)
+
+	// 定时任务（多实例部署时通过数据库锁保证同一任务只在一个实例执行）
+	if cfg.Scheduler.Enabled {
+		sched := scheduler.New(database)
+		if err := jobs.Register(sched, cfg.Scheduler, ticketSvc, auditLogSvc); err != nil {
+			log.Fatalf("scheduler: 注册任务失败: %v", err)
+		}
+		sched.Start(context.Background())
+		defer sched.Stop()
+	}
 
 	r := gin.New()
-	r.Use(gin.Logger(), gin.Recovery(), httpserver.CORS(cfg.CORS))
+	r.Use(gin.Logger(), gin.Recovery(), middleware.RequestMeta(), httpserver.CORS(cfg.CORS))
 
 	api := r.Group("/api/v1")
 	{
 		api.GET("/healthz", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{"ok": true, "ts": time.Now().UTC().Format(time.RFC3339)})
 		})
-		router.Init(api, cfg, database, authH, userH, ticketH, imagesH, adminStatsH, cannedH, adminUserH)
+		router.Init(api, cfg, database, authH, userH, ticketH, imagesH, adminStatsH, cannedH, adminUserH, availabilityH, savedViewH, tagH, categoryH, auditLogH)
 	}
 
 	log.Printf("listening on :%s (mode=%s)", cfg.Server.Port, gin.Mode())
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	auditlogsvc "student-services-platform-backend/app/services/auditlog"
+	"student-services-platform-backend/internal/config"
+	dbpkg "student-services-platform-backend/internal/db"
+)
+
+// 校验审计日志哈希链与锚定文件：go run ./cmd/auditverify [-anchor] [-rekey]
+// 发现断点时以状态码 1 退出，便于放进定时巡检。
+// 首次配置或轮换哈希链密钥（SSP_AUDIT_CHAIN_KEY）后以 -rekey 执行一次，旧密钥通过 SSP_AUDIT_PREVIOUS_CHAIN_KEY 提供（首次启用时留空）
+
+func main() {
+	anchor := flag.Bool("anchor", false, "校验通过后立即将当前链头追加到锚定文件")
+	rekey := flag.Bool("rekey", false, "先按旧密钥校验整条链，再换算到当前密钥（原锚定文件会被归档）")
+	flag.Parse()
+
+	cfg := config.MustLoad()
+	database := dbpkg.MustOpen(cfg.Database)
+	if sqlDB, err := database.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	dbpkg.SetAuditChainKey(cfg.Audit.ChainKey)
+	if !dbpkg.AuditChainKeyed() {
+		log.Println("警告：未配置哈希链密钥（SSP_AUDIT_CHAIN_KEY），整链重算只能通过锚点发现")
+	}
+	svc := auditlogsvc.NewService(database, cfg.Audit.AnchorFile)
+
+	ctx := context.Background()
+	if *rekey {
+		n, err := svc.Rekey(ctx, os.Getenv("SSP_AUDIT_PREVIOUS_CHAIN_KEY"))
+		if err != nil {
+			log.Fatalf("换算哈希链失败: %v", err)
+		}
+		fmt.Printf("已按当前密钥换算 %d 条审计日志\n", n)
+	}
+	res, err := svc.Verify(ctx)
+	if err != nil {
+		log.Fatalf("校验审计日志失败: %v", err)
+	}
+	fmt.Printf("已校验 %d 条审计日志（跳过 %d 条不入链记录），核对 %d 个锚点；链头 id=%d hash=%s\n", res.Checked, res.Unchained, res.AnchorsChecked, res.LastId, res.LastHash)
+	if !res.Ok {
+		fmt.Printf("发现 %d 处问题：\n", res.BreakCount)
+		for _, b := range res.Breaks {
+			fmt.Printf("  id=%d %s expected=%s actual=%s\n", b.Id, b.Kind, b.Expected, b.Actual)
+		}
+		if int(res.BreakCount) > len(res.Breaks) {
+			fmt.Printf("  ……其余 %d 处未列出\n", int(res.BreakCount)-len(res.Breaks))
+		}
+		os.Exit(1)
+	}
+	fmt.Println("哈希链完整")
+
+	if *anchor {
+		a, err := svc.AnchorHead(ctx)
+		if err != nil {
+			log.Fatalf("锚定链头失败: %v", err)
+		}
+		if a != nil {
+			fmt.Printf("已锚定链头 id=%d\n", a.LastID)
+		}
+	}
+}
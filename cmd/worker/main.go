@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"student-services-platform-backend/app/jobs"
+	auditlogsvc "student-services-platform-backend/app/services/auditlog"
+	ticketsvc "student-services-platform-backend/app/services/ticket"
 	"student-services-platform-backend/internal/config"
+	dbpkg "student-services-platform-backend/internal/db"
 	"student-services-platform-backend/internal/email"
+	"student-services-platform-backend/internal/scheduler"
 	"student-services-platform-backend/internal/worker"
 )
 
@@ -16,6 +22,7 @@ import (
 func main() {
 	// 加载配置
 	cfg := config.MustLoad()
+	dbpkg.SetAuditChainKey(cfg.Audit.ChainKey)
 
 	// 创建邮件服务
 	emailConfig := &email.Config{
@@ -51,6 +58,27 @@ func main() {
 
 	log.Println("邮件Worker服务已启动，等待任务...")
 
+	// 定时任务（与 API 实例共享数据库锁，不会重复执行）
+	var sched *scheduler.Scheduler
+	if cfg.Scheduler.Enabled {
+		database, err := dbpkg.Open(cfg.Database)
+		if err != nil {
+			log.Printf("连接数据库失败，禁用定时任务: %v", err)
+		} else {
+			if sqlDB, err := database.DB(); err == nil {
+				defer sqlDB.Close()
+			}
+			ticketSvc := ticketsvc.NewServiceWithNotifier(database, email.NewNotifier(emailService))
+			ticketSvc.SetDutyLocation(cfg.Duty.Location())
+			auditLogSvc := auditlogsvc.NewService(database, cfg.Audit.AnchorFile)
+			sched = scheduler.New(database)
+			if err := jobs.Register(sched, cfg.Scheduler, ticketSvc, auditLogSvc); err != nil {
+				log.Fatalf("注册定时任务失败: %v", err)
+			}
+			sched.Start(context.Background())
+		}
+	}
+
 	// 等待系统信号
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -59,6 +87,9 @@ func main() {
 	log.Println("接收到退出信号，正在关闭...")
 
 	// 优雅关闭
+	if sched != nil {
+		sched.Stop()
+	}
 	workerManager.Shutdown()
 	log.Println("Worker服务已关闭")
 }
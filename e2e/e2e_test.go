@@ -3,6 +3,7 @@ package e2e
 import (
     "bytes"
     "crypto/rand"
+    "database/sql"
     "encoding/hex"
     "fmt"
     "image"
@@ -17,6 +18,7 @@ import (
     "time"
 
     "github.com/gavv/httpexpect/v2"
+    _ "github.com/jackc/pgx/v5/stdlib"
     "github.com/stretchr/testify/require"
 )
 
@@ -72,6 +74,23 @@ func Test_E2E_All(t *testing.T) {
         }
     })
 
+    t.Run("category catalog bootstrap", func(t *testing.T) {
+        // 提单时分类必须存在于目录中
+        for _, name := range []string{"路灯报修", "空调报修"} {
+            s.ensureCategory(t, name)
+        }
+        withAuth(s.E.POST("/api/v1/admin/categories"), s.AdminA.Token).
+            WithJSON(map[string]any{"name": "E2E-" + randHex(4)}).
+            Expect().Status(http.StatusForbidden)
+        arr := s.E.GET("/api/v1/categories").
+            Expect().Status(http.StatusOK).JSON().Object().Value("items").Array()
+        names := map[string]bool{}
+        for i := range arr.Iter() {
+            names[arr.Element(i).Object().Value("name").String().Raw()] = true
+        }
+        require.True(t, names["路灯报修"] && names["空调报修"])
+    })
+
     t.Run("users me profile r/w", func(t *testing.T) {
         obj := withAuth(s.E.GET("/api/v1/users/me"), s.StuA.Token).
             Expect().Status(http.StatusOK).JSON().Object()
@@ -213,6 +232,169 @@ func Test_E2E_All(t *testing.T) {
             Value("status").String().IsEqual("CLOSED")
     })
 
+    t.Run("message edit + redact leaves no body text", func(t *testing.T) {
+        tid := s.createTicket(t, s.StuA.Token, map[string]any{
+            "title": "C-门禁卡失效", "content": "宿舍门禁刷不开", "category": "路灯报修",
+            "is_urgent": false, "is_anonymous": false,
+        })
+        withAuth(s.E.POST(fmt.Sprintf("/api/v1/tickets/%d/claim", tid)), s.AdminA.Token).
+            Expect().Status(http.StatusNoContent)
+        original := "原文-" + randHex(6)
+        edited := "改后-" + randHex(6)
+        msg := withAuth(s.E.POST(fmt.Sprintf("/api/v1/tickets/%d/messages", tid)), s.StuA.Token).
+            WithJSON(map[string]any{"body": "卡号 " + original}).
+            Expect().Status(http.StatusCreated).JSON().Object()
+        mid := int(msg.Value("id").Number().Raw())
+        withAuth(s.E.PATCH(fmt.Sprintf("/api/v1/tickets/%d/messages/%d", tid, mid)), s.StuA.Token).
+            WithJSON(map[string]any{"body": "卡号 " + edited}).
+            Expect().Status(http.StatusOK).
+            JSON().Object().Value("body").String().Contains(edited)
+        withAuth(s.E.GET(fmt.Sprintf("/api/v1/tickets/%d/messages/%d/revisions", tid, mid)), s.AdminA.Token).
+            Expect().Status(http.StatusOK).Body().Contains(original)
+
+        withAuth(s.E.POST(fmt.Sprintf("/api/v1/tickets/%d/messages/%d/redact", tid, mid)), s.AdminA.Token).
+            WithJSON(map[string]any{"reason": "含个人信息"}).
+            Expect().Status(http.StatusForbidden)
+        withAuth(s.E.POST(fmt.Sprintf("/api/v1/tickets/%d/messages/%d/redact", tid, mid)), s.Super.Token).
+            WithJSON(map[string]any{"reason": "含个人信息"}).
+            Expect().Status(http.StatusNoContent)
+
+        reads := []*httpexpect.Request{
+            withAuth(s.E.GET(fmt.Sprintf("/api/v1/tickets/%d/messages", tid)), s.StuA.Token),
+            withAuth(s.E.GET(fmt.Sprintf("/api/v1/tickets/%d/messages", tid)), s.Super.Token),
+            withAuth(s.E.GET(fmt.Sprintf("/api/v1/tickets/%d/messages/%d/revisions", tid, mid)), s.Super.Token),
+            withAuth(s.E.GET(fmt.Sprintf("/api/v1/tickets/%d/timeline", tid)), s.Super.Token),
+            withAuth(s.E.GET("/api/v1/admin/audit-logs").
+                WithQuery("entity", "TICKET").WithQuery("entity_id", tid).WithQuery("page_size", 100), s.Super.Token),
+            withAuth(s.E.GET("/api/v1/admin/audit-logs/export").
+                WithQuery("entity", "TICKET").WithQuery("entity_id", tid), s.Super.Token),
+        }
+        for _, r := range reads {
+            body := r.Expect().Status(http.StatusOK).Body().Raw()
+            require.NotContains(t, body, original)
+            require.NotContains(t, body, edited)
+        }
+        for _, q := range []string{original, edited} {
+            withAuth(s.E.GET("/api/v1/tickets/search").WithQuery("q", q), s.Super.Token).
+                Expect().Status(http.StatusOK).
+                JSON().Object().Value("items").Array().IsEmpty()
+        }
+    })
+
+    t.Run("merge cannot probe anonymous ticket authorship", func(t *testing.T) {
+        anon := s.createTicket(t, s.StuA.Token, map[string]any{
+            "title": "E-匿名反映宿管问题", "content": "匿名反映", "category": "路灯报修",
+            "is_urgent": false, "is_anonymous": true,
+        })
+        own := s.createTicket(t, s.StuA.Token, map[string]any{
+            "title": "E-实名工单", "content": "实名", "category": "路灯报修",
+            "is_urgent": false, "is_anonymous": false,
+        })
+        other := s.createTicket(t, s.StuB.Token, map[string]any{
+            "title": "E-他人工单", "content": "他人", "category": "路灯报修",
+            "is_urgent": false, "is_anonymous": false,
+        })
+        // 合并到他人工单与合并到本人实名工单的失败结果必须一致，不能据此判断提交人
+        toOther := withAuth(s.E.POST(fmt.Sprintf("/api/v1/tickets/%d/merge-into/%d", anon, other)), s.AdminA.Token).
+            Expect().Status(http.StatusBadRequest).Body().Raw()
+        toOwn := withAuth(s.E.POST(fmt.Sprintf("/api/v1/tickets/%d/merge-into/%d", anon, own)), s.AdminA.Token).
+            Expect().Status(http.StatusBadRequest).Body().Raw()
+        require.Equal(t, toOther, toOwn)
+
+        withAuth(s.E.GET(fmt.Sprintf("/api/v1/tickets/%d", anon)), s.AdminA.Token).
+            Expect().Status(http.StatusOK).JSON().Object().
+            Value("status").String().IsEqual("NEW")
+        tl := withAuth(s.E.GET(fmt.Sprintf("/api/v1/tickets/%d/timeline", anon)), s.AdminA.Token).
+            Expect().Status(http.StatusOK).JSON().Object().Value("items").Array()
+        for i := range tl.Iter() {
+            if id, ok := tl.Element(i).Object().Raw()["actor_user_id"]; ok {
+                require.NotEqual(t, float64(s.StuA.ID), id)
+            }
+        }
+    })
+
+    t.Run("follow-up of anonymous ticket stays anonymous", func(t *testing.T) {
+        src := s.createTicket(t, s.StuA.Token, map[string]any{
+            "title": "E-匿名源工单", "content": "匿名反映", "category": "路灯报修",
+            "is_urgent": false, "is_anonymous": true,
+        })
+        for _, step := range []string{"claim", "resolve", "close"} {
+            withAuth(s.E.POST(fmt.Sprintf("/api/v1/tickets/%d/%s", src, step)), s.AdminA.Token).
+                Expect().Status(http.StatusNoContent)
+        }
+        // 请求中显式要求实名，后续工单仍须继承源工单的匿名属性
+        fu := withAuth(s.E.POST(fmt.Sprintf("/api/v1/tickets/%d/follow-up", src)), s.StuA.Token).
+            WithJSON(map[string]any{
+                "title": "E-匿名后续工单", "content": "问题复现", "category": "路灯报修",
+                "is_urgent": false, "is_anonymous": false,
+            }).
+            Expect().Status(http.StatusCreated).JSON().Object()
+        fuID := int(fu.Value("id").Number().Raw())
+
+        got := withAuth(s.E.GET(fmt.Sprintf("/api/v1/tickets/%d", fuID)), s.AdminA.Token).
+            Expect().Status(http.StatusOK).JSON().Object()
+        got.Value("is_anonymous").Boolean().IsTrue()
+        if id, ok := got.Raw()["user_id"]; ok {
+            require.NotEqual(t, float64(s.StuA.ID), id)
+        }
+    })
+
+    t.Run("keyset cursor round-trip per sort key", func(t *testing.T) {
+        for i, p := range []string{"P1", "P2", "P3", "P4"} {
+            if p == "P1" {
+                continue // 学生不能提交 P1
+            }
+            s.createTicket(t, s.StuA.Token, map[string]any{
+                "title": fmt.Sprintf("D-%d-路灯不亮", i), "content": "路灯", "category": "路灯报修",
+                "is_urgent": p == "P2", "is_anonymous": false, "priority": p,
+            })
+        }
+        for _, key := range []string{"created_at", "updated_at", "urgency", "priority", "sla_due"} {
+            for _, order := range []string{"asc", "desc"} {
+                // 基准：页码分页一次取全（学生只能看到自己的工单，数量可控）
+                all := withAuth(s.E.GET("/api/v1/tickets").
+                    WithQuery("sort", key).WithQuery("order", order).WithQuery("page_size", 100), s.StuA.Token).
+                    Expect().Status(http.StatusOK).JSON().Object().Value("items").Array()
+                var want []int
+                for i := range all.Iter() {
+                    want = append(want, int(all.Element(i).Object().Value("id").Number().Raw()))
+                }
+                require.GreaterOrEqual(t, len(want), 4)
+
+                var got []int
+                cursor := ""
+                for pages := 0; ; pages++ {
+                    require.Less(t, pages, 50, "cursor walk did not terminate (%s %s)", key, order)
+                    obj := withAuth(s.E.GET("/api/v1/tickets").
+                        WithQuery("sort", key).WithQuery("order", order).
+                        WithQuery("page_size", 2).WithQuery("cursor", cursor), s.StuA.Token).
+                        Expect().Status(http.StatusOK).JSON().Object()
+                    items := obj.Value("items").Array()
+                    for i := range items.Iter() {
+                        got = append(got, int(items.Element(i).Object().Value("id").Number().Raw()))
+                    }
+                    next, _ := obj.Raw()["next_cursor"].(string)
+                    if next == "" {
+                        break
+                    }
+                    cursor = next
+                }
+                require.Equal(t, want, got, "cursor pages should match offset order (%s %s)", key, order)
+
+                if cursor != "" {
+                    // 游标与排序方式绑定，换排序键复用应被拒绝
+                    other := "created_at"
+                    if key == other {
+                        other = "updated_at"
+                    }
+                    withAuth(s.E.GET("/api/v1/tickets").
+                        WithQuery("sort", other).WithQuery("order", order).WithQuery("cursor", cursor), s.StuA.Token).
+                        Expect().Status(http.StatusBadRequest)
+                }
+            }
+        }
+    })
+
     t.Run("spam flow: flag -> super review approve -> auto reply", func(t *testing.T) {
         withAuth(s.E.POST(fmt.Sprintf("/api/v1/tickets/%d/spam-flag", ticketB)), s.AdminB.Token).
             WithJSON(map[string]any{"reason": "疑似广告"}).
@@ -272,6 +454,63 @@ func Test_E2E_All(t *testing.T) {
         stats.Value("daily_trend").Array()
         stats.Value("admin_workload").Array()
     })
+
+    t.Run("audit hash chain verify + tamper detection", func(t *testing.T) {
+        // 失败登录不入链，不应影响校验
+        s.E.POST("/api/v1/auth/login").
+            WithJSON(map[string]any{"email": s.StuA.Email, "password": "wrong-" + randomDigits(4)}).
+            Expect().Status(http.StatusUnauthorized)
+        withAuth(s.E.GET("/api/v1/admin/audit-logs/verify"), s.AdminA.Token).
+            Expect().Status(http.StatusForbidden)
+        rep := withAuth(s.E.GET("/api/v1/admin/audit-logs/verify"), s.Super.Token).
+            Expect().Status(http.StatusOK).JSON().Object()
+        rep.Value("ok").Boolean().IsTrue()
+        rep.Value("break_count").Number().IsEqual(0)
+        require.Positive(t, rep.Value("checked").Number().Raw())
+
+        dsn := os.Getenv("E2E_DATABASE_DSN")
+        if dsn == "" {
+            t.Log("[skip] E2E_DATABASE_DSN not set; skip tamper detection (needs direct postgres access)")
+            return
+        }
+        db, err := sql.Open("pgx", dsn)
+        require.NoError(t, err)
+        defer db.Close()
+
+        row := withAuth(s.E.GET("/api/v1/admin/audit-logs").
+            WithQuery("entity", "TICKET").WithQuery("entity_id", ticketA).WithQuery("page_size", 1), s.Super.Token).
+            Expect().Status(http.StatusOK).JSON().Object().Value("items").Array().Element(0).Object()
+        auditID := int(row.Value("id").Number().Raw())
+
+        // 直接改库模拟篡改，结束时还原
+        _, err = db.Exec("UPDATE audit_logs SET entity_id = entity_id + 1000000 WHERE id = $1", auditID)
+        require.NoError(t, err)
+        restored := false
+        defer func() {
+            if !restored {
+                _, _ = db.Exec("UPDATE audit_logs SET entity_id = entity_id - 1000000 WHERE id = $1", auditID)
+            }
+        }()
+        bad := withAuth(s.E.GET("/api/v1/admin/audit-logs/verify"), s.Super.Token).
+            Expect().Status(http.StatusOK).JSON().Object()
+        bad.Value("ok").Boolean().IsFalse()
+        found := false
+        breaks := bad.Value("breaks").Array()
+        for i := range breaks.Iter() {
+            b := breaks.Element(i).Object().Raw()
+            if int(b["id"].(float64)) == auditID && b["kind"] == "hash_mismatch" {
+                found = true
+            }
+        }
+        require.True(t, found, "篡改的审计日志 %d 应报告 hash_mismatch", auditID)
+
+        _, err = db.Exec("UPDATE audit_logs SET entity_id = entity_id - 1000000 WHERE id = $1", auditID)
+        require.NoError(t, err)
+        restored = true
+        withAuth(s.E.GET("/api/v1/admin/audit-logs/verify"), s.Super.Token).
+            Expect().Status(http.StatusOK).JSON().Object().
+            Value("ok").Boolean().IsTrue()
+    })
 }
 
 /* ----------------------------- helpers ------------------------------ */
@@ -396,6 +635,20 @@ func (s *scenario) uploadImage(t *testing.T, token, filename string, data []byte
     }
 }
 
+func (s *scenario) ensureCategory(t *testing.T, name string) {
+    arr := s.E.GET("/api/v1/categories").
+        Expect().Status(http.StatusOK).JSON().Object().Value("items").Array()
+    for i := range arr.Iter() {
+        if arr.Element(i).Object().Value("name").String().Raw() == name {
+            return
+        }
+    }
+    withAuth(s.E.POST("/api/v1/admin/categories"), s.Super.Token).
+        WithJSON(map[string]any{"name": name}).
+        Expect().Status(http.StatusCreated)
+    t.Logf("[category] created %s", name)
+}
+
 func (s *scenario) createTicket(t *testing.T, token string, payload map[string]any) int {
     obj := withAuth(s.E.POST("/api/v1/tickets"), token).
         WithJSON(payload).
@@ -0,0 +1,85 @@
+// Package audit 提供各业务服务共用的审计日志写入。
+// 审计记录与业务修改在同一事务中写入；请求元数据（客户端 IP、请求 ID）由 HTTP 中间件放入 context，
+// 写入时自动带上，后台任务等没有请求的场景留空。
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	dbpkg "student-services-platform-backend/internal/db"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Meta 请求元数据
+type Meta struct {
+	IP        string
+	RequestID string
+}
+
+type metaKey struct{}
+
+// WithMeta 将请求元数据放入 context
+func WithMeta(ctx context.Context, m Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, m)
+}
+
+// MetaFrom 从 context 取出请求元数据；没有时返回零值
+func MetaFrom(ctx context.Context) Meta {
+	if ctx == nil {
+		return Meta{}
+	}
+	m, _ := ctx.Value(metaKey{}).(Meta)
+	return m
+}
+
+// Record 在 tx（可以是事务，也可以是普通连接）中写入一条审计日志。
+// actorID 为 0 表示匿名或系统操作；diff 为 nil 时不记录变更内容
+func Record(ctx context.Context, tx *gorm.DB, actorID uint, action, entity string, entityID uint, diff map[string]interface{}) error {
+	var diffJSON datatypes.JSON
+	if diff != nil {
+		b, err := json.Marshal(diff)
+		if err != nil {
+			return fmt.Errorf("序列化diff失败: %w", err)
+		}
+		diffJSON = datatypes.JSON(b)
+	}
+	m := MetaFrom(ctx)
+	return tx.WithContext(ctx).Create(&dbpkg.AuditLog{
+		ActorUserID: actorID,
+		Action:      action,
+		Entity:      entity,
+		EntityID:    entityID,
+		Diff:        diffJSON,
+		IP:          m.IP,
+		RequestID:   m.RequestID,
+		CreatedAt:   time.Now().UTC().Truncate(time.Microsecond),
+	}).Error
+}
+
+// Change 单个字段的变更，序列化为 {"from": ..., "to": ...}
+func Change(from, to interface{}) map[string]interface{} {
+	return map[string]interface{}{"from": from, "to": to}
+}
+
+// Changes 比较修改前后的字段快照，返回发生变化的字段 {字段: {"from", "to"}}；
+// 只出现在一侧的字段按另一侧为 nil 处理。没有变化时返回空 map
+func Changes(before, after map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for k, b := range before {
+		if a := after[k]; !reflect.DeepEqual(b, a) {
+			out[k] = Change(b, a)
+		}
+	}
+	for k, a := range after {
+		if _, ok := before[k]; !ok && a != nil {
+			out[k] = Change(nil, a)
+		}
+	}
+	return out
+}
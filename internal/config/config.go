@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -84,6 +85,51 @@ type FileStoreConfig struct {
 	Root string `mapstructure:"root"`
 }
 
+// 值班配置
+type DutyConfig struct {
+	// 班次所使用的时区（IANA 名称），例如 "Asia/Shanghai"
+	Timezone string `mapstructure:"timezone"`
+}
+
+// Location 返回值班时区；无法加载时回退到 UTC
+func (c DutyConfig) Location() *time.Location {
+	if c.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		log.Printf("config: 无法加载值班时区 %q，回退到 UTC: %v", c.Timezone, err)
+		return time.UTC
+	}
+	return loc
+}
+
+// 定时任务配置
+type SchedulerConfig struct {
+	// 是否在本进程启动定时任务（多实例同时开启也只会有一个实例执行同一任务）
+	Enabled bool `mapstructure:"enabled"`
+	// 已处理工单在多少天内学生无回复则自动关闭；<= 0 表示禁用自动关闭
+	AutoCloseAfterDays int `mapstructure:"auto_close_after_days"`
+	// 自动关闭任务的执行间隔，例如 "1h"
+	AutoCloseInterval string `mapstructure:"auto_close_interval"`
+	// 审计日志链头锚定任务的执行间隔，例如 "1h"
+	AuditAnchorInterval string `mapstructure:"audit_anchor_interval"`
+}
+
+// 审计日志配置
+type AuditConfig struct {
+	// 链头锚定文件（只追加写入的 JSON Lines）；应放在与数据库不同的存储上，留空表示禁用锚定
+	AnchorFile string `mapstructure:"anchor_file"`
+	// 哈希链 HMAC 密钥，只通过环境变量 SSP_AUDIT_CHAIN_KEY 提供，不要写进配置文件或数据库；留空时哈希不带密钥
+	ChainKey string `mapstructure:"chain_key"`
+}
+
+// 工单配置
+type TicketConfig struct {
+	// 发送者可编辑消息的时限（自发送起），例如 "15m"
+	MessageEditWindow string `mapstructure:"message_edit_window"`
+}
+
 type Config struct {
 	Server    ServerConfig    `mapstructure:"server"`
 	CORS      CORSConfig      `mapstructure:"cors"`
@@ -94,6 +140,10 @@ type Config struct {
 	Worker    WorkerConfig    `mapstructure:"worker"`
 	FileStore FileStoreConfig `mapstructure:"filestore"`
 	Frontend  FrontendConfig  `mapstructure:"frontend"`
+	Duty      DutyConfig      `mapstructure:"duty"`
+	Scheduler SchedulerConfig `mapstructure:"scheduler"`
+	Audit     AuditConfig     `mapstructure:"audit"`
+	Ticket    TicketConfig    `mapstructure:"ticket"`
 }
 
 func defaults(v *viper.Viper) {
@@ -102,7 +152,7 @@ func defaults(v *viper.Viper) {
 
 	v.SetDefault("cors.allowed_origins", []string{"*"})
 	v.SetDefault("cors.allowed_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
-	v.SetDefault("cors.allowed_headers", []string{"Authorization", "Content-Type", "X-Requested-With"})
+	v.SetDefault("cors.allowed_headers", []string{"Authorization", "Content-Type", "X-Requested-With", "X-Request-ID"})
 	v.SetDefault("cors.allow_credentials", true)
 
 	v.SetDefault("database.driver", "postgres")
@@ -144,6 +194,22 @@ func defaults(v *viper.Viper) {
 
 	// 前端配置默认值
 	v.SetDefault("frontend.base_url", "http://localhost:3000")
+
+	// 值班配置默认值
+	v.SetDefault("duty.timezone", "Asia/Shanghai")
+
+	// 定时任务默认值
+	v.SetDefault("scheduler.enabled", true)
+	v.SetDefault("scheduler.auto_close_after_days", 7)
+	v.SetDefault("scheduler.auto_close_interval", "1h")
+	v.SetDefault("scheduler.audit_anchor_interval", "1h")
+
+	// 审计日志默认值
+	v.SetDefault("audit.anchor_file", "data/audit/anchors.jsonl")
+	v.SetDefault("audit.chain_key", "")
+
+	// 工单默认值
+	v.SetDefault("ticket.message_edit_window", "15m")
 }
 
 // Load 从以下位置返回一个配置（按优先级顺序）：
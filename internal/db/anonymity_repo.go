@@ -0,0 +1,110 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// AnonymousHandle 匿名工单中学生的代号：按工单生成，不同工单之间无法据此关联到同一学生
+func AnonymousHandle(ticketID uint) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("anonymous-ticket:%d", ticketID)))
+	return "匿名学生-" + hex.EncodeToString(sum[:3])
+}
+
+// anonymousTicketsOf 子查询：userID 创建的匿名工单 ID
+func anonymousTicketsOf(d *gorm.DB, userID uint) *gorm.DB {
+	return d.Session(&gorm.Session{NewDB: true}).Model(&Ticket{}).
+		Select("id").
+		Where("user_id = ? AND is_anonymous = ?", userID, true)
+}
+
+// anonymousImagesOf 子查询：userID 附在其匿名工单上的图片 ID（工单附件与本人消息的附件）
+func anonymousImagesOf(d *gorm.DB, userID uint) (ticketImages, messageImages *gorm.DB) {
+	ticketImages = d.Session(&gorm.Session{NewDB: true}).Model(&TicketImage{}).
+		Select("image_id").
+		Where("ticket_id IN (?)", anonymousTicketsOf(d, userID))
+	messageImages = d.Session(&gorm.Session{NewDB: true}).Table("message_attachments ma").
+		Select("ma.image_id").
+		Joins("JOIN ticket_messages m ON m.id = ma.message_id").
+		Where("m.sender_user_id = ? AND m.ticket_id IN (?)", userID, anonymousTicketsOf(d, userID))
+	return ticketImages, messageImages
+}
+
+// AnonymousAuditRows 找出审计日志中属于“创建者在其匿名工单上的操作”的记录：
+// 实体为该匿名工单，或为创建者附在该工单（含其消息）上的图片。返回 map[审计日志ID]匿名工单ID
+func AnonymousAuditRows(d *gorm.DB, rows []AuditLog) (map[uint]uint, error) {
+	out := map[uint]uint{}
+	var ticketIDs, imageIDs []uint
+	for _, r := range rows {
+		switch r.Entity {
+		case "TICKET":
+			ticketIDs = append(ticketIDs, r.EntityID)
+		case "IMAGE":
+			imageIDs = append(imageIDs, r.EntityID)
+		}
+	}
+
+	// 匿名工单 → 创建者
+	creators := map[uint]uint{}
+	if len(ticketIDs) > 0 {
+		var tickets []Ticket
+		if err := d.Select("id", "user_id").
+			Where("id IN ? AND is_anonymous = ?", ticketIDs, true).
+			Find(&tickets).Error; err != nil {
+			return nil, err
+		}
+		for _, t := range tickets {
+			creators[t.ID] = t.UserID
+		}
+	}
+
+	// 图片 → 附有该图片的匿名工单（只计创建者本人附上的）
+	type imageOwner struct {
+		ImageID  uint
+		TicketID uint
+		UserID   uint
+	}
+	imageOwners := map[uint][]imageOwner{}
+	if len(imageIDs) > 0 {
+		var owners []imageOwner
+		if err := d.Table("ticket_images ti").
+			Select("ti.image_id, t.id AS ticket_id, t.user_id").
+			Joins("JOIN tickets t ON t.id = ti.ticket_id").
+			Where("ti.image_id IN ? AND t.is_anonymous = ?", imageIDs, true).
+			Scan(&owners).Error; err != nil {
+			return nil, err
+		}
+		var fromMessages []imageOwner
+		if err := d.Table("message_attachments ma").
+			Select("ma.image_id, t.id AS ticket_id, t.user_id").
+			Joins("JOIN ticket_messages m ON m.id = ma.message_id").
+			Joins("JOIN tickets t ON t.id = m.ticket_id").
+			Where("ma.image_id IN ? AND t.is_anonymous = ? AND m.sender_user_id = t.user_id", imageIDs, true).
+			Scan(&fromMessages).Error; err != nil {
+			return nil, err
+		}
+		for _, o := range append(owners, fromMessages...) {
+			imageOwners[o.ImageID] = append(imageOwners[o.ImageID], o)
+		}
+	}
+
+	for _, r := range rows {
+		switch r.Entity {
+		case "TICKET":
+			if uid, ok := creators[r.EntityID]; ok && uid == r.ActorUserID {
+				out[r.ID] = r.EntityID
+			}
+		case "IMAGE":
+			for _, o := range imageOwners[r.EntityID] {
+				if o.UserID == r.ActorUserID {
+					out[r.ID] = o.TicketID
+					break
+				}
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,380 @@
+package db
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AuditChainGenesis 哈希链第一条记录的 PrevHash
+var AuditChainGenesis = strings.Repeat("0", 64)
+
+// auditChainHeadID 链头表中唯一一行的主键
+const auditChainHeadID = 1
+
+// auditChainKey 计算哈希链使用的 HMAC 密钥（来自配置，不落库）；为空时退化为不带密钥的 sha256
+var auditChainKey []byte
+
+// SetAuditChainKey 设置哈希链密钥，需在写入或校验审计日志之前调用。
+// 能改写数据库的人拿不到密钥就无法在篡改后重算出有效的链；未配置密钥时整链重算只能依靠外部锚点发现
+func SetAuditChainKey(key string) {
+	auditChainKey = []byte(key)
+}
+
+// AuditChainKeyed 是否配置了哈希链密钥
+func AuditChainKeyed() bool { return len(auditChainKey) > 0 }
+
+// 哈希链校验发现的问题类型
+const (
+	AuditBreakPrevHash = "prev_hash_mismatch" // PrevHash 与上一条记录的哈希不一致（中间记录被删除、插入或重排）
+	AuditBreakHash     = "hash_mismatch"      // 记录内容与自身哈希不一致（记录被修改）
+	AuditBreakHead     = "head_mismatch"      // 链头与最后一条记录不一致（尾部记录被删除）
+)
+
+// unchainedAuditActions 不进入哈希链的审计动作：未登录即可触发、量大且无需防篡改，
+// 若参与链接会让任何人都能通过反复失败登录争抢链头锁，拖慢所有写入
+var unchainedAuditActions = map[string]bool{
+	"auth.login_failed": true,
+}
+
+// inChain 记录是否参与哈希链；已有哈希的记录（包括此前写入的失败登录）始终参与，
+// 把链上记录的哈希清空伪装成不入链的记录会使下一条的 PrevHash 对不上
+func (a *AuditLog) inChain() bool {
+	return a.Hash != "" || a.PrevHash != "" || !unchainedAuditActions[a.Action]
+}
+
+// AuditBreakLimit 校验报告中最多列出的问题条数（BreakCount 仍为总数）；锚点核对等追加的问题共用此上限
+const AuditBreakLimit = 100
+
+// ComputeHash 计算审计日志的哈希：HMAC-SHA256(密钥, PrevHash + 规范化后的内容)，未配置密钥时为 sha256。
+// Diff 先规范化（键排序、去空白）再参与计算，避免 jsonb 改写格式导致误报；时间统一为 UTC 微秒精度。
+// IP 与请求 ID 为空时不参与计算，早于请求元数据记录的审计日志哈希保持不变
+func (a *AuditLog) ComputeHash() string {
+	return a.computeHash(auditChainKey)
+}
+
+// computeHash 按指定密钥计算哈希（key 为空时不带密钥）
+func (a *AuditLog) computeHash(key []byte) string {
+	diff, err := canonicalJSON(a.Diff)
+	if err != nil {
+		diff = []byte(a.Diff) // 无法解析的内容按原样计算，校验时同样会得到这个结果
+	}
+	payload, _ := json.Marshal(struct {
+		PrevHash    string          `json:"prev_hash"`
+		ActorUserID uint            `json:"actor_user_id"`
+		Action      string          `json:"action"`
+		Entity      string          `json:"entity"`
+		EntityID    uint            `json:"entity_id"`
+		Diff        json.RawMessage `json:"diff"`
+		IP          string          `json:"ip,omitempty"`
+		RequestID   string          `json:"request_id,omitempty"`
+		CreatedAt   string          `json:"created_at"`
+	}{
+		PrevHash:    a.PrevHash,
+		ActorUserID: a.ActorUserID,
+		Action:      a.Action,
+		Entity:      a.Entity,
+		EntityID:    a.EntityID,
+		Diff:        diff,
+		IP:          a.IP,
+		RequestID:   a.RequestID,
+		CreatedAt:   a.CreatedAt.UTC().Format("2006-01-02T15:04:05.000000Z"),
+	})
+	var h hash.Hash
+	if len(key) > 0 {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// canonicalJSON 规范化 JSON：对象键排序、去除空白、数字保持原文；空内容视为 null
+func canonicalJSON(raw []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return []byte("null"), nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// BeforeCreate 锁定链头并为新记录填入 PrevHash 与 Hash。
+// 链头行锁持有到事务结束，保证 ID 分配顺序与链顺序一致，因此审计日志应作为事务中最后的写入；
+// 审计日志需逐条写入（不支持批量 Create）。不入链的动作不加锁，哈希留空
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = time.Now()
+	}
+	a.CreatedAt = a.CreatedAt.UTC().Truncate(time.Microsecond)
+	if unchainedAuditActions[a.Action] {
+		a.PrevHash, a.Hash = "", ""
+		return nil
+	}
+	head, err := lockAuditChainHead(tx.Session(&gorm.Session{NewDB: true}))
+	if err != nil {
+		return err
+	}
+	a.PrevHash = head.Hash
+	if a.PrevHash == "" {
+		a.PrevHash = AuditChainGenesis
+	}
+	a.Hash = a.ComputeHash()
+	return nil
+}
+
+// AfterCreate 将链头推进到新记录
+func (a *AuditLog) AfterCreate(tx *gorm.DB) error {
+	if !a.inChain() {
+		return nil
+	}
+	return tx.Session(&gorm.Session{NewDB: true}).
+		Model(&AuditChainHead{}).
+		Where("id = ?", auditChainHeadID).
+		Updates(map[string]interface{}{
+			"last_id":    a.ID,
+			"hash":       a.Hash,
+			"updated_at": time.Now().UTC(),
+		}).Error
+}
+
+// lockAuditChainHead 读取并锁定链头（不存在时先创建）；SQLite 不支持行锁，依靠其写事务串行化
+func lockAuditChainHead(tx *gorm.DB) (*AuditChainHead, error) {
+	var head AuditChainHead
+	res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Limit(1).Find(&head, "id = ?", auditChainHeadID)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected > 0 {
+		return &head, nil
+	}
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&AuditChainHead{ID: auditChainHeadID, UpdatedAt: time.Now().UTC()}).Error
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&head, "id = ?", auditChainHeadID).Error; err != nil {
+		return nil, err
+	}
+	return &head, nil
+}
+
+// GetAuditChainHead 读取链头；链尚未开始时返回 LastID 为 0 的空链头
+func GetAuditChainHead(d *gorm.DB) (*AuditChainHead, error) {
+	var head AuditChainHead
+	err := d.First(&head, "id = ?", auditChainHeadID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &AuditChainHead{ID: auditChainHeadID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &head, nil
+}
+
+// BackfillAuditChain 为启用哈希链之前的历史审计日志按 ID 顺序补算哈希链。
+// 仅在链尚未开始（链头为空）时执行；链开始后出现的无哈希记录交由校验报告
+func BackfillAuditChain(d *gorm.DB) error {
+	return d.Transaction(func(tx *gorm.DB) error {
+		head, err := lockAuditChainHead(tx)
+		if err != nil {
+			return err
+		}
+		if head.LastID != 0 {
+			return nil
+		}
+		prev := AuditChainGenesis
+		var lastID, chainedID uint
+		for {
+			var rows []AuditLog
+			if err := tx.Where("id > ?", lastID).Order("id ASC").Limit(500).Find(&rows).Error; err != nil {
+				return err
+			}
+			if len(rows) == 0 {
+				break
+			}
+			for i := range rows {
+				r := &rows[i]
+				lastID = r.ID
+				if !r.inChain() {
+					continue
+				}
+				r.CreatedAt = r.CreatedAt.UTC().Truncate(time.Microsecond)
+				r.PrevHash = prev
+				r.Hash = r.ComputeHash()
+				err := tx.Model(&AuditLog{}).Where("id = ?", r.ID).
+					UpdateColumns(map[string]interface{}{
+						"prev_hash":  r.PrevHash,
+						"hash":       r.Hash,
+						"created_at": r.CreatedAt,
+					}).Error
+				if err != nil {
+					return err
+				}
+				prev, chainedID = r.Hash, r.ID
+			}
+		}
+		if chainedID == 0 {
+			return nil
+		}
+		return tx.Model(&AuditChainHead{}).Where("id = ?", auditChainHeadID).
+			Updates(map[string]interface{}{"last_id": chainedID, "hash": prev, "updated_at": time.Now().UTC()}).Error
+	})
+}
+
+// RekeyAuditChain 将整条哈希链从 oldKey（为空表示不带密钥）换算到当前配置的密钥，用于首次启用或轮换密钥。
+// 先按旧密钥完整校验，发现任何问题都不换算，以免把篡改过的记录重新“洗白”；成功时返回换算的记录数
+func RekeyAuditChain(d *gorm.DB, oldKey string) (int64, error) {
+	old := []byte(oldKey)
+	var n int64
+	err := d.Transaction(func(tx *gorm.DB) error {
+		head, err := lockAuditChainHead(tx)
+		if err != nil {
+			return err
+		}
+		oldPrev, newPrev := AuditChainGenesis, AuditChainGenesis
+		var lastID, chainedID uint
+		for {
+			var rows []AuditLog
+			if err := tx.Where("id > ?", lastID).Order("id ASC").Limit(500).Find(&rows).Error; err != nil {
+				return err
+			}
+			if len(rows) == 0 {
+				break
+			}
+			for i := range rows {
+				r := &rows[i]
+				lastID = r.ID
+				if !r.inChain() {
+					continue
+				}
+				if r.PrevHash != oldPrev || r.computeHash(old) != r.Hash {
+					return fmt.Errorf("审计日志 %d 未通过旧密钥校验，已中止换算", r.ID)
+				}
+				oldPrev = r.Hash
+				r.PrevHash = newPrev
+				r.Hash = r.ComputeHash()
+				if err := tx.Model(&AuditLog{}).Where("id = ?", r.ID).
+					UpdateColumns(map[string]interface{}{"prev_hash": r.PrevHash, "hash": r.Hash}).Error; err != nil {
+					return err
+				}
+				newPrev, chainedID = r.Hash, r.ID
+				n++
+			}
+		}
+		if head.LastID != chainedID || (chainedID > 0 && head.Hash != oldPrev) {
+			return fmt.Errorf("链头与最后一条审计日志不一致，已中止换算")
+		}
+		if n == 0 {
+			return nil
+		}
+		return tx.Model(&AuditChainHead{}).Where("id = ?", auditChainHeadID).
+			Updates(map[string]interface{}{"hash": newPrev, "updated_at": time.Now().UTC()}).Error
+	})
+	return n, err
+}
+
+// AuditChainBreak 哈希链校验发现的一处问题
+type AuditChainBreak struct {
+	ID       uint   // 出问题的审计日志 ID（链头问题为链头记录的 LastID）
+	Kind     string // AuditBreak*
+	Expected string
+	Actual   string
+}
+
+// AuditChainReport 哈希链校验结果
+type AuditChainReport struct {
+	Checked    int64  // 校验的记录数
+	Unchained  int64  // 跳过的不入链记录数（如失败登录）
+	LastID     uint   // 链上最后一条记录的 ID
+	LastHash   string // 链上最后一条记录的哈希
+	BreakCount int    // 问题总数
+	Breaks     []AuditChainBreak
+}
+
+func (r *AuditChainReport) addBreak(b AuditChainBreak) {
+	r.BreakCount++
+	if len(r.Breaks) < AuditBreakLimit {
+		r.Breaks = append(r.Breaks, b)
+	}
+}
+
+// VerifyAuditChain 按 ID 顺序分批遍历审计日志，校验每条记录的 PrevHash 与 Hash，最后核对链头。
+// 某条记录被修改时只报告这一条（后续记录仍以其存储的哈希继续校验）
+func VerifyAuditChain(d *gorm.DB, batchSize int) (*AuditChainReport, error) {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	rep := &AuditChainReport{Breaks: []AuditChainBreak{}}
+	prev := AuditChainGenesis
+	var lastID, chainedID uint
+	for {
+		var rows []AuditLog
+		if err := d.Where("id > ?", lastID).Order("id ASC").Limit(batchSize).Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		for i := range rows {
+			r := &rows[i]
+			lastID = r.ID
+			if !r.inChain() {
+				rep.Unchained++
+				continue
+			}
+			if r.PrevHash != prev {
+				rep.addBreak(AuditChainBreak{ID: r.ID, Kind: AuditBreakPrevHash, Expected: prev, Actual: r.PrevHash})
+			}
+			if h := r.ComputeHash(); h != r.Hash {
+				rep.addBreak(AuditChainBreak{ID: r.ID, Kind: AuditBreakHash, Expected: h, Actual: r.Hash})
+			}
+			prev, chainedID = r.Hash, r.ID
+			rep.Checked++
+		}
+	}
+	rep.LastID = chainedID
+	if chainedID > 0 {
+		rep.LastHash = prev
+	}
+
+	head, err := GetAuditChainHead(d)
+	if err != nil {
+		return nil, err
+	}
+	if head.LastID != rep.LastID || head.Hash != rep.LastHash {
+		rep.addBreak(AuditChainBreak{ID: head.LastID, Kind: AuditBreakHead, Expected: head.Hash, Actual: rep.LastHash})
+	}
+	return rep, nil
+}
+
+// GetAuditLogHashes 批量读取审计日志的哈希 map[id]=>hash（不存在的 ID 不出现在结果中）
+func GetAuditLogHashes(d *gorm.DB, ids []uint) (map[uint]string, error) {
+	out := make(map[uint]string, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	var rows []AuditLog
+	if err := d.Select("id", "hash").Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		out[r.ID] = r.Hash
+	}
+	return out, nil
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter 审计日志筛选条件（所有字段可选）
+type AuditLogFilter struct {
+	ActorID   *uint
+	Actions   []string // 精确匹配；以 ".*" 结尾的按前缀匹配，例如 ticket.*
+	Entity    string
+	EntityID  *uint
+	RequestID string
+	From      *time.Time // 含
+	To        *time.Time // 不含
+}
+
+// auditLogQuery 在 audit_logs 上叠加筛选条件
+func auditLogQuery(d *gorm.DB, f AuditLogFilter) *gorm.DB {
+	q := d.Model(&AuditLog{})
+	if f.ActorID != nil {
+		// 按操作人筛选时排除其在本人匿名工单上的操作，否则结果本身就把匿名工单与提交人对应了起来
+		ticketImages, messageImages := anonymousImagesOf(d, *f.ActorID)
+		q = q.Where("actor_user_id = ?", *f.ActorID).
+			Where("NOT (entity = ? AND entity_id IN (?))", "TICKET", anonymousTicketsOf(d, *f.ActorID)).
+			Where("NOT (entity = ? AND (entity_id IN (?) OR entity_id IN (?)))", "IMAGE", ticketImages, messageImages)
+	}
+	if len(f.Actions) > 0 {
+		var conds []string
+		var args []interface{}
+		for _, a := range f.Actions {
+			if prefix, ok := strings.CutSuffix(a, ".*"); ok {
+				conds = append(conds, "action LIKE ? ESCAPE '!'")
+				args = append(args, escapeLikePattern(prefix)+".%")
+			} else {
+				conds = append(conds, "action = ?")
+				args = append(args, a)
+			}
+		}
+		q = q.Where("("+strings.Join(conds, " OR ")+")", args...)
+	}
+	if f.Entity != "" {
+		q = q.Where("entity = ?", f.Entity)
+	}
+	if f.EntityID != nil {
+		q = q.Where("entity_id = ?", *f.EntityID)
+	}
+	if f.RequestID != "" {
+		q = q.Where("request_id = ?", f.RequestID)
+	}
+	if f.From != nil {
+		q = q.Where("created_at >= ?", f.From.UTC())
+	}
+	if f.To != nil {
+		q = q.Where("created_at < ?", f.To.UTC())
+	}
+	return q
+}
+
+// escapeLikePattern 转义 LIKE 通配符（配合 ESCAPE '!' 使用）
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+// ListAuditLogs 按 offset 分页列出审计日志（最新的在前），同时返回总数
+func ListAuditLogs(d *gorm.DB, f AuditLogFilter, offset, limit int) ([]AuditLog, int64, error) {
+	var total int64
+	if err := auditLogQuery(d, f).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []AuditLog
+	err := auditLogQuery(d, f).Order("id DESC").Offset(offset).Limit(limit).Find(&rows).Error
+	return rows, total, err
+}
+
+// ListAuditLogsBefore 游标分页：列出 ID 小于 beforeID 的审计日志（最新的在前）；beforeID 为 0 表示第一页
+func ListAuditLogsBefore(d *gorm.DB, f AuditLogFilter, beforeID uint, limit int) ([]AuditLog, error) {
+	q := auditLogQuery(d, f)
+	if beforeID > 0 {
+		q = q.Where("id < ?", beforeID)
+	}
+	var rows []AuditLog
+	err := q.Order("id DESC").Limit(limit).Find(&rows).Error
+	return rows, err
+}
+
+// ListAuditLogsAfter 按 ID 升序列出 ID 大于 afterID 的审计日志，用于分批导出
+func ListAuditLogsAfter(d *gorm.DB, f AuditLogFilter, afterID uint, limit int) ([]AuditLog, error) {
+	var rows []AuditLog
+	err := auditLogQuery(d, f).Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&rows).Error
+	return rows, err
+}
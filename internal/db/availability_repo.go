@@ -0,0 +1,228 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ShiftCovers 判断班次是否覆盖时间点 at（at 需已转换到值班时区）。
+// EndMinute <= StartMinute 表示跨夜班次，后半段落在次日。
+func ShiftCovers(sh AdminShift, at time.Time) bool {
+	wd := int(at.Weekday())
+	m := at.Hour()*60 + at.Minute()
+	if sh.EndMinute > sh.StartMinute {
+		return wd == sh.Weekday && m >= sh.StartMinute && m < sh.EndMinute
+	}
+	// 跨夜：当天 [start, 24:00) + 次日 [00:00, end)
+	if wd == sh.Weekday && m >= sh.StartMinute {
+		return true
+	}
+	return wd == (sh.Weekday+1)%7 && m < sh.EndMinute
+}
+
+// isOnDuty 综合手动状态、班次与外出判断是否在岗：
+// - 手动设置为 OFF_DUTY 的不在岗（AWAY 仍视为在岗，只是暂时离开）
+// - 处于外出区间的不在岗
+// - 配置了班次的，必须落在任一班次内；未配置班次视为全天在岗
+func isOnDuty(status AvailabilityStatus, shifts []AdminShift, outOfOffice bool, at time.Time) bool {
+	if status == AvailabilityOffDuty || outOfOffice {
+		return false
+	}
+	if len(shifts) == 0 {
+		return true
+	}
+	for _, sh := range shifts {
+		if ShiftCovers(sh, at) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAdminAvailability 获取管理员的手动状态；无记录时返回默认 ONLINE
+func GetAdminAvailability(d *gorm.DB, uid uint) (*AdminAvailability, error) {
+	var av AdminAvailability
+	if err := d.First(&av, "user_id = ?", uid).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &AdminAvailability{UserID: uid, Status: AvailabilityOnline}, nil
+		}
+		return nil, err
+	}
+	return &av, nil
+}
+
+// ListAdminShifts 列出管理员的全部班次
+func ListAdminShifts(d *gorm.DB, uid uint) ([]AdminShift, error) {
+	var rows []AdminShift
+	err := d.Where("admin_user_id = ?", uid).Order("weekday ASC, start_minute ASC").Find(&rows).Error
+	return rows, err
+}
+
+// GetActiveOutOfOffice 返回 at 时刻生效的外出记录（没有则返回 nil, nil）
+func GetActiveOutOfOffice(d *gorm.DB, uid uint, at time.Time) (*AdminOutOfOffice, error) {
+	var ooo AdminOutOfOffice
+	utc := at.UTC()
+	err := d.Where("admin_user_id = ? AND start_at <= ? AND end_at > ?", uid, utc, utc).
+		Order("start_at DESC").
+		First(&ooo).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &ooo, nil
+}
+
+// OnDutyAdminIDs 批量计算 ids 中哪些管理员在 at 时刻在岗（at 需已转换到值班时区）。
+// 非管理员、已停用或不存在的用户都会被剔除；返回顺序与入参一致。
+func OnDutyAdminIDs(d *gorm.DB, ids []uint, at time.Time) ([]uint, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var admins []User
+	if err := d.Select("id").
+		Where("id IN ? AND role IN ? AND is_active = ?", ids, []Role{RoleAdmin, RoleSuperAdmin}, true).
+		Find(&admins).Error; err != nil {
+		return nil, err
+	}
+	if len(admins) == 0 {
+		return nil, nil
+	}
+	adminIDs := make([]uint, 0, len(admins))
+	for _, a := range admins {
+		adminIDs = append(adminIDs, a.ID)
+	}
+
+	// 一次性加载状态、班次与外出，避免 N+1
+	var avs []AdminAvailability
+	if err := d.Where("user_id IN ?", adminIDs).Find(&avs).Error; err != nil {
+		return nil, err
+	}
+	statusMap := make(map[uint]AvailabilityStatus, len(avs))
+	for _, av := range avs {
+		statusMap[av.UserID] = av.Status
+	}
+
+	var shifts []AdminShift
+	if err := d.Where("admin_user_id IN ?", adminIDs).Find(&shifts).Error; err != nil {
+		return nil, err
+	}
+	shiftMap := make(map[uint][]AdminShift)
+	for _, sh := range shifts {
+		shiftMap[sh.AdminUserID] = append(shiftMap[sh.AdminUserID], sh)
+	}
+
+	var away []uint
+	utc := at.UTC()
+	if err := d.Model(&AdminOutOfOffice{}).
+		Where("admin_user_id IN ? AND start_at <= ? AND end_at > ?", adminIDs, utc, utc).
+		Pluck("admin_user_id", &away).Error; err != nil {
+		return nil, err
+	}
+	awaySet := make(map[uint]bool, len(away))
+	for _, id := range away {
+		awaySet[id] = true
+	}
+
+	isAdminSet := make(map[uint]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		isAdminSet[id] = true
+	}
+
+	out := make([]uint, 0, len(adminIDs))
+	for _, id := range ids {
+		if !isAdminSet[id] {
+			continue
+		}
+		status, ok := statusMap[id]
+		if !ok {
+			status = AvailabilityOnline
+		}
+		if isOnDuty(status, shiftMap[id], awaySet[id], at) {
+			out = append(out, id)
+			delete(isAdminSet, id) // 入参重复时只保留一次
+		}
+	}
+	return out, nil
+}
+
+// IsAdminOnDuty 判断单个管理员是否在岗
+func IsAdminOnDuty(d *gorm.DB, uid uint, at time.Time) (bool, error) {
+	ids, err := OnDutyAdminIDs(d, []uint{uid}, at)
+	if err != nil {
+		return false, err
+	}
+	return len(ids) == 1, nil
+}
+
+// ListOnDutyAdmins 列出 at 时刻在岗的指定角色管理员（按 ID 升序）
+func ListOnDutyAdmins(d *gorm.DB, roles []Role, at time.Time) ([]User, error) {
+	var candidates []uint
+	if err := d.Model(&User{}).
+		Where("role IN ? AND is_active = ?", roles, true).
+		Order("id ASC").
+		Pluck("id", &candidates).Error; err != nil {
+		return nil, err
+	}
+	ids, err := OnDutyAdminIDs(d, candidates, at)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var users []User
+	err = d.Where("id IN ?", ids).Order("id ASC").Find(&users).Error
+	return users, err
+}
+
+// AdminOffDutyReason 说明管理员在 at 时刻为何不在岗（at 需已转换到值班时区），在岗时返回空字符串。
+// 处于外出区间时一并返回外出记录，调用方可据此提示代理人
+func AdminOffDutyReason(d *gorm.DB, uid uint, at time.Time) (string, *AdminOutOfOffice, error) {
+	ooo, err := GetActiveOutOfOffice(d, uid, at)
+	if err != nil {
+		return "", nil, err
+	}
+	if ooo != nil {
+		return "外出中", ooo, nil
+	}
+	av, err := GetAdminAvailability(d, uid)
+	if err != nil {
+		return "", nil, err
+	}
+	if av.Status == AvailabilityOffDuty {
+		return "已设置为下班", nil, nil
+	}
+	shifts, err := ListAdminShifts(d, uid)
+	if err != nil {
+		return "", nil, err
+	}
+	if !isOnDuty(av.Status, shifts, false, at) {
+		return "不在班次时间内", nil, nil
+	}
+	return "", nil, nil
+}
+
+// ListActiveOutOfOffice 批量返回 ids 中各管理员在 at 时刻生效的外出记录 map[管理员ID]外出记录；
+// 区间重叠时取开始时间最晚的一条，与 GetActiveOutOfOffice 一致
+func ListActiveOutOfOffice(d *gorm.DB, ids []uint, at time.Time) (map[uint]AdminOutOfOffice, error) {
+	out := make(map[uint]AdminOutOfOffice, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	var rows []AdminOutOfOffice
+	utc := at.UTC()
+	if err := d.Where("admin_user_id IN ? AND start_at <= ? AND end_at > ?", ids, utc, utc).
+		Order("start_at ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		out[r.AdminUserID] = r // 按开始时间升序覆盖，最终保留最晚开始的一条
+	}
+	return out, nil
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CategoryKey 分类名称/别名的比较键：去除首尾空白并转小写
+func CategoryKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// FindCategoryByName 按名称或别名（不区分大小写）查找分类；找不到时返回 gorm.ErrRecordNotFound
+func FindCategoryByName(d *gorm.DB, name string) (*Category, error) {
+	key := CategoryKey(name)
+	if key == "" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	var c Category
+	err := d.Where("LOWER(name) = ?", key).First(&c).Error
+	if err == nil {
+		return &c, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	var a CategoryAlias
+	if err := d.Where("alias = ?", key).First(&a).Error; err != nil {
+		return nil, err
+	}
+	if err := d.First(&c, a.CategoryID).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ListCategoryAssigneeIDs 列出分类的默认处理人 ID（升序）
+func ListCategoryAssigneeIDs(d *gorm.DB, categoryID uint) ([]uint, error) {
+	var ids []uint
+	err := d.Model(&CategoryAssignee{}).
+		Where("category_id = ?", categoryID).
+		Order("admin_user_id ASC").
+		Pluck("admin_user_id", &ids).Error
+	return ids, err
+}
+
+// BackfillTicketCategories 将历史工单的自由文本分类映射到分类目录：
+// 已有同名分类或别名时直接关联，否则以该文本新建分类（之后可由超级管理员合并）。
+func BackfillTicketCategories(d *gorm.DB) error {
+	var names []string
+	if err := d.Model(&Ticket{}).
+		Where("category_id IS NULL").
+		Distinct("category").
+		Pluck("category", &names).Error; err != nil {
+		return err
+	}
+	for _, raw := range names {
+		name := strings.TrimSpace(raw)
+		if name == "" {
+			continue
+		}
+		err := d.Transaction(func(tx *gorm.DB) error {
+			c, err := FindCategoryByName(tx, name)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				now := time.Now().UTC().Truncate(time.Microsecond)
+				c = &Category{Name: name, IsActive: true, CreatedAt: now, UpdatedAt: now}
+				err = tx.Create(c).Error
+			}
+			if err != nil {
+				return err
+			}
+			return tx.Model(&Ticket{}).
+				Where("category_id IS NULL AND category = ?", raw).
+				UpdateColumns(map[string]interface{}{"category_id": c.ID, "category": c.Name}).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// categoryFieldKeyPattern 自定义字段键：小写字母开头，仅含小写字母、数字和下划线
+var categoryFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// ValidCategoryFieldKey 判断自定义字段键是否合法
+func ValidCategoryFieldKey(key string) bool {
+	return categoryFieldKeyPattern.MatchString(key)
+}
+
+// ListCategoryFields 按表单顺序列出分类的自定义字段；includeArchived 为 false 时仅返回表单中的字段
+func ListCategoryFields(d *gorm.DB, categoryIDs []uint, includeArchived bool) (map[uint][]CategoryField, error) {
+	out := make(map[uint][]CategoryField, len(categoryIDs))
+	if len(categoryIDs) == 0 {
+		return out, nil
+	}
+	q := d.Where("category_id IN ?", categoryIDs)
+	if !includeArchived {
+		q = q.Where("is_archived = ?", false)
+	}
+	var rows []CategoryField
+	if err := q.Order("position ASC, id ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, f := range rows {
+		out[f.CategoryID] = append(out[f.CategoryID], f)
+	}
+	return out, nil
+}
+
+// CategoryFieldOptions 解析 SELECT 字段的可选值
+func CategoryFieldOptions(f CategoryField) ([]string, error) {
+	var opts []string
+	if len(f.Options) == 0 {
+		return opts, nil
+	}
+	err := json.Unmarshal(f.Options, &opts)
+	return opts, err
+}
+
+// TicketFieldRow 工单字段值及其字段定义
+type TicketFieldRow struct {
+	Field CategoryField
+	Value string
+}
+
+// GetTicketFieldValues 按字段顺序返回工单的自定义字段值（含已归档字段）
+func GetTicketFieldValues(d *gorm.DB, ticketID uint) ([]TicketFieldRow, error) {
+	var vals []TicketFieldValue
+	if err := d.Where("ticket_id = ?", ticketID).Find(&vals).Error; err != nil {
+		return nil, err
+	}
+	if len(vals) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint, 0, len(vals))
+	byField := make(map[uint]string, len(vals))
+	for _, v := range vals {
+		ids = append(ids, v.FieldID)
+		byField[v.FieldID] = v.Value
+	}
+	var fields []CategoryField
+	if err := d.Where("id IN ?", ids).Order("position ASC, id ASC").Find(&fields).Error; err != nil {
+		return nil, err
+	}
+	out := make([]TicketFieldRow, 0, len(fields))
+	for _, f := range fields {
+		out = append(out, TicketFieldRow{Field: f, Value: byField[f.ID]})
+	}
+	return out, nil
+}
@@ -92,8 +92,32 @@ func AutoMigrate(db *gorm.DB) error {
         &Image{},
         &Rating{},
         &AuditLog{},
+        &AuditChainHead{},
         &SpamFlag{},
         &CannedReply{},
         &TicketImage{},
+        &AdminAvailability{},
+        &AdminShift{},
+        &AdminOutOfOffice{},
+        &SchedulerLock{},
+        &TicketSearchDoc{},
+        &SavedView{},
+        &TicketRevision{},
+        &TicketLink{},
+        &Incident{},
+        &IncidentTicket{},
+        &IncidentUpdate{},
+        &Tag{},
+        &TicketTag{},
+        &Category{},
+        &CategoryAlias{},
+        &CategoryAssignee{},
+        &CategoryField{},
+        &TicketFieldValue{},
+        &TicketWatcher{},
+        &TicketMention{},
+        &MessageAttachment{},
+        &TicketMessageRevision{},
+        &TicketReadState{},
     )
 }
\ No newline at end of file
@@ -26,9 +26,9 @@ func CreateImage(d *gorm.DB, im *Image) error {
 	return d.Create(im).Error
 }
 
-// IsImageAccessibleByUser 判断用户 uid 是否能通过工单关联访问该图片。
-// 如果存在与图片关联的工单，并且满足以下任一条件，则授予访问权限：
-// - ticket.user_id = uid  或
+// IsImageAccessibleByUser 判断用户 uid 是否能通过工单或消息附件关联访问该图片。
+// 如果存在与图片关联的工单（直接关联，或作为该工单某条消息的附件），并且满足以下任一条件，则授予访问权限：
+// - ticket.user_id = uid（内部备注或已删除消息的附件除外）  或
 // - ticket.assigned_admin_id = uid
 // (管理员可以查看所有内容；该检查由调用方完成。)
 func IsImageAccessibleByUser(d *gorm.DB, imageID, uid uint) (bool, error) {
@@ -38,22 +38,51 @@ func IsImageAccessibleByUser(d *gorm.DB, imageID, uid uint) (bool, error) {
 		Joins("JOIN ticket_images ti ON ti.ticket_id = t.id").
 		Where("ti.image_id = ? AND (t.user_id = ? OR t.assigned_admin_id = ?)", imageID, uid, uid).
 		Count(&cnt).Error
+	if err != nil || cnt > 0 {
+		return cnt > 0, err
+	}
+	err = d.
+		Table("tickets AS t").
+		Joins("JOIN ticket_messages m ON m.ticket_id = t.id").
+		Joins("JOIN message_attachments ma ON ma.message_id = m.id").
+		Where("ma.image_id = ? AND ((t.user_id = ? AND m.is_internal_note = ? AND m.deleted_at IS NULL) OR t.assigned_admin_id = ?)", imageID, uid, false, uid).
+		Count(&cnt).Error
 	if err != nil {
 		return false, err
 	}
 	return cnt > 0, nil
 }
 
-// DoesImageHaveAnyTicket 检查图片是否与任何工单关联（用于提供更具信息量的决策）。
+// DoesImageHaveAnyTicket 检查图片是否与任何工单关联（含消息附件；用于提供更具信息量的决策）。
 func DoesImageHaveAnyTicket(d *gorm.DB, imageID uint) (bool, error) {
 	var cnt int64
 	err := d.Model(&TicketImage{}).Where("image_id = ?", imageID).Count(&cnt).Error
+	if err != nil || cnt > 0 {
+		return cnt > 0, err
+	}
+	err = d.Model(&MessageAttachment{}).Where("image_id = ?", imageID).Count(&cnt).Error
 	if err != nil {
 		return false, err
 	}
 	return cnt > 0, nil
 }
 
+// GetMessageAttachmentsMap 批量获取消息的附件 ID 映射
+func GetMessageAttachmentsMap(d *gorm.DB, messageIDs []uint) (map[uint][]uint, error) {
+	out := make(map[uint][]uint, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return out, nil
+	}
+	var rels []MessageAttachment
+	if err := d.Where("message_id IN ?", messageIDs).Order("image_id ASC").Find(&rels).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rels {
+		out[r.MessageID] = append(out[r.MessageID], r.ImageID)
+	}
+	return out, nil
+}
+
 // IsAdminOrSuperAdmin 快速检查用户角色
 func IsAdminOrSuperAdmin(d *gorm.DB, uid uint) (bool, error) {
 	var u User
@@ -27,6 +27,18 @@ const (
     TicketStatusSpamPending   TicketStatus = "SPAM_PENDING"
     TicketStatusSpamConfirmed TicketStatus = "SPAM_CONFIRMED"
     TicketStatusSpamRejected  TicketStatus = "SPAM_REJECTED"
+    TicketStatusCancelled     TicketStatus = "CANCELLED" // 创建者撤回
+    TicketStatusMerged        TicketStatus = "MERGED"    // 已合并到其他工单
+)
+
+// 工单优先级枚举：P1 最高（如安全隐患），P4 最低
+type TicketPriority string
+
+const (
+    TicketPriorityP1 TicketPriority = "P1"
+    TicketPriorityP2 TicketPriority = "P2"
+    TicketPriorityP3 TicketPriority = "P3"
+    TicketPriorityP4 TicketPriority = "P4"
 )
 
 // User 表：用户基础信息
@@ -52,12 +64,17 @@ type Ticket struct {
     UserID          uint         `gorm:"index;not null;comment:提单学生ID"`
     Title           string       `gorm:"type:varchar(255);not null"`
     Content         string       `gorm:"type:text;not null"`
-    Category        string       `gorm:"type:varchar(100);index"`
-    IsUrgent        bool         `gorm:"not null;default:false"`
+    Category        string       `gorm:"type:varchar(100);index;comment:分类名称（与 category_id 对应的分类同步）"`
+    CategoryID      *uint        `gorm:"index;comment:分类ID"`
+    IsUrgent        bool         `gorm:"not null;default:false;comment:是否紧急（与优先级 P1/P2 同步）"`
+    Priority        TicketPriority `gorm:"type:varchar(2);index;not null;default:'P3';comment:优先级 P1-P4"`
     IsAnonymous     bool         `gorm:"not null;default:false"`
     Status          TicketStatus `gorm:"type:varchar(20);index;not null;default:'NEW'"`
     AssignedAdminID *uint        `gorm:"index;comment:受理管理员ID"`
     ClaimedAt       *time.Time
+    ResolvedAt      *time.Time   `gorm:"index;comment:最近一次标记已处理的时间"`
+    SLADueAt        *time.Time   `gorm:"column:sla_due_at;index;comment:SLA 截止时间"`
+    MergedIntoTicketID *uint     `gorm:"index;comment:合并目标工单ID（状态为 MERGED 时）"`
     CreatedAt       time.Time
     UpdatedAt       time.Time
 }
@@ -72,10 +89,38 @@ type TicketMessage struct {
     Body          string    `gorm:"type:text;not null"`
     IsInternalNote bool     `gorm:"not null;default:false;comment:是否内部备注"`
     CreatedAt     time.Time
+    EditedAt      *time.Time `gorm:"comment:发送者最后编辑时间"`
+    DeletedAt     *time.Time `gorm:"index;comment:管理员软删除时间（线程中保留墓碑）"`
+    DeletedBy     *uint
+    RedactedAt    *time.Time `gorm:"comment:超级管理员抹除内容的时间"`
+    RedactedBy    *uint
 }
 
 func (TicketMessage) TableName() string { return "ticket_messages" }
 
+// TicketMessageRevision 消息编辑历史：每次编辑前保存的旧内容
+type TicketMessageRevision struct {
+    ID             uint   `gorm:"primaryKey"`
+    MessageID      uint   `gorm:"not null;uniqueIndex:uniq_message_revision_version,priority:1"`
+    Version        int    `gorm:"not null;uniqueIndex:uniq_message_revision_version,priority:2"`
+    Body           string `gorm:"type:text;not null"`
+    IsInternalNote bool   `gorm:"not null;default:false"`
+    EditedBy       uint   `gorm:"not null"`
+    CreatedAt      time.Time
+}
+
+func (TicketMessageRevision) TableName() string { return "ticket_message_revisions" }
+
+// TicketReadState 表：用户在工单中的已读位置（每个用户每个工单一行）
+type TicketReadState struct {
+    TicketID          uint `gorm:"primaryKey;autoIncrement:false"`
+    UserID            uint `gorm:"primaryKey;autoIncrement:false;index"`
+    LastReadMessageID uint `gorm:"not null;default:0;comment:已读到的最大消息ID"`
+    UpdatedAt         time.Time
+}
+
+func (TicketReadState) TableName() string { return "ticket_read_states" }
+
 // Image 表：图片元数据
 type Image struct {
     ID        uint      `gorm:"primaryKey"`
@@ -101,6 +146,15 @@ type TicketImage struct {
 
 func (TicketImage) TableName() string { return "ticket_images" }
 
+// MessageAttachment 关联表：消息-附件（附件与工单图片共用 images 表，可为图片或 PDF）
+type MessageAttachment struct {
+    MessageID uint      `gorm:"primaryKey"`
+    ImageID   uint      `gorm:"primaryKey;index"`
+    CreatedAt time.Time
+}
+
+func (MessageAttachment) TableName() string { return "message_attachments" }
+
 // Rating 表：工单评分（一个工单一条评分）
 type Rating struct {
     ID        uint      `gorm:"primaryKey"`
@@ -113,7 +167,8 @@ type Rating struct {
 
 func (Rating) TableName() string { return "ratings" }
 
-// AuditLog 表：审计日志（Diff 为 JSON）
+// AuditLog 表：审计日志（Diff 为 JSON）。
+// 每条记录保存上一条记录的哈希与自身内容的哈希，按 ID 顺序构成防篡改哈希链（见 audit_chain.go）
 type AuditLog struct {
     ID          uint           `gorm:"primaryKey"`
     ActorUserID uint           `gorm:"index;not null"`
@@ -121,11 +176,26 @@ type AuditLog struct {
     Entity      string         `gorm:"type:varchar(100);index;not null"`
     EntityID    uint           `gorm:"index;not null"`
     Diff        datatypes.JSON `gorm:"type:jsonb"`
+    IP          string         `gorm:"type:varchar(64);not null;default:'';comment:客户端 IP"`
+    RequestID   string         `gorm:"type:varchar(64);index;not null;default:'';comment:请求 ID"`
+    PrevHash    string         `gorm:"type:char(64);not null;default:'';comment:上一条审计日志的哈希"`
+    Hash        string         `gorm:"type:char(64);not null;default:'';comment:本条审计日志的哈希"`
     CreatedAt   time.Time
 }
 
 func (AuditLog) TableName() string { return "audit_logs" }
 
+// AuditChainHead 表：审计日志哈希链的链头（单行）。
+// 写入审计日志时先锁定此行，保证链按 ID 顺序串行增长；也用于发现尾部记录被删除
+type AuditChainHead struct {
+    ID        uint      `gorm:"primaryKey;autoIncrement:false"`
+    LastID    uint      `gorm:"not null;default:0;comment:链上最后一条审计日志 ID"`
+    Hash      string    `gorm:"type:char(64);not null;default:'';comment:链上最后一条审计日志的哈希"`
+    UpdatedAt time.Time
+}
+
+func (AuditChainHead) TableName() string { return "audit_chain_heads" }
+
 // SpamFlag 表：垃圾举报与复核
 type SpamFlag struct {
     ID                     uint       `gorm:"primaryKey"`
@@ -151,4 +221,292 @@ type CannedReply struct {
     UpdatedAt   time.Time
 }
 
-func (CannedReply) TableName() string { return "canned_replies" }
\ No newline at end of file
+func (CannedReply) TableName() string { return "canned_replies" }
+
+// 管理员在岗状态枚举（与 OpenAPI 模型一致）
+type AvailabilityStatus string
+
+const (
+    AvailabilityOnline  AvailabilityStatus = "ONLINE"
+    AvailabilityAway    AvailabilityStatus = "AWAY"
+    AvailabilityOffDuty AvailabilityStatus = "OFF_DUTY"
+)
+
+// AdminAvailability 表：管理员手动设置的在岗状态（每个管理员至多一行，无记录视为 ONLINE）
+type AdminAvailability struct {
+    UserID    uint               `gorm:"primaryKey;autoIncrement:false"`
+    Status    AvailabilityStatus `gorm:"type:varchar(20);index;not null;default:'ONLINE'"`
+    Note      string             `gorm:"type:varchar(255)"`
+    UpdatedAt time.Time
+}
+
+func (AdminAvailability) TableName() string { return "admin_availabilities" }
+
+// AdminShift 表：管理员每周循环班次（分钟数相对当天零点，按值班时区解释）
+type AdminShift struct {
+    ID          uint `gorm:"primaryKey"`
+    AdminUserID uint `gorm:"index;not null"`
+    Weekday     int  `gorm:"not null;comment:0=周日 ... 6=周六"`
+    StartMinute int  `gorm:"not null"`
+    EndMinute   int  `gorm:"not null;comment:小于等于开始时间表示跨夜"`
+    CreatedAt   time.Time
+}
+
+func (AdminShift) TableName() string { return "admin_shifts" }
+
+// AdminOutOfOffice 表：管理员临时外出（可指定代理人）
+type AdminOutOfOffice struct {
+    ID             uint      `gorm:"primaryKey"`
+    AdminUserID    uint      `gorm:"index;not null"`
+    StartAt        time.Time `gorm:"index;not null"`
+    EndAt          time.Time `gorm:"index;not null"`
+    DelegateUserID *uint     `gorm:"index;comment:代理管理员ID"`
+    Reason         string    `gorm:"type:varchar(255)"`
+    CreatedAt      time.Time
+}
+
+func (AdminOutOfOffice) TableName() string { return "admin_out_of_offices" }
+
+// SchedulerLock 表：定时任务分布式锁（多实例部署时保证同一任务同一时刻只有一个实例执行）
+type SchedulerLock struct {
+    Name        string    `gorm:"type:varchar(100);primaryKey"`
+    Holder      string    `gorm:"type:varchar(255);not null;comment:持有者实例标识"`
+    LockedUntil time.Time `gorm:"not null;comment:锁到期时间"`
+    UpdatedAt   time.Time
+}
+
+func (SchedulerLock) TableName() string { return "scheduler_locks" }
+
+// TicketSearchDoc 表：工单全文检索文档（标题 + 正文 + 非内部备注消息，已按 search.IndexText 切分）
+type TicketSearchDoc struct {
+    TicketID  uint      `gorm:"primaryKey;autoIncrement:false"`
+    UserID    uint      `gorm:"index;not null;comment:提单学生ID，用于可见性过滤"`
+    Tokens    string    `gorm:"type:text;not null"`
+    UpdatedAt time.Time
+}
+
+func (TicketSearchDoc) TableName() string { return "ticket_search_docs" }
+
+// SavedView 表：管理员保存的工单队列视图（Filters 为 openapi.TicketViewFilters 的 JSON）
+type SavedView struct {
+    ID          uint           `gorm:"primaryKey"`
+    OwnerUserID uint           `gorm:"index;not null;uniqueIndex:uniq_saved_view_owner_name,priority:1"`
+    Name        string         `gorm:"type:varchar(100);not null;uniqueIndex:uniq_saved_view_owner_name,priority:2"`
+    Filters     datatypes.JSON `gorm:"type:jsonb"`
+    IsShared    bool           `gorm:"not null;default:false;index;comment:是否共享给其他管理员"`
+    CreatedAt   time.Time
+    UpdatedAt   time.Time
+}
+
+func (SavedView) TableName() string { return "saved_views" }
+
+// TicketRevision 表：学生编辑工单前的历史版本（Version 从 1 递增，记录的是被覆盖的内容）
+type TicketRevision struct {
+    ID        uint           `gorm:"primaryKey"`
+    TicketID  uint           `gorm:"not null;uniqueIndex:uniq_ticket_revision_version,priority:1"`
+    Version   int            `gorm:"not null;uniqueIndex:uniq_ticket_revision_version,priority:2"`
+    Title     string         `gorm:"type:varchar(255);not null"`
+    Content   string         `gorm:"type:text;not null"`
+    Category  string         `gorm:"type:varchar(100);not null"`
+    IsUrgent  bool           `gorm:"not null;default:false"`
+    Priority  TicketPriority `gorm:"type:varchar(2)"`
+    ImageIDs  datatypes.JSON `gorm:"type:jsonb;comment:当时关联的图片 ID 列表"`
+    Fields    datatypes.JSON `gorm:"type:jsonb;comment:当时的自定义字段值（key -> value）"`
+    EditedBy  uint           `gorm:"not null"`
+    CreatedAt time.Time
+}
+
+func (TicketRevision) TableName() string { return "ticket_revisions" }
+
+// TicketLinkType 工单关联类型（按 From -> To 方向理解）
+type TicketLinkType string
+
+const (
+    TicketLinkRelated     TicketLinkType = "RELATED"      // 相关（无方向，存储时 From < To）
+    TicketLinkDuplicateOf TicketLinkType = "DUPLICATE_OF" // From 与 To 重复
+    TicketLinkParentOf    TicketLinkType = "PARENT_OF"    // From 是 To 的父工单
+    TicketLinkFollowUpOf  TicketLinkType = "FOLLOW_UP_OF" // From 是 To 的后续工单
+)
+
+// TicketLink 表：工单之间的关联
+type TicketLink struct {
+    ID           uint           `gorm:"primaryKey"`
+    FromTicketID uint           `gorm:"not null;index;uniqueIndex:uniq_ticket_link,priority:1"`
+    ToTicketID   uint           `gorm:"not null;index;uniqueIndex:uniq_ticket_link,priority:2"`
+    Type         TicketLinkType `gorm:"type:varchar(20);not null;uniqueIndex:uniq_ticket_link,priority:3"`
+    CreatedBy    uint           `gorm:"not null"`
+    CreatedAt    time.Time
+}
+
+func (TicketLink) TableName() string { return "ticket_links" }
+
+// IncidentStatus 事件状态
+type IncidentStatus string
+
+const (
+    IncidentStatusOpen     IncidentStatus = "OPEN"
+    IncidentStatusResolved IncidentStatus = "RESOLVED"
+)
+
+// Incident 表：将大量同类工单归为一个事件（如宿舍停水）统一处理
+type Incident struct {
+    ID          uint           `gorm:"primaryKey"`
+    Title       string         `gorm:"type:varchar(255);not null"`
+    Description string         `gorm:"type:text;not null;default:''"`
+    Status      IncidentStatus `gorm:"type:varchar(20);index;not null;default:'OPEN'"`
+    CreatedBy   uint           `gorm:"index;not null"`
+    ResolvedAt  *time.Time
+    CreatedAt   time.Time
+    UpdatedAt   time.Time
+}
+
+func (Incident) TableName() string { return "incidents" }
+
+// IncidentTicket 表：事件与工单的关联（一个工单最多属于一个事件）
+type IncidentTicket struct {
+    IncidentID uint `gorm:"primaryKey"`
+    TicketID   uint `gorm:"primaryKey;uniqueIndex"`
+    AddedBy    uint `gorm:"not null"`
+    CreatedAt  time.Time
+}
+
+func (IncidentTicket) TableName() string { return "incident_tickets" }
+
+// IncidentUpdate 表：事件的公开进展（发布时同步为每个关联工单的一条消息）
+type IncidentUpdate struct {
+    ID          uint   `gorm:"primaryKey"`
+    IncidentID  uint   `gorm:"index;not null"`
+    AuthorID    uint   `gorm:"not null"`
+    Body        string `gorm:"type:text;not null"`
+    TicketCount int    `gorm:"not null;default:0;comment:同步到的工单数"`
+    CreatedAt   time.Time
+}
+
+func (IncidentUpdate) TableName() string { return "incident_updates" }
+
+// Tag 表：管理员为工单打的自由标签（如 refund、repeat-issue），名称统一小写
+type Tag struct {
+    ID        uint   `gorm:"primaryKey"`
+    Name      string `gorm:"type:varchar(50);uniqueIndex;not null"`
+    CreatedBy uint   `gorm:"not null"`
+    CreatedAt time.Time
+    UpdatedAt time.Time
+}
+
+func (Tag) TableName() string { return "tags" }
+
+// TicketTag 表：工单与标签的多对多关联
+type TicketTag struct {
+    TicketID  uint `gorm:"primaryKey"`
+    TagID     uint `gorm:"primaryKey;index"`
+    AddedBy   uint `gorm:"not null"`
+    CreatedAt time.Time
+}
+
+func (TicketTag) TableName() string { return "ticket_tags" }
+
+// Category 表：工单分类目录（支持一级父分类）
+type Category struct {
+    ID          uint   `gorm:"primaryKey"`
+    Name        string `gorm:"type:varchar(100);uniqueIndex;not null"`
+    Description string `gorm:"type:text;not null;default:''"`
+    ParentID    *uint  `gorm:"index"`
+    IsActive    bool   `gorm:"not null;default:true;comment:停用后不再出现在提单表单中"`
+    SortOrder   int    `gorm:"not null;default:0"`
+    Department  string `gorm:"type:varchar(100);not null;default:'';comment:负责部门"`
+    CreatedAt   time.Time
+    UpdatedAt   time.Time
+}
+
+func (Category) TableName() string { return "categories" }
+
+// CategoryAlias 表：分类别名（历史自由文本、旧名称），Alias 为小写形式
+type CategoryAlias struct {
+    Alias      string `gorm:"type:varchar(100);primaryKey"`
+    CategoryID uint   `gorm:"index;not null"`
+    CreatedAt  time.Time
+}
+
+func (CategoryAlias) TableName() string { return "category_aliases" }
+
+// CategoryAssignee 表：分类的默认处理人（新工单通知优先发送给在岗的默认处理人）
+type CategoryAssignee struct {
+    CategoryID  uint `gorm:"primaryKey"`
+    AdminUserID uint `gorm:"primaryKey;index"`
+    CreatedAt   time.Time
+}
+
+func (CategoryAssignee) TableName() string { return "category_assignees" }
+
+// CategoryFieldType 分类自定义字段类型
+type CategoryFieldType string
+
+const (
+    CategoryFieldText   CategoryFieldType = "TEXT"
+    CategoryFieldNumber CategoryFieldType = "NUMBER"
+    CategoryFieldSelect CategoryFieldType = "SELECT"
+    CategoryFieldDate   CategoryFieldType = "DATE" // YYYY-MM-DD
+)
+
+// CategoryField 表：分类的自定义表单字段（如报修的楼栋、房间号）
+type CategoryField struct {
+    ID         uint              `gorm:"primaryKey"`
+    CategoryID uint              `gorm:"not null;uniqueIndex:uniq_category_field_key,priority:1"`
+    Key        string            `gorm:"type:varchar(50);not null;uniqueIndex:uniq_category_field_key,priority:2"`
+    Label      string            `gorm:"type:varchar(100);not null"`
+    Type       CategoryFieldType `gorm:"type:varchar(20);not null"`
+    Required   bool              `gorm:"not null;default:false"`
+    Options    datatypes.JSON    `gorm:"type:jsonb;comment:SELECT 类型的可选值"`
+    MaxLength  *int              `gorm:"comment:TEXT 类型的最大长度"`
+    Min        *float64          `gorm:"comment:NUMBER 类型的最小值"`
+    Max        *float64          `gorm:"comment:NUMBER 类型的最大值"`
+    Position   int               `gorm:"not null;default:0"`
+    IsArchived bool              `gorm:"not null;default:false;comment:已从表单移除，历史值仍保留"`
+    CreatedAt  time.Time
+    UpdatedAt  time.Time
+}
+
+func (CategoryField) TableName() string { return "category_fields" }
+
+// TicketFieldValue 表：工单的自定义字段值（统一以字符串存储）
+type TicketFieldValue struct {
+    TicketID  uint   `gorm:"primaryKey"`
+    FieldID   uint   `gorm:"primaryKey;index"`
+    Value     string `gorm:"type:text;not null"`
+    CreatedAt time.Time
+}
+
+func (TicketFieldValue) TableName() string { return "ticket_field_values" }
+
+// TicketWatcherSource 关注来源
+type TicketWatcherSource string
+
+const (
+    TicketWatcherManual       TicketWatcherSource = "MANUAL"        // 管理员主动关注
+    TicketWatcherCC           TicketWatcherSource = "CC"            // 由其他管理员抄送
+    TicketWatcherInternalNote TicketWatcherSource = "INTERNAL_NOTE" // 发布内部备注时自动关注
+)
+
+// TicketWatcher 表：关注工单的管理员，会收到消息、状态变更与评价通知
+type TicketWatcher struct {
+    TicketID  uint                `gorm:"primaryKey"`
+    UserID    uint                `gorm:"primaryKey;index"`
+    Source    TicketWatcherSource `gorm:"type:varchar(20);not null"`
+    AddedBy   uint                `gorm:"not null"`
+    CreatedAt time.Time
+}
+
+func (TicketWatcher) TableName() string { return "ticket_watchers" }
+
+// TicketMention 表：内部备注中对管理员的 @ 提及（每条备注对同一管理员只记一次）
+type TicketMention struct {
+    ID              uint       `gorm:"primaryKey"`
+    TicketID        uint       `gorm:"not null;index"`
+    MessageID       uint       `gorm:"not null;uniqueIndex:uniq_mention_message_user,priority:1"`
+    MentionedUserID uint       `gorm:"not null;uniqueIndex:uniq_mention_message_user,priority:2;index"`
+    MentionedBy     uint       `gorm:"not null"`
+    ReadAt          *time.Time `gorm:"index"`
+    CreatedAt       time.Time
+}
+
+func (TicketMention) TableName() string { return "ticket_mentions" }
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MarkTicketRead 将用户在工单中的已读位置推进到 messageID；已读位置只前进不后退
+func MarkTicketRead(d *gorm.DB, ticketID, userID, messageID uint, now time.Time) error {
+	if messageID == 0 {
+		return nil
+	}
+	res := d.Model(&TicketReadState{}).
+		Where("ticket_id = ? AND user_id = ? AND last_read_message_id < ?", ticketID, userID, messageID).
+		Updates(map[string]interface{}{"last_read_message_id": messageID, "updated_at": now})
+	if res.Error != nil || res.RowsAffected > 0 {
+		return res.Error
+	}
+	// 没有更新到行：要么尚无记录，要么已读位置已更靠后（此时插入冲突被忽略）
+	return d.Clauses(clause.OnConflict{DoNothing: true}).Create(&TicketReadState{
+		TicketID:          ticketID,
+		UserID:            userID,
+		LastReadMessageID: messageID,
+		UpdatedAt:         now,
+	}).Error
+}
+
+// ListTicketReadStates 列出工单中所有用户的已读位置
+func ListTicketReadStates(d *gorm.DB, ticketID uint) ([]TicketReadState, error) {
+	var rows []TicketReadState
+	err := d.Where("ticket_id = ?", ticketID).Order("user_id ASC").Find(&rows).Error
+	return rows, err
+}
+
+// unreadMessages 用户 userID 未读消息的查询：他人发送、未删除且在已读位置之后；
+// includeInternal 为 false 时不计内部备注
+func unreadMessages(d *gorm.DB, userID uint, includeInternal bool) *gorm.DB {
+	q := d.Table("ticket_messages AS m").
+		Joins("LEFT JOIN ticket_read_states r ON r.ticket_id = m.ticket_id AND r.user_id = ?", userID).
+		Where("m.sender_user_id <> ? AND m.deleted_at IS NULL AND m.id > COALESCE(r.last_read_message_id, 0)", userID)
+	if !includeInternal {
+		q = q.Where("m.is_internal_note = ?", false)
+	}
+	return q
+}
+
+// CountUnreadByTicket 批量统计用户在各工单中的未读消息数（无未读的工单不出现在结果中）
+func CountUnreadByTicket(d *gorm.DB, userID uint, ticketIDs []uint, includeInternal bool) (map[uint]int, error) {
+	out := make(map[uint]int, len(ticketIDs))
+	if len(ticketIDs) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		TicketID uint
+		N        int
+	}
+	if err := unreadMessages(d, userID, includeInternal).
+		Where("m.ticket_id IN ?", ticketIDs).
+		Select("m.ticket_id AS ticket_id, COUNT(*) AS n").
+		Group("m.ticket_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		out[r.TicketID] = r.N
+	}
+	return out, nil
+}
+
+// UnreadTicketRow 有未读消息的工单
+type UnreadTicketRow struct {
+	TicketID      uint
+	Title         string
+	Status        TicketStatus
+	UnreadCount   int
+	LastMessageID uint
+}
+
+// unreadOwnedMessages 限定在用户名下工单（学生：本人提交；管理员：本人受理）的未读消息
+func unreadOwnedMessages(d *gorm.DB, userID uint, asAdmin bool) *gorm.DB {
+	q := unreadMessages(d, userID, asAdmin).
+		Joins("JOIN tickets t ON t.id = m.ticket_id")
+	if asAdmin {
+		return q.Where("t.assigned_admin_id = ?", userID)
+	}
+	return q.Where("t.user_id = ?", userID)
+}
+
+// CountUnreadOwned 统计用户名下工单的未读消息总数与有未读的工单数
+func CountUnreadOwned(d *gorm.DB, userID uint, asAdmin bool) (messages, tickets int64, err error) {
+	var row struct {
+		Messages int64
+		Tickets  int64
+	}
+	err = unreadOwnedMessages(d, userID, asAdmin).
+		Select("COUNT(*) AS messages, COUNT(DISTINCT m.ticket_id) AS tickets").
+		Scan(&row).Error
+	return row.Messages, row.Tickets, err
+}
+
+// ListUnreadTickets 列出用户名下有未读消息的工单，最近有新消息的在前
+func ListUnreadTickets(d *gorm.DB, userID uint, asAdmin bool, limit int) ([]UnreadTicketRow, error) {
+	var rows []UnreadTicketRow
+	err := unreadOwnedMessages(d, userID, asAdmin).
+		Select("m.ticket_id AS ticket_id, t.title AS title, t.status AS status, COUNT(*) AS unread_count, MAX(m.id) AS last_message_id").
+		Group("m.ticket_id, t.title, t.status").
+		Order("last_message_id DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
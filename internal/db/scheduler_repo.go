@@ -0,0 +1,44 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TryAcquireSchedulerLock 尝试获取（或续期）名为 name 的定时任务锁，租期为 ttl。
+// 锁已过期或本就由 holder 持有时获取成功；返回 false 表示锁被其他实例持有。
+func TryAcquireSchedulerLock(d *gorm.DB, name, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	// 确保锁记录存在（已存在则忽略），兼容 postgres / mysql / sqlite
+	seed := &SchedulerLock{Name: name, Holder: "", LockedUntil: time.Unix(0, 0).UTC(), UpdatedAt: now}
+	if err := d.Clauses(clause.OnConflict{DoNothing: true}).Create(seed).Error; err != nil {
+		return false, err
+	}
+
+	// 条件更新（CAS）：只有过期或自己持有时才能抢到
+	res := d.Model(&SchedulerLock{}).
+		Where("name = ? AND (locked_until <= ? OR holder = ?)", name, now, holder).
+		Updates(map[string]interface{}{
+			"holder":       holder,
+			"locked_until": now.Add(ttl),
+			"updated_at":   now,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// ReleaseSchedulerLock 提前释放 holder 持有的锁（锁不属于 holder 时不做任何事）
+func ReleaseSchedulerLock(d *gorm.DB, name, holder string) error {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	return d.Model(&SchedulerLock{}).
+		Where("name = ? AND holder = ?", name, holder).
+		Updates(map[string]interface{}{
+			"locked_until": now,
+			"updated_at":   now,
+		}).Error
+}
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"student-services-platform-backend/internal/search"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 不同数据库的全文检索实现：
+//   - postgres：对 tokens 建 to_tsvector('simple', ...) 表达式 GIN 索引
+//   - sqlite：FTS5 虚拟表 ticket_search_fts（需以 -tags sqlite_fts5 编译，否则退化为 LIKE）
+//   - 其他：LIKE 逐词匹配
+const sqliteFTSTable = "ticket_search_fts"
+
+// EnsureSearchIndex 创建数据库相关的全文索引结构，并为缺失检索文档的工单补建索引
+func EnsureSearchIndex(d *gorm.DB) error {
+	switch d.Dialector.Name() {
+	case "postgres":
+		if err := d.Exec(`CREATE INDEX IF NOT EXISTS idx_ticket_search_docs_tsv
+			ON ticket_search_docs USING GIN (to_tsvector('simple', tokens))`).Error; err != nil {
+			return err
+		}
+	case "sqlite":
+		var fts5 int
+		d.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&fts5)
+		if fts5 != 1 {
+			log.Printf("db: sqlite 未启用 FTS5（编译时加 -tags sqlite_fts5），全文检索退化为 LIKE 匹配")
+			break
+		}
+		if err := d.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS ` + sqliteFTSTable +
+			` USING fts5(ticket_id UNINDEXED, tokens)`).Error; err != nil {
+			return err
+		}
+	}
+
+	var missing []uint
+	if err := d.Model(&Ticket{}).
+		Where("id NOT IN (?)", d.Model(&TicketSearchDoc{}).Select("ticket_id")).
+		Order("id ASC").
+		Pluck("id", &missing).Error; err != nil {
+		return err
+	}
+	for _, id := range missing {
+		if err := ReindexTicket(d, id); err != nil {
+			return err
+		}
+	}
+	if len(missing) > 0 {
+		log.Printf("db: 已为 %d 个工单补建检索索引", len(missing))
+	}
+	return nil
+}
+
+// sqliteHasFTS 判断 sqlite 中是否已建立 FTS5 表
+func sqliteHasFTS(d *gorm.DB) bool {
+	var n int64
+	d.Raw("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", sqliteFTSTable).Scan(&n)
+	return n > 0
+}
+
+// ReindexTicket 根据工单标题、正文与未删除的非内部备注消息重建检索文档；工单不存在时删除文档
+func ReindexTicket(d *gorm.DB, ticketID uint) error {
+	var t Ticket
+	if err := d.First(&t, ticketID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return deleteSearchDoc(d, ticketID)
+		}
+		return err
+	}
+
+	var bodies []string
+	if err := d.Model(&TicketMessage{}).
+		Where("ticket_id = ? AND is_internal_note = ? AND deleted_at IS NULL", ticketID, false).
+		Order("id ASC").
+		Pluck("body", &bodies).Error; err != nil {
+		return err
+	}
+
+	parts := append([]string{t.Title, t.Content}, bodies...)
+	// 两端补空格，便于 LIKE 退化模式按整词匹配
+	tokens := " " + search.IndexText(strings.Join(parts, "\n")) + " "
+
+	return d.Transaction(func(tx *gorm.DB) error {
+		doc := &TicketSearchDoc{
+			TicketID:  t.ID,
+			UserID:    t.UserID,
+			Tokens:    tokens,
+			UpdatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "ticket_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"user_id", "tokens", "updated_at"}),
+		}).Create(doc).Error; err != nil {
+			return err
+		}
+		if tx.Dialector.Name() == "sqlite" && sqliteHasFTS(tx) {
+			if err := tx.Exec("DELETE FROM "+sqliteFTSTable+" WHERE ticket_id = ?", t.ID).Error; err != nil {
+				return err
+			}
+			return tx.Exec("INSERT INTO "+sqliteFTSTable+" (ticket_id, tokens) VALUES (?, ?)", t.ID, tokens).Error
+		}
+		return nil
+	})
+}
+
+func deleteSearchDoc(d *gorm.DB, ticketID uint) error {
+	if err := d.Delete(&TicketSearchDoc{}, "ticket_id = ?", ticketID).Error; err != nil {
+		return err
+	}
+	if d.Dialector.Name() == "sqlite" && sqliteHasFTS(d) {
+		return d.Exec("DELETE FROM "+sqliteFTSTable+" WHERE ticket_id = ?", ticketID).Error
+	}
+	return nil
+}
+
+// SearchTicketIDs 按检索词（须全部命中）查找工单，按相关度排序并分页。
+// ownerID 非空时只在该学生自己的工单中查找。
+func SearchTicketIDs(d *gorm.DB, terms []string, ownerID *uint, offset, limit int) ([]uint, int64, error) {
+	if len(terms) == 0 {
+		return nil, 0, nil
+	}
+
+	var q *gorm.DB
+	var order interface{} = "ticket_search_docs.updated_at DESC"
+	switch {
+	case d.Dialector.Name() == "postgres":
+		tsq := strings.Join(terms, " ")
+		q = d.Table("ticket_search_docs").
+			Where("to_tsvector('simple', tokens) @@ plainto_tsquery('simple', ?)", tsq)
+		order = clause.OrderBy{Expression: clause.Expr{
+			SQL:  "ts_rank(to_tsvector('simple', tokens), plainto_tsquery('simple', ?)) DESC, ticket_search_docs.ticket_id DESC",
+			Vars: []interface{}{tsq},
+		}}
+	case d.Dialector.Name() == "sqlite" && sqliteHasFTS(d):
+		quoted := make([]string, 0, len(terms))
+		for _, t := range terms {
+			quoted = append(quoted, `"`+strings.ReplaceAll(t, `"`, `""`)+`"`)
+		}
+		q = d.Table("ticket_search_docs").
+			Joins("JOIN "+sqliteFTSTable+" ON "+sqliteFTSTable+".ticket_id = ticket_search_docs.ticket_id").
+			Where(sqliteFTSTable+" MATCH ?", strings.Join(quoted, " "))
+		order = "bm25(" + sqliteFTSTable + ") ASC, ticket_search_docs.ticket_id DESC"
+	default:
+		// 检索词只含字母、数字与汉字，无需转义 LIKE 通配符
+		q = d.Table("ticket_search_docs")
+		for _, t := range terms {
+			q = q.Where("tokens LIKE ?", "% "+t+" %")
+		}
+	}
+	if ownerID != nil {
+		q = q.Where("ticket_search_docs.user_id = ?", *ownerID)
+	}
+
+	var total int64
+	if err := q.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var ids []uint
+	if err := q.Select("ticket_search_docs.ticket_id").
+		Order(order).
+		Offset(offset).Limit(limit).
+		Pluck("ticket_search_docs.ticket_id", &ids).Error; err != nil {
+		return nil, 0, err
+	}
+	return ids, total, nil
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxTagNameLen 标签名称最大长度（字符数）
+const MaxTagNameLen = 50
+
+// NormalizeTagName 规范化标签名称：去除首尾空白、转小写、内部空白替换为 '-'
+func NormalizeTagName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
+
+// EnsureTags 按名称（已规范化）查找标签，不存在的自动创建
+func EnsureTags(d *gorm.DB, names []string, actorID uint) ([]Tag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	rows := make([]Tag, 0, len(names))
+	for _, n := range names {
+		rows = append(rows, Tag{Name: n, CreatedBy: actorID, CreatedAt: now, UpdatedAt: now})
+	}
+	if err := d.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).
+		Create(&rows).Error; err != nil {
+		return nil, err
+	}
+	var tags []Tag
+	if err := d.Where("name IN ?", names).Order("name ASC").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetTicketTagsMap 批量获取工单的标签名称映射（按名称排序）
+func GetTicketTagsMap(d *gorm.DB, ticketIDs []uint) (map[uint][]string, error) {
+	out := make(map[uint][]string, len(ticketIDs))
+	if len(ticketIDs) == 0 {
+		return out, nil
+	}
+	type row struct {
+		TicketID uint
+		Name     string
+	}
+	var rows []row
+	if err := d.Table("ticket_tags tt").
+		Select("tt.ticket_id, t.name").
+		Joins("JOIN tags t ON t.id = tt.tag_id").
+		Where("tt.ticket_id IN ?", ticketIDs).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		out[r.TicketID] = append(out[r.TicketID], r.Name)
+	}
+	for _, names := range out {
+		sort.Strings(names)
+	}
+	return out, nil
+}
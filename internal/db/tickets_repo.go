@@ -1,10 +1,77 @@
 package db
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+// SLA 响应时限：紧急工单 24 小时，普通工单 72 小时
+const (
+	SLAWindowUrgent = 24 * time.Hour
+	SLAWindowNormal = 72 * time.Hour
+)
+
+// TicketSLADue 计算工单的 SLA 截止时间
+func TicketSLADue(createdAt time.Time, isUrgent bool) time.Time {
+	if isUrgent {
+		return createdAt.Add(SLAWindowUrgent)
+	}
+	return createdAt.Add(SLAWindowNormal)
+}
+
+// BackfillTicketSLADue 为历史工单补全 sla_due_at（按 SLA 排序和游标分页要求该列非空）
+func BackfillTicketSLADue(d *gorm.DB) error {
+	for {
+		var rows []Ticket
+		if err := d.Select("id", "created_at", "is_urgent").
+			Where("sla_due_at IS NULL").
+			Limit(500).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		for _, t := range rows {
+			due := TicketSLADue(t.CreatedAt, t.IsUrgent).UTC().Truncate(time.Microsecond)
+			if err := d.Model(&Ticket{}).Where("id = ?", t.ID).UpdateColumn("sla_due_at", due).Error; err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ValidTicketPriority 是否为合法的优先级
+func ValidTicketPriority(p TicketPriority) bool {
+	switch p {
+	case TicketPriorityP1, TicketPriorityP2, TicketPriorityP3, TicketPriorityP4:
+		return true
+	}
+	return false
+}
+
+// IsUrgentPriority P1、P2 视为紧急（按紧急工单的 SLA 计算）
+func IsUrgentPriority(p TicketPriority) bool {
+	return p == TicketPriorityP1 || p == TicketPriorityP2
+}
+
+// PriorityFromUrgent 仅给出是否紧急时对应的默认优先级：紧急 P2，否则 P3
+func PriorityFromUrgent(isUrgent bool) TicketPriority {
+	if isUrgent {
+		return TicketPriorityP2
+	}
+	return TicketPriorityP3
+}
+
+// BackfillTicketPriority 历史紧急工单的优先级补为 P2（新列默认 P3）
+func BackfillTicketPriority(d *gorm.DB) error {
+	return d.Model(&Ticket{}).
+		Where("is_urgent = ? AND priority = ?", true, TicketPriorityP3).
+		UpdateColumn("priority", TicketPriorityP2).Error
+}
+
 // 获取一批图片是否存在的 map[id]=>true
 func GetExistingImageIDs(d *gorm.DB, ids []uint) (map[uint]bool, error) {
 	out := make(map[uint]bool, len(ids))
@@ -67,4 +134,15 @@ func GetTicketByID(d *gorm.DB, id uint) (*Ticket, error) {
 		return nil, err
 	}
 	return &t, nil
-}
\ No newline at end of file
+}
+// 将工单的图片关联替换为给定列表（删除不在列表中的，补充新增的）
+func ReplaceTicketImages(d *gorm.DB, ticketID uint, imageIDs []uint) error {
+	q := d.Where("ticket_id = ?", ticketID)
+	if len(imageIDs) > 0 {
+		q = q.Where("image_id NOT IN ?", imageIDs)
+	}
+	if err := q.Delete(&TicketImage{}).Error; err != nil {
+		return err
+	}
+	return LinkTicketImages(d, ticketID, imageIDs)
+}
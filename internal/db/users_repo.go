@@ -1,6 +1,8 @@
 package db
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -72,4 +74,19 @@ func ListUsers(d *gorm.DB, page, pageSize int, role *Role) ([]User, int64, error
 	}
 	
 	return users, total, nil
-}
\ No newline at end of file
+}
+
+// ListUsersKeyset 按 (created_at DESC, id DESC) 游标分页列出用户；after 为空时从第一条开始
+func ListUsersKeyset(d *gorm.DB, role *Role, afterCreatedAt *time.Time, afterID uint, limit int) ([]User, error) {
+	var users []User
+	query := d.Model(&User{})
+	if role != nil {
+		query = query.Where("role = ?", *role)
+	}
+	if afterCreatedAt != nil {
+		at := afterCreatedAt.UTC()
+		query = query.Where("(created_at < ? OR (created_at = ? AND id < ?))", at, at, afterID)
+	}
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&users).Error
+	return users, err
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AddTicketWatcher 添加关注者；已关注时保持原记录不变，返回是否新增
+func AddTicketWatcher(d *gorm.DB, ticketID, userID, addedBy uint, source TicketWatcherSource, now time.Time) (bool, error) {
+	res := d.Clauses(clause.OnConflict{DoNothing: true}).Create(&TicketWatcher{
+		TicketID:  ticketID,
+		UserID:    userID,
+		Source:    source,
+		AddedBy:   addedBy,
+		CreatedAt: now,
+	})
+	return res.RowsAffected > 0, res.Error
+}
+
+// ListTicketWatchers 按关注时间列出工单的关注者
+func ListTicketWatchers(d *gorm.DB, ticketID uint) ([]TicketWatcher, error) {
+	var rows []TicketWatcher
+	err := d.Where("ticket_id = ?", ticketID).Order("created_at ASC, user_id ASC").Find(&rows).Error
+	return rows, err
+}
+
+// CountTicketWatchers 返回工单的关注者数量
+func CountTicketWatchers(d *gorm.DB, ticketID uint) (int64, error) {
+	var n int64
+	err := d.Model(&TicketWatcher{}).Where("ticket_id = ?", ticketID).Count(&n).Error
+	return n, err
+}
+
+// ListTicketWatcherEmails 返回工单关注者中在职、允许邮件提醒且当前在岗的管理员邮箱
+func ListTicketWatcherEmails(d *gorm.DB, ticketID uint, at time.Time) ([]string, error) {
+	var ids []uint
+	if err := d.Model(&TicketWatcher{}).Where("ticket_id = ?", ticketID).Pluck("user_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	onDuty, err := OnDutyAdminIDs(d, ids, at)
+	if err != nil || len(onDuty) == 0 {
+		return nil, err
+	}
+	var emails []string
+	err = d.Model(&User{}).
+		Where("id IN ? AND is_active = ? AND allow_email = ? AND email <> ''", onDuty, true, true).
+		Order("id ASC").
+		Pluck("email", &emails).Error
+	return emails, err
+}
@@ -0,0 +1,257 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	dbpkg "student-services-platform-backend/internal/db"
+	"student-services-platform-backend/internal/worker"
+
+	"gorm.io/gorm"
+)
+
+// DutyAwareRecipientResolver 感知管理员在岗状态的收件人解析器：
+//   - 面向管理员群体的通知（工单创建、垃圾标记）只发给当前在岗的管理员；
+//     工单所属分类配置了默认处理人时，工单创建通知优先发给其中在岗的处理人（或其代理人）
+//   - 面向具体管理员的通知，若其不在岗则改发给外出代理人（代理人也不在岗时跳过）
+//   - 消息、状态变更与评价通知额外抄送给工单的关注者（仅在岗者）
+//
+// 其余规则沿用 DefaultRecipientResolver。
+type DutyAwareRecipientResolver struct {
+	db       *gorm.DB
+	loc      *time.Location
+	fallback *DefaultRecipientResolver
+}
+
+// NewDutyAwareRecipientResolver 创建感知在岗状态的收件人解析器
+func NewDutyAwareRecipientResolver(db *gorm.DB, loc *time.Location, defaultAdminEmail string) *DutyAwareRecipientResolver {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &DutyAwareRecipientResolver{
+		db:       db,
+		loc:      loc,
+		fallback: NewDefaultRecipientResolver(defaultAdminEmail),
+	}
+}
+
+// ResolveRecipients 实现收件人解析逻辑
+func (r *DutyAwareRecipientResolver) ResolveRecipients(ctx context.Context, emailType worker.EmailType, emailContext map[string]interface{}) ([]string, error) {
+	now := time.Now().In(r.loc)
+
+	switch emailType {
+	case worker.EmailTypeTicketCreated:
+		emails, err := r.categoryAssigneeEmails(ctx, emailContext, now)
+		if err != nil || len(emails) > 0 {
+			return emails, err
+		}
+		return r.onDutyAdminEmails(ctx, []dbpkg.Role{dbpkg.RoleAdmin, dbpkg.RoleSuperAdmin}, now)
+	case worker.EmailTypeSpamFlagged:
+		return r.onDutyAdminEmails(ctx, []dbpkg.Role{dbpkg.RoleSuperAdmin}, now)
+	}
+
+	recipients, err := r.fallback.ResolveRecipients(ctx, emailType, emailContext)
+	if err != nil {
+		return nil, err
+	}
+	recipients, err = r.routeAroundOffDuty(ctx, recipients, now)
+	if err != nil || !watcherEmailTypes[emailType] {
+		return recipients, err
+	}
+	return r.appendWatcherEmails(ctx, recipients, emailContext, now)
+}
+
+// watcherEmailTypes 需要抄送给工单关注者的通知类型
+var watcherEmailTypes = map[worker.EmailType]bool{
+	worker.EmailTypeMessageReceived: true,
+	worker.EmailTypeTicketClaimed:   true,
+	worker.EmailTypeTicketUnclaimed: true,
+	worker.EmailTypeTicketResolved:  true,
+	worker.EmailTypeTicketClosed:    true,
+	worker.EmailTypeTicketWithdrawn: true,
+	worker.EmailTypeTicketMerged:    true,
+	worker.EmailTypeTicketRated:     true,
+}
+
+// appendWatcherEmails 将工单关注者中在岗的管理员追加到收件人（去重）
+func (r *DutyAwareRecipientResolver) appendWatcherEmails(ctx context.Context, recipients []string, emailContext map[string]interface{}, now time.Time) ([]string, error) {
+	ticketID, ok := emailContext["ticket_id"].(uint)
+	if !ok {
+		return recipients, nil
+	}
+	watchers, err := dbpkg.ListTicketWatcherEmails(r.db.WithContext(ctx), ticketID, now)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(recipients))
+	for _, e := range recipients {
+		seen[e] = true
+	}
+	for _, e := range watchers {
+		if !seen[e] {
+			seen[e] = true
+			recipients = append(recipients, e)
+		}
+	}
+	return recipients, nil
+}
+
+// onDutyAdminEmails 返回在岗且允许邮件提醒的管理员邮箱；一个都没有时回退到默认管理员邮箱
+func (r *DutyAwareRecipientResolver) onDutyAdminEmails(ctx context.Context, roles []dbpkg.Role, now time.Time) ([]string, error) {
+	admins, err := dbpkg.ListOnDutyAdmins(r.db.WithContext(ctx), roles, now)
+	if err != nil {
+		return nil, err
+	}
+	emails := make([]string, 0, len(admins))
+	for _, a := range admins {
+		if a.AllowEmail && a.Email != "" {
+			emails = append(emails, a.Email)
+		}
+	}
+	if len(emails) == 0 {
+		return []string{r.fallback.defaultAdminEmail}, nil
+	}
+	return emails, nil
+}
+
+// categoryAssigneeEmails 返回工单所属分类的默认处理人中当前可接收通知的邮箱（不在岗时转给代理人）；
+// 未配置默认处理人或无人可接收时返回空
+func (r *DutyAwareRecipientResolver) categoryAssigneeEmails(ctx context.Context, emailContext map[string]interface{}, now time.Time) ([]string, error) {
+	ticketID, ok := emailContext["ticket_id"].(uint)
+	if !ok {
+		return nil, nil
+	}
+	d := r.db.WithContext(ctx)
+	var t dbpkg.Ticket
+	if err := d.Select("id", "category_id").First(&t, ticketID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if t.CategoryID == nil {
+		return nil, nil
+	}
+	ids, err := dbpkg.ListCategoryAssigneeIDs(d, *t.CategoryID)
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	var admins []dbpkg.User
+	if err := d.Where("id IN ? AND is_active = ? AND allow_email = ?", ids, true, true).
+		Order("id ASC").
+		Find(&admins).Error; err != nil {
+		return nil, err
+	}
+	emails := make([]string, 0, len(admins))
+	for _, a := range admins {
+		if a.Email != "" {
+			emails = append(emails, a.Email)
+		}
+	}
+	return r.routeAroundOffDuty(ctx, emails, now)
+}
+
+// routeAroundOffDuty 将不在岗的管理员收件人替换为其在岗代理人；学生与未知邮箱原样保留。
+// 用户、在岗状态、外出记录与代理人均按批查询，查询次数与收件人数量无关
+func (r *DutyAwareRecipientResolver) routeAroundOffDuty(ctx context.Context, recipients []string, now time.Time) ([]string, error) {
+	if len(recipients) == 0 {
+		return recipients, nil
+	}
+	d := r.db.WithContext(ctx)
+
+	var users []dbpkg.User
+	if err := d.Select("id", "email", "role").Where("email IN ?", recipients).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	byEmail := make(map[string]dbpkg.User, len(users))
+	var adminIDs []uint
+	for _, u := range users {
+		byEmail[u.Email] = u
+		if u.Role == dbpkg.RoleAdmin || u.Role == dbpkg.RoleSuperAdmin {
+			adminIDs = append(adminIDs, u.ID)
+		}
+	}
+
+	onDuty, err := r.onDutySet(d, adminIDs, now)
+	if err != nil {
+		return nil, err
+	}
+	var offDuty []uint
+	for _, id := range adminIDs {
+		if !onDuty[id] {
+			offDuty = append(offDuty, id)
+		}
+	}
+
+	// 不在岗：若有外出代理人且代理人在岗，则转给代理人
+	ooos, err := dbpkg.ListActiveOutOfOffice(d, offDuty, now)
+	if err != nil {
+		return nil, err
+	}
+	var delegateIDs []uint
+	for _, o := range ooos {
+		if o.DelegateUserID != nil {
+			delegateIDs = append(delegateIDs, *o.DelegateUserID)
+		}
+	}
+	delegateOnDuty, err := r.onDutySet(d, delegateIDs, now)
+	if err != nil {
+		return nil, err
+	}
+	delegateEmails := map[uint]string{}
+	if len(delegateOnDuty) > 0 {
+		ids := make([]uint, 0, len(delegateOnDuty))
+		for id := range delegateOnDuty {
+			ids = append(ids, id)
+		}
+		var delegates []dbpkg.User
+		if err := d.Select("id", "email").Where("id IN ?", ids).Find(&delegates).Error; err != nil {
+			return nil, err
+		}
+		for _, u := range delegates {
+			delegateEmails[u.ID] = u.Email
+		}
+	}
+
+	out := make([]string, 0, len(recipients))
+	seen := map[string]bool{}
+	add := func(email string) {
+		if email != "" && !seen[email] {
+			seen[email] = true
+			out = append(out, email)
+		}
+	}
+	for _, email := range recipients {
+		u, ok := byEmail[email]
+		switch {
+		case !ok:
+			add(email) // 非系统用户（例如默认管理员邮箱）
+		case u.Role != dbpkg.RoleAdmin && u.Role != dbpkg.RoleSuperAdmin:
+			add(email)
+		case onDuty[u.ID]:
+			add(email)
+		default:
+			if o, ok := ooos[u.ID]; ok && o.DelegateUserID != nil {
+				add(delegateEmails[*o.DelegateUserID])
+			}
+		}
+	}
+	return out, nil
+}
+
+// onDutySet 批量判断在岗状态，返回在岗管理员 ID 集合
+func (r *DutyAwareRecipientResolver) onDutySet(d *gorm.DB, ids []uint, now time.Time) (map[uint]bool, error) {
+	out := map[uint]bool{}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	onDuty, err := dbpkg.OnDutyAdminIDs(d, ids, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range onDuty {
+		out[id] = true
+	}
+	return out, nil
+}
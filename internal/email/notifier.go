@@ -21,14 +21,16 @@ func NewNotifier(emailService *Service) *Notifier {
 }
 
 // NotifyTicketCreated 通知工单创建
-func (n *Notifier) NotifyTicketCreated(ctx context.Context, ticketID uint, title, category, creatorName string) error {
+func (n *Notifier) NotifyTicketCreated(ctx context.Context, ticketID uint, title, category, creatorName string, isAnonymous bool) error {
 	subject := fmt.Sprintf("新工单创建 - %s", title)
 
+	// 匿名工单：creatorName 为代号，模板不展示任何可识别身份的信息
 	emailContext := map[string]interface{}{
 		"ticket_id":    ticketID,
 		"title":        title,
 		"category":     category,
 		"student_name": creatorName,
+		"is_anonymous": isAnonymous,
 		"created_at":   time.Now().Format("2006-01-02 15:04:05"),
 	}
 
@@ -120,6 +122,23 @@ func (n *Notifier) NotifyNewMessage(ctx context.Context, ticketID uint, senderNa
 	return n.emailService.SendEmailWithDynamicRecipients(ctx, worker.EmailTypeMessageReceived, subject, "", emailContext)
 }
 
+// NotifyMentioned 通知管理员在内部备注中被 @ 提及
+func (n *Notifier) NotifyMentioned(ctx context.Context, ticketID uint, title, senderName, message string, mentionedEmails []string) error {
+	subject := fmt.Sprintf("%s 在工单中提到了您 - #%d", senderName, ticketID)
+
+	emailContext := map[string]interface{}{
+		"ticket_id":        ticketID,
+		"title":            title,
+		"sender_name":      senderName,
+		"message_body":     message,
+		"message_time":     time.Now().Format("2006-01-02 15:04:05"),
+		"mentioned_emails": mentionedEmails,
+		"ticket_url":       fmt.Sprintf("/tickets/%d", ticketID), // 前端路由
+	}
+
+	return n.emailService.SendEmailWithDynamicRecipients(ctx, worker.EmailTypeMentioned, subject, "", emailContext)
+}
+
 // NotifyUserCreated 通知新用户创建
 func (n *Notifier) NotifyUserCreated(ctx context.Context, userName, userEmail, userRole string) error {
 	subject := fmt.Sprintf("新用户注册 - %s", userName)
@@ -179,6 +198,42 @@ func (n *Notifier) NotifyTicketUnclaimed(ctx context.Context, ticketID uint, tit
 	return n.emailService.SendEmailWithDynamicRecipients(ctx, worker.EmailTypeTicketUnclaimed, subject, "", emailContext)
 }
 
+// NotifyTicketWithdrawn 通知负责人工单已被学生撤回
+func (n *Notifier) NotifyTicketWithdrawn(ctx context.Context, ticketID uint, title, reason, creatorName, handlerName, handlerEmail string) error {
+	subject := fmt.Sprintf("工单已被撤回 - %s", title)
+
+	emailContext := map[string]interface{}{
+		"ticket_id":     ticketID,
+		"title":         title,
+		"reason":        reason,
+		"student_name":  creatorName,
+		"admin_name":    handlerName,
+		"handler_email": handlerEmail,
+		"withdrawn_at":  time.Now().Format("2006-01-02 15:04:05"),
+		"ticket_url":    fmt.Sprintf("/tickets/%d", ticketID),
+	}
+
+	return n.emailService.SendEmailWithDynamicRecipients(ctx, worker.EmailTypeTicketWithdrawn, subject, "", emailContext)
+}
+
+// NotifyTicketMerged 通知学生其工单已被合并到另一工单
+func (n *Notifier) NotifyTicketMerged(ctx context.Context, sourceID, targetID uint, sourceTitle, targetTitle, creatorEmail string) error {
+	subject := fmt.Sprintf("工单已合并 - %s", sourceTitle)
+
+	emailContext := map[string]interface{}{
+		"ticket_id":     sourceID,
+		"title":         sourceTitle,
+		"target_id":     targetID,
+		"target_title":  targetTitle,
+		"student_name":  "学生", // 默认值，实际应用中应从数据库获取
+		"creator_email": creatorEmail,
+		"merged_at":     time.Now().Format("2006-01-02 15:04:05"),
+		"ticket_url":    fmt.Sprintf("/tickets/%d", targetID),
+	}
+
+	return n.emailService.SendEmailWithDynamicRecipients(ctx, worker.EmailTypeTicketMerged, subject, "", emailContext)
+}
+
 // NotifyTicketRated 通知工单被评价
 func (n *Notifier) NotifyTicketRated(ctx context.Context, ticketID uint, title, rating string, comments, handlerEmail string) error {
 	subject := fmt.Sprintf("工单已被评价 - %s", title)
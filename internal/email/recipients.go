@@ -37,8 +37,14 @@ func (r *DefaultRecipientResolver) ResolveRecipients(ctx context.Context, emailT
 		return r.resolveTicketResolvedRecipients(ctx, emailContext)
 	case worker.EmailTypeTicketClosed:
 		return r.resolveTicketClosedRecipients(ctx, emailContext)
+	case worker.EmailTypeTicketWithdrawn:
+		return r.resolveTicketWithdrawnRecipients(ctx, emailContext)
+	case worker.EmailTypeTicketMerged:
+		return r.resolveTicketMergedRecipients(ctx, emailContext)
 	case worker.EmailTypeMessageReceived:
 		return r.resolveMessageReceivedRecipients(ctx, emailContext)
+	case worker.EmailTypeMentioned:
+		return r.resolveMentionedRecipients(ctx, emailContext)
 	case worker.EmailTypeUserCreated:
 		return r.resolveUserCreatedRecipients(ctx, emailContext)
 	case worker.EmailTypePasswordReset:
@@ -101,6 +107,22 @@ func (r *DefaultRecipientResolver) resolveTicketClosedRecipients(ctx context.Con
 	return r.resolveTicketResolvedRecipients(ctx, emailContext)
 }
 
+// resolveTicketWithdrawnRecipients 工单被撤回时的收件人（通知负责的管理员）
+func (r *DefaultRecipientResolver) resolveTicketWithdrawnRecipients(ctx context.Context, emailContext map[string]interface{}) ([]string, error) {
+	if handlerEmail, ok := emailContext["handler_email"].(string); ok && handlerEmail != "" {
+		return []string{handlerEmail}, nil
+	}
+	return nil, fmt.Errorf("处理人员邮箱信息缺失")
+}
+
+// resolveTicketMergedRecipients 工单被合并时的收件人（通知工单创建者）
+func (r *DefaultRecipientResolver) resolveTicketMergedRecipients(ctx context.Context, emailContext map[string]interface{}) ([]string, error) {
+	if creatorEmail, ok := emailContext["creator_email"].(string); ok {
+		return []string{creatorEmail}, nil
+	}
+	return nil, fmt.Errorf("工单创建者邮箱信息缺失")
+}
+
 // resolveMessageReceivedRecipients 收到新消息时的收件人
 func (r *DefaultRecipientResolver) resolveMessageReceivedRecipients(ctx context.Context, emailContext map[string]interface{}) ([]string, error) {
 	// 通知工单的相关人员（创建者和处理者）
@@ -117,6 +139,14 @@ func (r *DefaultRecipientResolver) resolveMessageReceivedRecipients(ctx context.
 	return recipients, nil
 }
 
+// resolveMentionedRecipients 被 @ 提及时的收件人（通知被提及的管理员）
+func (r *DefaultRecipientResolver) resolveMentionedRecipients(ctx context.Context, emailContext map[string]interface{}) ([]string, error) {
+	if emails, ok := emailContext["mentioned_emails"].([]string); ok && len(emails) > 0 {
+		return emails, nil
+	}
+	return nil, fmt.Errorf("被提及人邮箱信息缺失")
+}
+
 // resolveUserCreatedRecipients 新用户创建时的收件人（通知管理员）
 func (r *DefaultRecipientResolver) resolveUserCreatedRecipients(ctx context.Context, emailContext map[string]interface{}) ([]string, error) {
 	// 通知管理员有新用户注册
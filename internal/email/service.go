@@ -72,6 +72,7 @@ func (s *Service) SendEmail(ctx context.Context, task *worker.EmailTask) error {
 	headers["Subject"] = encodedSubject
 	headers["MIME-Version"] = "1.0"
 	headers["Content-Type"] = "text/html; charset=UTF-8"
+	headers["X-Priority"] = task.Priority.XPriority()
 
 	// 构建邮件内容（不使用Base64编码）
 	message := ""
@@ -184,7 +185,7 @@ func (s *Service) SendTemplateEmail(ctx context.Context, emailType string, conte
 		Subject:  s.getSubjectFromContext(context),
 		Body:     templateBody,
 		Type:     worker.EmailType(emailType),
-		Priority: worker.EmailPriorityNormal,
+		Priority: worker.PriorityFromContext(ctx),
 		Context:  context,
 	}
 
@@ -229,7 +230,7 @@ func (s *Service) SendEmailWithDynamicRecipients(ctx context.Context, emailType
 		Subject:  subject,
 		Body:     body,
 		Type:     emailType,
-		Priority: worker.EmailPriorityNormal,
+		Priority: worker.PriorityFromContext(ctx),
 		Context:  emailContext,
 	}
 
@@ -39,6 +39,8 @@ func CORS(cfg config.CORSConfig) gin.HandlerFunc {
                 if len(cfg.AllowedMethods) > 0 {
                     c.Header("Access-Control-Allow-Methods", strings.Join(cfg.AllowedMethods, ", "))
                 }
+                // 允许前端读取请求 ID，便于反馈问题时关联审计日志
+                c.Header("Access-Control-Expose-Headers", "X-Request-ID")
             }
         }
 
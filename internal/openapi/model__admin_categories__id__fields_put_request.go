@@ -0,0 +1,16 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type AdminCategoriesIdFieldsPutRequest struct {
+
+	// 完整的字段列表（按顺序），未出现的已有字段将被归档
+	Fields []CategoryField `json:"fields"`
+}
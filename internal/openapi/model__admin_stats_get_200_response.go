@@ -15,6 +15,8 @@ type AdminStatsGet200Response struct {
 
 	ByCategory []AdminStatsGet200ResponseByCategoryInner `json:"by_category,omitempty"`
 
+	ByTag []AdminStatsGet200ResponseByTagInner `json:"by_tag,omitempty"`
+
 	DailyTrend []AdminStatsGet200ResponseDailyTrendInner `json:"daily_trend,omitempty"`
 
 	AdminWorkload []AdminStatsGet200ResponseAdminWorkloadInner `json:"admin_workload,omitempty"`
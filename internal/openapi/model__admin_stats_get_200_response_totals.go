@@ -11,6 +11,7 @@ package openapi
 
 type AdminStatsGet200ResponseTotals struct {
 
+	// 工单数（不含已撤回与已合并）
 	Tickets int32 `json:"tickets,omitempty"`
 
 	Resolved int32 `json:"resolved,omitempty"`
@@ -18,4 +19,10 @@ type AdminStatsGet200ResponseTotals struct {
 	Closed int32 `json:"closed,omitempty"`
 
 	SpamConfirmed int32 `json:"spam_confirmed,omitempty"`
+
+	// 被创建者撤回的工单数
+	Cancelled int32 `json:"cancelled,omitempty"`
+
+	// 作为重复工单被合并的工单数
+	Merged int32 `json:"merged,omitempty"`
 }
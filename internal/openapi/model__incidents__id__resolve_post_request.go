@@ -0,0 +1,16 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type IncidentsIdResolvePostRequest struct {
+
+	// 处理说明（可选），填写时作为最后一条进展同步到所有关联工单
+	Resolution string `json:"resolution,omitempty"`
+}
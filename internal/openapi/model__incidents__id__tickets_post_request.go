@@ -0,0 +1,15 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type IncidentsIdTicketsPostRequest struct {
+
+	TicketIds []int32 `json:"ticket_ids"`
+}
@@ -0,0 +1,19 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type TicketsIdMessagesMessageIdPatchRequest struct {
+
+	// 新正文；不传则保持不变
+	Body *string `json:"body,omitempty"`
+
+	// 仅管理员可修改：在内部备注与公开消息之间切换
+	IsInternalNote *bool `json:"is_internal_note,omitempty"`
+}
@@ -15,4 +15,7 @@ type TicketsIdMessagesPostRequest struct {
 
 	// 管理员内部备注（学生不可见）
 	IsInternalNote bool `json:"is_internal_note,omitempty"`
+
+	// 附件 ID（先通过 POST /images 上传，支持图片与 PDF）；有附件时 body 可为空
+	AttachmentIds []int32 `json:"attachment_ids,omitempty"`
 }
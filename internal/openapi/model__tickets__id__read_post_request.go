@@ -0,0 +1,16 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type TicketsIdReadPostRequest struct {
+
+	// 已读到的消息 ID；不传则标记到最新一条
+	MessageId *int32 `json:"message_id,omitempty"`
+}
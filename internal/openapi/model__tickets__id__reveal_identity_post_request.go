@@ -0,0 +1,16 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type TicketsIdRevealIdentityPostRequest struct {
+
+	// 查看原因（写入审计日志）
+	Reason string `json:"reason"`
+}
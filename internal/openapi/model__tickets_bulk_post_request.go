@@ -0,0 +1,31 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type TicketsBulkPostRequest struct {
+
+	// 最多 100 个，重复 ID 只处理一次
+	TicketIds []int32 `json:"ticket_ids"`
+
+	// claim / close / tag / assign / spam_flag / set_priority
+	Action string `json:"action"`
+
+	// action=tag 时必填
+	Tags []string `json:"tags,omitempty"`
+
+	// action=assign 时必填
+	AssigneeId int32 `json:"assignee_id,omitempty"`
+
+	// action=set_priority 时必填
+	Priority TicketPriority `json:"priority,omitempty"`
+
+	// action=spam_flag 时必填；set_priority 时可选
+	Reason string `json:"reason,omitempty"`
+}
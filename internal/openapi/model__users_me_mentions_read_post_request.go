@@ -0,0 +1,16 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type UsersMeMentionsReadPostRequest struct {
+
+	// 要标记为已读的提及 ID；为空时全部标记为已读
+	Ids []int32 `json:"ids,omitempty"`
+}
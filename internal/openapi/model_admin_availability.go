@@ -0,0 +1,35 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type AdminAvailability struct {
+
+	UserId int32 `json:"user_id,omitempty"`
+
+	Name string `json:"name,omitempty"`
+
+	Status AvailabilityStatus `json:"status,omitempty"`
+
+	Note string `json:"note,omitempty"`
+
+	// 综合状态、班次与外出计算出的当前是否在岗
+	OnDuty bool `json:"on_duty"`
+
+	Shifts []AdminShift `json:"shifts"`
+
+	// 当前及未来的外出安排
+	OutOfOffice []AdminOutOfOffice `json:"out_of_office"`
+
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
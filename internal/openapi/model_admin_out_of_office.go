@@ -0,0 +1,32 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type AdminOutOfOffice struct {
+
+	Id int32 `json:"id,omitempty"`
+
+	AdminUserId int32 `json:"admin_user_id,omitempty"`
+
+	StartAt time.Time `json:"start_at,omitempty"`
+
+	EndAt time.Time `json:"end_at,omitempty"`
+
+	// 外出期间的代理管理员
+	DelegateUserId *int32 `json:"delegate_user_id,omitempty"`
+
+	Reason string `json:"reason,omitempty"`
+
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
@@ -0,0 +1,25 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type AdminOutOfOfficeCreate struct {
+
+	StartAt time.Time `json:"start_at"`
+
+	EndAt time.Time `json:"end_at"`
+
+	DelegateUserId *int32 `json:"delegate_user_id,omitempty"`
+
+	Reason string `json:"reason,omitempty"`
+}
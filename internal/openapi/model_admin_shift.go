@@ -0,0 +1,24 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type AdminShift struct {
+
+	Id int32 `json:"id,omitempty"`
+
+	// 星期几（0=周日 ... 6=周六）
+	Weekday int32 `json:"weekday"`
+
+	// 开始时间 HH:MM（值班时区）
+	Start string `json:"start"`
+
+	// 结束时间 HH:MM；早于开始时间表示跨夜班次
+	End string `json:"end"`
+}
@@ -0,0 +1,23 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type AuditChainBreak struct {
+
+	// 出问题的审计日志 ID
+	Id int32 `json:"id"`
+
+	// prev_hash_mismatch / hash_mismatch / head_mismatch / anchor_missing / anchor_mismatch
+	Kind string `json:"kind"`
+
+	Expected string `json:"expected"`
+
+	Actual string `json:"actual"`
+}
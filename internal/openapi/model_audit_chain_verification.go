@@ -0,0 +1,40 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type AuditChainVerification struct {
+
+	Ok bool `json:"ok"`
+
+	// 校验的审计日志条数
+	Checked int64 `json:"checked"`
+
+	// 不入链而跳过的审计日志条数（失败登录）
+	Unchained int64 `json:"unchained"`
+
+	LastId int32 `json:"last_id"`
+
+	LastHash string `json:"last_hash"`
+
+	// 核对的锚点数
+	AnchorsChecked int32 `json:"anchors_checked"`
+
+	// 问题总数
+	BreakCount int32 `json:"break_count"`
+
+	// 最多列出 100 条
+	Breaks []AuditChainBreak `json:"breaks"`
+
+	VerifiedAt time.Time `json:"verified_at"`
+}
@@ -0,0 +1,23 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type AuditLogChange struct {
+
+	Field string `json:"field"`
+
+	// 变更前的值（diff 中形如 {"from": ..., "to": ...} 的字段）
+	From interface{} `json:"from,omitempty"`
+
+	To interface{} `json:"to,omitempty"`
+
+	// 非 from/to 形式的字段直接给出取值
+	Value interface{} `json:"value,omitempty"`
+}
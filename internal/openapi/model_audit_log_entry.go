@@ -0,0 +1,49 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type AuditLogEntry struct {
+
+	Id int32 `json:"id"`
+
+	ActorUserId int32 `json:"actor_user_id"`
+
+	ActorName string `json:"actor_name,omitempty"`
+
+	// 匿名工单上创建者的代号（此时 actor_user_id 为 0、actor_name 为空）
+	ActorHandle string `json:"actor_handle,omitempty"`
+
+	Action string `json:"action"`
+
+	Entity string `json:"entity"`
+
+	EntityId int32 `json:"entity_id"`
+
+	// 客户端 IP（后台任务等无请求的操作为空）
+	Ip string `json:"ip,omitempty"`
+
+	// 请求 ID，与响应头 X-Request-ID 一致
+	RequestId string `json:"request_id,omitempty"`
+
+	// 原始 diff
+	Diff map[string]interface{} `json:"diff,omitempty"`
+
+	// 按字段名排序的变更明细
+	Changes []AuditLogChange `json:"changes,omitempty"`
+
+	// 可读的变更摘要，例如 "priority: P3 → P1；reason: 安全隐患"
+	Summary string `json:"summary,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
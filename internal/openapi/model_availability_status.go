@@ -0,0 +1,19 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type AvailabilityStatus string
+
+// List of AvailabilityStatus
+const (
+	ONLINE AvailabilityStatus = "ONLINE"
+	AWAY AvailabilityStatus = "AWAY"
+	OFF_DUTY AvailabilityStatus = "OFF_DUTY"
+)
@@ -0,0 +1,22 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type BulkTicketItemResult struct {
+
+	TicketId int32 `json:"ticket_id"`
+
+	Success bool `json:"success"`
+
+	// 失败原因分类：not_found / forbidden / validation / conflict / invalid_state / merged / internal
+	Code string `json:"code,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,24 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type BulkTicketResult struct {
+
+	Action string `json:"action"`
+
+	Total int32 `json:"total"`
+
+	Succeeded int32 `json:"succeeded"`
+
+	Failed int32 `json:"failed"`
+
+	// 与请求中去重后的 ticket_ids 顺序一致
+	Results []BulkTicketItemResult `json:"results"`
+}
@@ -0,0 +1,26 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type Category struct {
+
+	Id int32 `json:"id,omitempty"`
+
+	Name string `json:"name,omitempty"`
+
+	Description string `json:"description"`
+
+	ParentId *int32 `json:"parent_id"`
+
+	SortOrder int32 `json:"sort_order"`
+
+	// 提单时需填写的自定义字段
+	Fields []CategoryField `json:"fields"`
+}
@@ -0,0 +1,46 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type CategoryDetail struct {
+
+	Id int32 `json:"id,omitempty"`
+
+	Name string `json:"name,omitempty"`
+
+	Description string `json:"description"`
+
+	ParentId *int32 `json:"parent_id"`
+
+	SortOrder int32 `json:"sort_order"`
+
+	Fields []CategoryField `json:"fields"`
+
+	IsActive bool `json:"is_active"`
+
+	// 负责部门
+	Department string `json:"department"`
+
+	// 别名（小写），提单时按别名也能匹配到该分类
+	Aliases []string `json:"aliases"`
+
+	// 默认处理人，新工单通知优先发送给其中在岗的管理员
+	DefaultAssigneeIds []int32 `json:"default_assignee_ids"`
+
+	TicketCount int32 `json:"ticket_count"`
+
+	CreatedAt time.Time `json:"created_at,omitempty"`
+
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
@@ -0,0 +1,34 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type CategoryField struct {
+
+	// 字段键，小写字母开头，仅含小写字母、数字和下划线
+	Key string `json:"key"`
+
+	Label string `json:"label"`
+
+	Type CategoryFieldType `json:"type"`
+
+	Required bool `json:"required"`
+
+	// SELECT 类型的可选值
+	Options []string `json:"options,omitempty"`
+
+	// TEXT 类型的最大长度（默认 500）
+	MaxLength *int32 `json:"max_length,omitempty"`
+
+	// NUMBER 类型的最小值
+	Min *float64 `json:"min,omitempty"`
+
+	// NUMBER 类型的最大值
+	Max *float64 `json:"max,omitempty"`
+}
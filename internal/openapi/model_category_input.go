@@ -0,0 +1,31 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type CategoryInput struct {
+
+	Name string `json:"name"`
+
+	Description string `json:"description,omitempty"`
+
+	// 父分类（仅支持一级）
+	ParentId *int32 `json:"parent_id,omitempty"`
+
+	// 默认 true
+	IsActive *bool `json:"is_active,omitempty"`
+
+	SortOrder int32 `json:"sort_order,omitempty"`
+
+	Department string `json:"department,omitempty"`
+
+	Aliases []string `json:"aliases,omitempty"`
+
+	DefaultAssigneeIds []int32 `json:"default_assignee_ids,omitempty"`
+}
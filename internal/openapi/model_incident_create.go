@@ -0,0 +1,20 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type IncidentCreate struct {
+
+	Title string `json:"title"`
+
+	Description string `json:"description,omitempty"`
+
+	// 创建时直接关联的工单
+	TicketIds []int32 `json:"ticket_ids,omitempty"`
+}
@@ -0,0 +1,42 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type IncidentDetail struct {
+
+	Id int32 `json:"id"`
+
+	Title string `json:"title"`
+
+	Description string `json:"description"`
+
+	// OPEN 或 RESOLVED
+	Status string `json:"status"`
+
+	CreatedBy int32 `json:"created_by"`
+
+	// 关联工单数
+	TicketCount int32 `json:"ticket_count"`
+
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Tickets []Ticket `json:"tickets"`
+
+	// 按发布时间升序
+	Updates []IncidentUpdate `json:"updates"`
+}
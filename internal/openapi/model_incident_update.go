@@ -0,0 +1,30 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type IncidentUpdate struct {
+
+	Id int32 `json:"id"`
+
+	IncidentId int32 `json:"incident_id"`
+
+	AuthorId int32 `json:"author_id"`
+
+	Body string `json:"body"`
+
+	// 同步到的工单数
+	TicketCount int32 `json:"ticket_count"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,37 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type Mention struct {
+
+	Id int32 `json:"id"`
+
+	TicketId int32 `json:"ticket_id"`
+
+	TicketTitle string `json:"ticket_title"`
+
+	MessageId int32 `json:"message_id"`
+
+	// 备注内容摘要（最多 200 字）
+	Excerpt string `json:"excerpt"`
+
+	MentionedBy int32 `json:"mentioned_by"`
+
+	MentionedByName string `json:"mentioned_by_name"`
+
+	CreatedAt time.Time `json:"created_at"`
+
+	// 为空表示未读
+	ReadAt *time.Time `json:"read_at"`
+}
@@ -0,0 +1,24 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type PagedAuditLogs struct {
+
+	Items []AuditLogEntry `json:"items"`
+
+	Page int32 `json:"page,omitempty"`
+
+	PageSize int32 `json:"page_size,omitempty"`
+
+	Total int32 `json:"total,omitempty"`
+
+	// 游标分页时返回，用于获取下一页；为空表示没有更多数据
+	NextCursor string `json:"next_cursor,omitempty"`
+}
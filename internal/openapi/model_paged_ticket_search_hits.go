@@ -0,0 +1,21 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type PagedTicketSearchHits struct {
+
+	Items []TicketSearchHit `json:"items"`
+
+	Page int32 `json:"page,omitempty"`
+
+	PageSize int32 `json:"page_size,omitempty"`
+
+	Total int32 `json:"total,omitempty"`
+}
@@ -18,4 +18,7 @@ type PagedUsers struct {
 	PageSize int32 `json:"page_size,omitempty"`
 
 	Total int32 `json:"total,omitempty"`
+
+	// 游标分页时返回，用于获取下一页；为空表示没有更多数据
+	NextCursor string `json:"next_cursor,omitempty"`
 }
@@ -0,0 +1,26 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type RevealedIdentity struct {
+
+	TicketId int32 `json:"ticket_id"`
+
+	UserId int32 `json:"user_id"`
+
+	Name string `json:"name"`
+
+	Email string `json:"email"`
+
+	Dept *string `json:"dept,omitempty"`
+
+	// 该工单中使用的匿名代号
+	CreatorHandle string `json:"creator_handle"`
+}
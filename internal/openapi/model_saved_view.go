@@ -0,0 +1,37 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type SavedView struct {
+
+	Id int32 `json:"id"`
+
+	OwnerUserId int32 `json:"owner_user_id"`
+
+	OwnerName string `json:"owner_name,omitempty"`
+
+	Name string `json:"name"`
+
+	Filters TicketViewFilters `json:"filters"`
+
+	// 是否共享给其他管理员
+	IsShared bool `json:"is_shared"`
+
+	// 当前查看者在该视图下可见的工单数
+	Count *int32 `json:"count,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,19 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type SavedViewCreate struct {
+
+	Name string `json:"name"`
+
+	Filters TicketViewFilters `json:"filters"`
+
+	IsShared bool `json:"is_shared"`
+}
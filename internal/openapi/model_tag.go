@@ -0,0 +1,28 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type Tag struct {
+
+	Id int32 `json:"id,omitempty"`
+
+	Name string `json:"name,omitempty"`
+
+	// 使用该标签的工单数
+	TicketCount int32 `json:"ticket_count"`
+
+	CreatedBy int32 `json:"created_by,omitempty"`
+
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
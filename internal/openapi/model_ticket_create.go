@@ -15,12 +15,22 @@ type TicketCreate struct {
 
 	Content string `json:"content"`
 
-	Category string `json:"category"`
+	// 分类名称或别名（不区分大小写）；提供 category_id 时可省略
+	Category string `json:"category,omitempty"`
+
+	// 分类ID（来自 GET /categories），优先于 category
+	CategoryId int32 `json:"category_id,omitempty"`
 
 	IsUrgent bool `json:"is_urgent"`
 
+	// 学生可选 P2-P4（P1 仅管理员可设置）；不传时按 is_urgent 推导：紧急为 P2，否则为 P3
+	Priority *TicketPriority `json:"priority,omitempty"`
+
 	IsAnonymous bool `json:"is_anonymous"`
 
 	// 由 /images 上传返回的 image_id 列表
 	ImageIds []int32 `json:"image_ids,omitempty"`
+
+	// 分类自定义字段值（key -> value），NUMBER 传数字，DATE 传 YYYY-MM-DD
+	Fields map[string]interface{} `json:"fields,omitempty"`
 }
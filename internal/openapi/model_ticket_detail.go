@@ -19,14 +19,22 @@ type TicketDetail struct {
 
 	UserId int32 `json:"user_id,omitempty"`
 
+	// 匿名工单中学生的代号；此时不返回 user_id（创建者本人除外）
+	CreatorHandle string `json:"creator_handle,omitempty"`
+
 	Title string `json:"title,omitempty"`
 
 	Content string `json:"content,omitempty"`
 
 	Category string `json:"category,omitempty"`
 
+	CategoryId *int32 `json:"category_id,omitempty"`
+
 	IsUrgent bool `json:"is_urgent,omitempty"`
 
+	// P1 最高，P4 最低；P1/P2 视为紧急
+	Priority TicketPriority `json:"priority,omitempty"`
+
 	IsAnonymous bool `json:"is_anonymous,omitempty"`
 
 	Status TicketStatus `json:"status,omitempty"`
@@ -35,6 +43,12 @@ type TicketDetail struct {
 
 	ClaimedAt *time.Time `json:"claimed_at,omitempty"`
 
+	// SLA 截止时间（紧急 24 小时，普通 72 小时）
+	SlaDueAt *time.Time `json:"sla_due_at,omitempty"`
+
+	// 状态为 MERGED 时指向合并目标工单
+	MergedIntoTicketId *int32 `json:"merged_into_ticket_id,omitempty"`
+
 	CreatedAt time.Time `json:"created_at,omitempty"`
 
 	UpdatedAt time.Time `json:"updated_at,omitempty"`
@@ -46,4 +60,19 @@ type TicketDetail struct {
 
 	// Rating 字段如果不存在则为 null
 	Rating *Rating `json:"rating,omitempty"` 
+
+	// 编辑历史（仅管理员可见）
+	Revisions []TicketRevision `json:"revisions,omitempty"`
+
+	// 关联工单（学生仅能看到关联到本人工单的部分）
+	Links []TicketLink `json:"links,omitempty"`
+
+	// 标签（仅管理员可见）
+	Tags []string `json:"tags,omitempty"`
+
+	// 分类自定义字段值
+	Fields []TicketFieldValue `json:"fields,omitempty"`
+
+	// 关注者（仅管理员可见）
+	Watchers []TicketWatcher `json:"watchers,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,22 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type TicketFieldValue struct {
+
+	Key string `json:"key"`
+
+	Label string `json:"label"`
+
+	Type CategoryFieldType `json:"type"`
+
+	// NUMBER 为数字，其余为字符串
+	Value interface{} `json:"value"`
+}
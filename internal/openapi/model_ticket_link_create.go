@@ -0,0 +1,19 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type TicketLinkCreate struct {
+
+	// 从当前工单角度描述的关联类型
+	Type TicketLinkType `json:"type"`
+
+	// 关联的另一工单
+	TicketId int32 `json:"ticket_id"`
+}
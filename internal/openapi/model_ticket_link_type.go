@@ -0,0 +1,23 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type TicketLinkType string
+
+// List of TicketLinkType
+const (
+	RELATED TicketLinkType = "RELATED"
+	DUPLICATE_OF TicketLinkType = "DUPLICATE_OF"
+	DUPLICATED_BY TicketLinkType = "DUPLICATED_BY"
+	PARENT_OF TicketLinkType = "PARENT_OF"
+	CHILD_OF TicketLinkType = "CHILD_OF"
+	FOLLOW_UP_OF TicketLinkType = "FOLLOW_UP_OF"
+	FOLLOWED_UP_BY TicketLinkType = "FOLLOWED_UP_BY"
+)
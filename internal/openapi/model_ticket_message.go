@@ -21,9 +21,35 @@ type TicketMessage struct {
 
 	SenderUserId int32 `json:"sender_user_id,omitempty"`
 
+	// 匿名工单中学生发送的消息以代号标识，此时不返回 sender_user_id
+	SenderHandle string `json:"sender_handle,omitempty"`
+
 	Body string `json:"body,omitempty"`
 
 	IsInternalNote bool `json:"is_internal_note,omitempty"`
 
+	// 内部备注中 @ 提及的管理员
+	MentionedUserIds []int32 `json:"mentioned_user_ids,omitempty"`
+
+	// 附件 ID，通过 GET /images/{id} 下载
+	AttachmentIds []int32 `json:"attachment_ids,omitempty"`
+
 	CreatedAt time.Time `json:"created_at,omitempty"`
+
+	// 发送者最后编辑时间；未编辑过为空
+	EditedAt *time.Time `json:"edited_at,omitempty"`
+
+	// 已被管理员删除（墓碑），此时 body 与附件不返回
+	IsDeleted bool `json:"is_deleted,omitempty"`
+
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	// 内容已被超级管理员抹除
+	IsRedacted bool `json:"is_redacted,omitempty"`
+
+	// 已读到该消息的其他用户 ID（不含发送者）
+	SeenBy []int32 `json:"seen_by,omitempty"`
+
+	// 工单创建者是否已读到该消息（匿名工单中创建者不出现在 seen_by 里）
+	SeenByCreator bool `json:"seen_by_creator,omitempty"`
 }
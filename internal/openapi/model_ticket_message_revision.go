@@ -0,0 +1,32 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type TicketMessageRevision struct {
+
+	Id int32 `json:"id"`
+
+	// 版本号，从 1 开始；记录的是被编辑覆盖前的内容
+	Version int32 `json:"version"`
+
+	Body string `json:"body"`
+
+	IsInternalNote bool `json:"is_internal_note"`
+
+	// 执行该次编辑的用户
+	EditedBy int32 `json:"edited_by"`
+
+	// 该次编辑发生的时间
+	CreatedAt time.Time `json:"created_at"`
+}
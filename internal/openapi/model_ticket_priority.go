@@ -0,0 +1,20 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type TicketPriority string
+
+// List of TicketPriority
+const (
+	P1 TicketPriority = "P1"
+	P2 TicketPriority = "P2"
+	P3 TicketPriority = "P3"
+	P4 TicketPriority = "P4"
+)
@@ -0,0 +1,27 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type TicketSearchHit struct {
+
+	Ticket Ticket `json:"ticket"`
+
+	// 标题（HTML 转义后，命中处以 <mark> 包裹）
+	TitleHighlight string `json:"title_highlight"`
+
+	// 正文或消息中的命中片段（同上）
+	Snippet string `json:"snippet"`
+
+	// 命中位置：title / content / message
+	MatchedIn string `json:"matched_in,omitempty"`
+
+	// 命中消息 ID（matched_in=message 时）
+	MessageId *int32 `json:"message_id,omitempty"`
+}
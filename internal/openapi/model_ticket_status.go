@@ -21,4 +21,6 @@ const (
 	SPAM_PENDING TicketStatus = "SPAM_PENDING"
 	SPAM_CONFIRMED TicketStatus = "SPAM_CONFIRMED"
 	SPAM_REJECTED TicketStatus = "SPAM_REJECTED"
+	CANCELLED TicketStatus = "CANCELLED"
+	MERGED TicketStatus = "MERGED"
 )
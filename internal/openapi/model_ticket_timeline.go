@@ -0,0 +1,18 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type TicketTimeline struct {
+
+	TicketId int32 `json:"ticket_id"`
+
+	// 按时间升序
+	Items []TicketTimelineEvent `json:"items"`
+}
@@ -0,0 +1,62 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type TicketTimelineEvent struct {
+
+	// created / message / attachment / status_change / assignment / priority_change / spam_flag / spam_review / rating
+	Type string `json:"type"`
+
+	At time.Time `json:"at"`
+
+	// 事件来自已合并到本工单的源工单时返回源工单 ID
+	TicketId int32 `json:"ticket_id,omitempty"`
+
+	// 匿名工单中创建者的操作不返回 actor_user_id，以 actor_handle 代替（创建者本人除外）
+	ActorUserId int32 `json:"actor_user_id,omitempty"`
+
+	ActorHandle string `json:"actor_handle,omitempty"`
+
+	// 仅管理员可见的事件（内部备注及其附件、垃圾标记与复核）
+	IsInternal bool `json:"is_internal,omitempty"`
+
+	StatusFrom TicketStatus `json:"status_from,omitempty"`
+
+	StatusTo TicketStatus `json:"status_to,omitempty"`
+
+	AssigneeFrom *int32 `json:"assignee_from,omitempty"`
+
+	AssigneeTo *int32 `json:"assignee_to,omitempty"`
+
+	PriorityFrom TicketPriority `json:"priority_from,omitempty"`
+
+	PriorityTo TicketPriority `json:"priority_to,omitempty"`
+
+	// type=message 时返回；已删除的消息只返回墓碑信息
+	Message *TicketMessage `json:"message,omitempty"`
+
+	// type=attachment 且附件属于消息时返回
+	MessageId *int32 `json:"message_id,omitempty"`
+
+	// type=attachment 时返回
+	ImageId int32 `json:"image_id,omitempty"`
+
+	// type=rating 时返回
+	Stars int32 `json:"stars,omitempty"`
+
+	Comment string `json:"comment,omitempty"`
+
+	// 撤回、垃圾标记、优先级调整等填写的原因
+	Reason string `json:"reason,omitempty"`
+}
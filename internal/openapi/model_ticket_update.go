@@ -0,0 +1,34 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type TicketUpdate struct {
+
+	Title string `json:"title"`
+
+	Content string `json:"content"`
+
+	// 分类名称或别名（不区分大小写）；提供 category_id 时可省略
+	Category string `json:"category,omitempty"`
+
+	// 分类ID（来自 GET /categories），优先于 category
+	CategoryId int32 `json:"category_id,omitempty"`
+
+	IsUrgent bool `json:"is_urgent"`
+
+	// 学生可选 P2-P4（P1 仅管理员可设置）；不传时按 is_urgent 推导：紧急为 P2，否则为 P3
+	Priority *TicketPriority `json:"priority,omitempty"`
+
+	// 编辑后的完整图片列表（替换原有关联）
+	ImageIds []int32 `json:"image_ids"`
+
+	// 分类自定义字段值（key -> value），NUMBER 传数字，DATE 传 YYYY-MM-DD
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
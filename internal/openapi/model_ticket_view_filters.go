@@ -0,0 +1,56 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type TicketViewFilters struct {
+
+	Statuses []string `json:"statuses,omitempty"`
+
+	Category string `json:"category,omitempty"`
+
+	IsUrgent *bool `json:"is_urgent,omitempty"`
+
+	Priorities []string `json:"priorities,omitempty"`
+
+	// 按查看者本人解释，共享视图对每位管理员显示各自负责的工单
+	AssignedToMe *bool `json:"assigned_to_me,omitempty"`
+
+	AssigneeId *int32 `json:"assignee_id,omitempty"`
+
+	CreatorId *int32 `json:"creator_id,omitempty"`
+
+	Unassigned *bool `json:"unassigned,omitempty"`
+
+	// 需同时带有全部标签
+	Tags []string `json:"tags,omitempty"`
+
+	// 自定义字段精确匹配（key -> value）
+	Fields map[string]string `json:"fields,omitempty"`
+
+	Keyword string `json:"keyword,omitempty"`
+
+	CreatedFrom *time.Time `json:"created_from,omitempty"`
+
+	CreatedTo *time.Time `json:"created_to,omitempty"`
+
+	UpdatedFrom *time.Time `json:"updated_from,omitempty"`
+
+	UpdatedTo *time.Time `json:"updated_to,omitempty"`
+
+	// created_at / updated_at / urgency / priority / sla_due
+	Sort string `json:"sort,omitempty"`
+
+	// asc / desc
+	Order string `json:"order,omitempty"`
+}
@@ -0,0 +1,26 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type TicketWatcher struct {
+
+	UserId int32 `json:"user_id"`
+
+	Source TicketWatcherSource `json:"source"`
+
+	// 添加者（主动关注时为本人）
+	AddedBy int32 `json:"added_by"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
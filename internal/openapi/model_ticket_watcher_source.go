@@ -0,0 +1,19 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type TicketWatcherSource string
+
+// List of TicketWatcherSource
+const (
+	MANUAL TicketWatcherSource = "MANUAL"
+	CC TicketWatcherSource = "CC"
+	INTERNAL_NOTE TicketWatcherSource = "INTERNAL_NOTE"
+)
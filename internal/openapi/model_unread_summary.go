@@ -0,0 +1,22 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+type UnreadSummary struct {
+
+	// 名下工单的未读消息总数
+	TotalUnread int32 `json:"total_unread"`
+
+	// 有未读消息的工单数
+	TicketCount int32 `json:"ticket_count"`
+
+	// 有未读消息的工单（最近有新消息的在前，最多 50 个）
+	Items []UnreadTicket `json:"items"`
+}
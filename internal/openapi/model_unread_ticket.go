@@ -0,0 +1,28 @@
+/*
+ * 学生服务平台 API
+ *
+ * No description provided (generated by Openapi Generator https://github.com/openapitools/openapi-generator)
+ *
+ * API version: 1.0.0
+ * Generated by: OpenAPI Generator (https://openapi-generator.tech)
+ */
+
+package openapi
+
+import (
+	"time"
+)
+
+type UnreadTicket struct {
+
+	TicketId int32 `json:"ticket_id"`
+
+	Title string `json:"title"`
+
+	Status TicketStatus `json:"status"`
+
+	UnreadCount int32 `json:"unread_count"`
+
+	// 最近一条未读消息的时间
+	LastMessageAt time.Time `json:"last_message_at"`
+}
@@ -0,0 +1,136 @@
+// Package pagination 提供基于游标（keyset）的分页辅助。
+//
+// 游标对客户端是不透明字符串（base64url 编码的 JSON），内部记录排序方式与
+// 上一页最后一行的排序键。下一页查询形如：
+//
+//	WHERE (c1 < v1) OR (c1 = v1 AND c2 < v2) OR ...  ORDER BY c1 DESC, c2 DESC ...
+//
+// 相比 offset 分页，新数据插入不会导致翻页时跳过或重复，且深翻页不退化。
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor 游标无法解析或与当前排序方式不匹配
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Params 列表分页参数：UseCursor 为 true 时按游标分页，忽略 Page
+type Params struct {
+	Page      int
+	PageSize  int
+	Cursor    string // 空字符串表示第一页
+	UseCursor bool
+}
+
+// Normalize 补全默认值（page 从 1 开始，page_size 默认 20）
+func (p Params) Normalize() Params {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PageSize < 1 {
+		p.PageSize = 20
+	}
+	return p
+}
+
+// Cursor 游标内容
+type Cursor struct {
+	// Sort 生成游标时的排序方式，换了排序的游标不能复用
+	Sort string `json:"s,omitempty"`
+	// Values 上一页最后一行的排序键（按排序列顺序）
+	Values []string `json:"v"`
+}
+
+// Encode 编码游标
+func Encode(c Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode 解码游标，并校验排序方式与键数量
+func Decode(s, sort string, nValues int) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.Sort != sort || len(c.Values) != nValues {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// KeysetWhere 生成 "位于游标之后" 的条件；所有列使用同一方向
+func KeysetWhere(cols []string, desc bool, vals []interface{}) (string, []interface{}) {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	var ors []string
+	var args []interface{}
+	for i := range cols {
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, cols[j]+" = ?")
+			args = append(args, vals[j])
+		}
+		ands = append(ands, cols[i]+" "+op+" ?")
+		args = append(args, vals[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+// OrderBy 生成与 KeysetWhere 对应的排序子句
+func OrderBy(cols []string, desc bool) string {
+	dir := " ASC"
+	if desc {
+		dir = " DESC"
+	}
+	parts := make([]string, 0, len(cols))
+	for _, c := range cols {
+		parts = append(parts, c+dir)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ---- 排序键编解码 ----
+
+func FormatTime(t time.Time) string { return t.UTC().Format(time.RFC3339Nano) }
+
+func ParseTime(s string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, ErrInvalidCursor
+	}
+	return t.UTC(), nil
+}
+
+func FormatUint(v uint) string { return strconv.FormatUint(uint64(v), 10) }
+
+func ParseUint(s string) (uint, error) {
+	v, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	return uint(v), nil
+}
+
+func FormatBool(v bool) string { return strconv.FormatBool(v) }
+
+func ParseBool(s string) (bool, error) {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, ErrInvalidCursor
+	}
+	return v, nil
+}
@@ -0,0 +1,27 @@
+package pagination
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// MaxPageSize 单页条数上限
+const MaxPageSize = 100
+
+// FromQuery 解析 page / page_size / cursor 查询参数：非法的 page 回落为 1，
+// page_size 缺省为 20 并截断到 [1, MaxPageSize]；出现 cursor 参数（可为空，
+// 表示第一页）即使用游标分页
+func FromQuery(q url.Values) Params {
+	p := Params{Page: 1, PageSize: 20}
+	if n, err := strconv.Atoi(strings.TrimSpace(q.Get("page"))); err == nil && n >= 1 {
+		p.Page = n
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(q.Get("page_size"))); err == nil {
+		p.PageSize = min(max(n, 1), MaxPageSize)
+	}
+	if v, ok := q["cursor"]; ok {
+		p.Cursor, p.UseCursor = v[0], true
+	}
+	return p
+}
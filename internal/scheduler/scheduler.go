@@ -0,0 +1,163 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	dbpkg "student-services-platform-backend/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// Job 周期性任务
+type Job struct {
+	// Name 任务名，同时作为分布式锁的名字，需全局唯一
+	Name string
+	// Interval 执行间隔
+	Interval time.Duration
+	// Run 任务主体；ctx 会在租期结束或调度器停止时取消
+	Run func(ctx context.Context) error
+}
+
+// Scheduler 基于数据库锁的简易定时任务调度器。
+// 多个 API / Worker 实例可以同时启动调度器：每个周期内，同名任务只会有一个实例抢到锁并执行。
+type Scheduler struct {
+	db     *gorm.DB
+	holder string
+	jobs   []Job
+
+	mu      sync.Mutex
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+}
+
+// New 创建调度器；holder 为本实例标识（主机名 + PID + 随机后缀）
+func New(db *gorm.DB) *Scheduler {
+	return &Scheduler{db: db, holder: instanceID()}
+}
+
+// Register 注册任务，必须在 Start 之前调用
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return fmt.Errorf("scheduler: 任务名和执行函数不能为空")
+	}
+	if job.Interval < time.Second {
+		return fmt.Errorf("scheduler: 任务 %s 的执行间隔过短: %s", job.Name, job.Interval)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return fmt.Errorf("scheduler: 调度器已启动，无法注册任务 %s", job.Name)
+	}
+	for _, j := range s.jobs {
+		if j.Name == job.Name {
+			return fmt.Errorf("scheduler: 任务 %s 重复注册", job.Name)
+		}
+	}
+	s.jobs = append(s.jobs, job)
+	return nil
+}
+
+// Start 为每个任务启动一个循环；启动后立即尝试执行一次
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.running = true
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+	log.Printf("scheduler: 已启动 %d 个定时任务 (holder=%s)", len(s.jobs), s.holder)
+}
+
+// Stop 停止调度并等待正在执行的任务退出
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.cancel()
+	s.running = false
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	s.runOnce(ctx, job)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runOnce(ctx, job)
+		}
+	}
+}
+
+// runOnce 抢锁并执行一次任务。
+// 租期略短于执行间隔：成功执行后不主动释放锁，保证同一周期内其他实例不会重复执行；
+// 执行失败时释放锁，让其他实例在下一次触发时重试。
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	lease := job.Interval - job.Interval/10
+
+	ok, err := dbpkg.TryAcquireSchedulerLock(s.db.WithContext(ctx), job.Name, s.holder, lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("scheduler: 任务 %s 获取锁失败: %v", job.Name, err)
+		}
+		return
+	}
+	if !ok {
+		return // 其他实例持有锁
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, lease)
+	defer cancel()
+
+	start := time.Now()
+	if err := s.safeRun(runCtx, job); err != nil {
+		log.Printf("scheduler: 任务 %s 执行失败 (%s): %v", job.Name, time.Since(start).Round(time.Millisecond), err)
+		if err := dbpkg.ReleaseSchedulerLock(s.db, job.Name, s.holder); err != nil {
+			log.Printf("scheduler: 任务 %s 释放锁失败: %v", job.Name, err)
+		}
+	}
+}
+
+// safeRun 执行任务并把 panic 转为错误，避免单个任务拖垮整个进程
+func (s *Scheduler) safeRun(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+func instanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(b))
+}
@@ -0,0 +1,155 @@
+// Package search 提供与数据库无关的全文检索辅助：分词与高亮。
+//
+// 中文没有空格分词，这里采用 bigram（相邻两字）切分：
+// "宿舍暖气坏了" -> "宿舍 舍暖 暖气 气坏 坏了"；孤立的单个汉字保留为单字词。
+// 拉丁字母与数字按连续片段切分并转小写。
+// 文档与查询使用同一套切分，因此数据库侧只需做按空格分词的精确匹配即可。
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// isCJK 判断是否为需要按 bigram 切分的字符
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isCJK(r)
+}
+
+// Tokenize 将文本切分为检索词（可能重复）
+func Tokenize(text string) []string {
+	var tokens []string
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isCJK(r):
+			j := i
+			for j < len(runes) && isCJK(runes[j]) {
+				j++
+			}
+			if j-i == 1 {
+				tokens = append(tokens, string(runes[i]))
+			} else {
+				for k := i; k+1 < j; k++ {
+					tokens = append(tokens, string(runes[k:k+2]))
+				}
+			}
+			i = j
+		case isWordRune(r):
+			j := i
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, strings.ToLower(string(runes[i:j])))
+			i = j
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+// IndexText 生成写入索引的文本：空格分隔的检索词
+func IndexText(text string) string {
+	return strings.Join(Tokenize(text), " ")
+}
+
+// QueryTerms 将用户输入切分为去重后的检索词；所有词都命中才算匹配
+func QueryTerms(q string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, t := range Tokenize(q) {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Highlight 对 text 做 HTML 转义，并用 <mark></mark> 包裹命中 terms 的片段。
+// maxRunes > 0 时截取以首个命中为中心、长度约 maxRunes 的片段（两端以 … 表示截断）。
+// 第二个返回值表示是否有命中。
+func Highlight(text string, terms []string, maxRunes int) (string, bool) {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// 极少数字符大小写转换后长度变化，退化为原文匹配
+		lower = runes
+	}
+
+	mask := make([]bool, len(runes))
+	first := -1
+	for _, t := range terms {
+		tr := []rune(t)
+		if len(tr) == 0 {
+			continue
+		}
+		for i := 0; i+len(tr) <= len(lower); i++ {
+			if runesEqual(lower[i:i+len(tr)], tr) {
+				for k := i; k < i+len(tr); k++ {
+					mask[k] = true
+				}
+				if first == -1 || i < first {
+					first = i
+				}
+			}
+		}
+	}
+
+	start, end := 0, len(runes)
+	if maxRunes > 0 && len(runes) > maxRunes {
+		if first > maxRunes/3 {
+			start = first - maxRunes/3
+		}
+		end = start + maxRunes
+		if end > len(runes) {
+			end = len(runes)
+			start = end - maxRunes
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	open := false
+	for i := start; i < end; i++ {
+		if mask[i] && !open {
+			b.WriteString("<mark>")
+			open = true
+		} else if !mask[i] && open {
+			b.WriteString("</mark>")
+			open = false
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+	}
+	if open {
+		b.WriteString("</mark>")
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String(), first >= 0
+}
+
+func runesEqual(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
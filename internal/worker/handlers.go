@@ -60,8 +60,11 @@ func (h *EmailHandler) needsTemplate(emailType EmailType) bool {
 		EmailTypeTicketUnclaimed:   true,
 		EmailTypeTicketResolved:    true,
 		EmailTypeTicketClosed:      true,
+		EmailTypeTicketWithdrawn:   true,
+		EmailTypeTicketMerged:      true,
 		EmailTypeTicketRated:       true,
 		EmailTypeMessageReceived:   true,
+		EmailTypeMentioned:         true,
 		EmailTypeSpamFlagged:       true,
 		EmailTypeSpamReviewed:      true,
 		EmailTypeUserCreated:       true,
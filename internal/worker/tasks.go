@@ -1,6 +1,7 @@
 package worker
 
 import (
+	"context"
 	"time"
 )
 
@@ -46,8 +47,11 @@ const (
 	EmailTypeTicketUnclaimed   EmailType = "ticket_unclaimed"   // 工单被撤销通知
 	EmailTypeTicketResolved    EmailType = "ticket_resolved"    // 工单已处理通知
 	EmailTypeTicketClosed      EmailType = "ticket_closed"      // 工单已关闭通知
+	EmailTypeTicketWithdrawn   EmailType = "ticket_withdrawn"   // 工单被学生撤回通知
+	EmailTypeTicketMerged      EmailType = "ticket_merged"      // 工单被合并通知
 	EmailTypeTicketRated       EmailType = "ticket_rated"       // 工单被评价通知
 	EmailTypeMessageReceived   EmailType = "message_received"   // 收到新消息通知
+	EmailTypeMentioned         EmailType = "mentioned"          // 内部备注中被 @ 提及通知
 	EmailTypeSpamFlagged       EmailType = "spam_flagged"       // 垃圾标记通知
 	EmailTypeSpamReviewed      EmailType = "spam_reviewed"      // 垃圾审核结果通知
 	EmailTypeUserCreated       EmailType = "user_created"       // 用户创建通知
@@ -79,6 +83,33 @@ func (p EmailPriority) GetQueueName() string {
 	}
 }
 
+// XPriority 邮件头 X-Priority 的取值（1 最高，5 最低），便于收件端按优先级排序
+func (p EmailPriority) XPriority() string {
+	switch p {
+	case EmailPriorityCritical, EmailPriorityHigh:
+		return "1"
+	case EmailPriorityLow:
+		return "5"
+	default:
+		return "3"
+	}
+}
+
+type priorityCtxKey struct{}
+
+// WithPriority 在 ctx 中携带邮件优先级，调用方无需修改通知方法签名即可指定优先级
+func WithPriority(ctx context.Context, p EmailPriority) context.Context {
+	return context.WithValue(ctx, priorityCtxKey{}, p)
+}
+
+// PriorityFromContext 取出 ctx 中携带的邮件优先级，未指定时为普通优先级
+func PriorityFromContext(ctx context.Context) EmailPriority {
+	if p, ok := ctx.Value(priorityCtxKey{}).(EmailPriority); ok && p != "" {
+		return p
+	}
+	return EmailPriorityNormal
+}
+
 // NewEmailTask 创建新的邮件任务
 func NewEmailTask(emailType EmailType, to []string, subject, body string) *EmailTask {
 	return &EmailTask{